@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hfern/min/parser"
+)
+
+var cmdAst = &command{
+	name:  "ast",
+	usage: "ast [-format=json|sexp] file.min",
+	short: "print the syntax tree of a source file",
+	run:   runAst,
+}
+
+func runAst(cmd *command, args []string) int {
+	flags := commandFlags(cmd)
+	format := flags.String("format", "json", "output format: json or sexp")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+	}
+
+	src, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "min ast: %s\n", err)
+		return 1
+	}
+	if len(src) == 0 {
+		fmt.Fprintf(os.Stderr, "min ast: %s: %s\n", flags.Arg(0), errEmpty)
+		return 1
+	}
+	tree := &parser.VMTree{Buffer: string(src)}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		fmt.Fprintf(os.Stderr, "min ast: %s: %s\n", flags.Arg(0), err)
+		return 1
+	}
+	exported := parser.Export(tree.ParseTree(), string(src))
+
+	var out []byte
+	switch *format {
+	case "json":
+		if out, err = exported.JSON(); err != nil {
+			fmt.Fprintf(os.Stderr, "min ast: %s\n", err)
+			return 1
+		}
+	case "sexp":
+		out = exported.Sexp()
+	default:
+		fmt.Fprintf(os.Stderr, "min ast: unknown format %q\n", *format)
+		return 2
+	}
+	os.Stdout.Write(out)
+	return 0
+}
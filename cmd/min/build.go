@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hfern/min/compiler"
+	"github.com/hfern/min/parser"
+)
+
+var cmdBuild = &command{
+	name:  "build",
+	usage: "build [-c] [-o output] [-I dir]... file.min...",
+	short: "compile a program, or modules into objects",
+	run:   runBuild,
+}
+
+// dirs is a flag that may be given several times.
+type dirs []string
+
+func (d *dirs) String() string       { return strings.Join(*d, string(os.PathListSeparator)) }
+func (d *dirs) Set(dir string) error { *d = append(*d, dir); return nil }
+
+// runBuild compiles the program whose main module is in the named
+// file into an image. With -c, it compiles each named module on
+// its own into an object instead, next to its source, so that
+// only the modules that changed need to be compiled again before
+// they are linked:
+//
+//	min build -c app/main.min lib/math.min
+//	min link -o app.img app/main.o lib/math.o
+func runBuild(cmd *command, args []string) int {
+	flags := commandFlags(cmd)
+	objects := flags.Bool("c", false, "compile each module into an object")
+	output := flags.String("o", "", "write the image, or the only object, to output")
+	var search dirs
+	flags.Var(&search, "I", "look for imports in dir too (may be repeated)")
+	flags.Parse(args)
+	if flags.NArg() == 0 || (flags.NArg() > 1 && (!*objects || *output != "")) {
+		flags.Usage()
+	}
+
+	if !*objects {
+		file := flags.Arg(0)
+		cmp, err := newCompiler(file, search)
+		if err == nil {
+			err = cmp.Compile()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "min build: %s: %s\n", file, err)
+			return 1
+		}
+		if *output == "" {
+			*output = replaceExt(file, ".img")
+		}
+		if err := ioutil.WriteFile(*output, cmp.Bytecode(), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "min build: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	status := 0
+	for _, file := range flags.Args() {
+		out := *output
+		if out == "" {
+			out = replaceExt(file, ".o")
+		}
+		if err := buildObject(file, out, search); err != nil {
+			fmt.Fprintf(os.Stderr, "min build: %s: %s\n", file, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// buildObject compiles the module in file into an object at out.
+func buildObject(file, out string, search []string) error {
+	cmp, err := newCompiler(file, search)
+	if err != nil {
+		return err
+	}
+	object, err := cmp.CompileObject()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := compiler.WriteObject(f, object); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// errEmpty is the error for a source file with nothing in it,
+// which the parser cannot be given.
+var errEmpty = errors.New("empty program")
+
+// newCompiler parses the source in file for a compiler that
+// looks for its imports in search too.
+func newCompiler(file string, search []string) (*compiler.Compiler, error) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) == 0 {
+		return nil, errEmpty
+	}
+	tree := &parser.VMTree{Buffer: string(src)}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		return nil, err
+	}
+	tree.ParseTree()
+	cmp := compiler.NewCompiler()
+	cmp.SetTree(tree)
+	cmp.SetSource(string(src))
+	cmp.SetPath(file)
+	cmp.SetSearchPath(search...)
+	return cmp, nil
+}
+
+// replaceExt replaces the extension of file with ext.
+func replaceExt(file, ext string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ext
+}
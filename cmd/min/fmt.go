@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hfern/min/format"
+)
+
+var cmdFmt = &command{
+	name:  "fmt",
+	usage: "fmt [-l] [-w] [path ...]",
+	short: "reformat min source files",
+	run:   runFmt,
+}
+
+var (
+	fmtList  *bool
+	fmtWrite *bool
+)
+
+// runFmt formats the named files, or the .min files found in
+// the named directories, or standard input. Like gofmt, -l
+// lists the files that are not formatted and -w rewrites them,
+// so CI can check formatting with
+//
+//	test -z "$(min fmt -l .)"
+func runFmt(cmd *command, args []string) int {
+	flags := commandFlags(cmd)
+	fmtList = flags.Bool("l", false, "list files whose formatting differs from min fmt's")
+	fmtWrite = flags.Bool("w", false, "write result to (source) file instead of stdout")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		if *fmtWrite {
+			fmt.Fprintln(os.Stderr, "min fmt: cannot use -w with standard input")
+			return 2
+		}
+		if err := fmtFile("<standard input>", os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "min fmt: %s\n", err)
+			return 2
+		}
+		return 0
+	}
+
+	status := 0
+	for _, root := range flags.Args() {
+		// files named on the command line are formatted
+		// whatever their extension
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || (path != root && !strings.HasSuffix(path, ".min")) {
+				return nil
+			}
+			if err := fmtFile(path, nil, os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "min fmt: %s: %s\n", path, err)
+				status = 2
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "min fmt: %s\n", err)
+			status = 2
+		}
+	}
+	return status
+}
+
+// fmtFile formats the file at path, reading it from in unless
+// in is nil.
+func fmtFile(path string, in io.Reader, out io.Writer) error {
+	if in == nil {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	src, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	res, err := format.Source(src)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(src, res) {
+		if !*fmtList && !*fmtWrite {
+			_, err = out.Write(res)
+		}
+		return err
+	}
+	if *fmtList {
+		fmt.Fprintln(out, path)
+	}
+	if *fmtWrite {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, res, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	if !*fmtList && !*fmtWrite {
+		_, err = out.Write(res)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hfern/min/compiler"
+)
+
+var cmdLink = &command{
+	name:  "link",
+	usage: "link [-o output] main.o file.o...",
+	short: "link objects into a program image",
+	run:   runLink,
+}
+
+// runLink links the named objects into an image. The first is the
+// main module's, whose main routine the image runs.
+func runLink(cmd *command, args []string) int {
+	flags := commandFlags(cmd)
+	output := flags.String("o", "", "write the image to output")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+	}
+
+	objects := make([]*compiler.Object, flags.NArg())
+	for i, file := range flags.Args() {
+		object, err := readObject(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "min link: %s: %s\n", file, err)
+			return 1
+		}
+		objects[i] = object
+	}
+	image, err := compiler.Link(objects)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "min link: %s\n", err)
+		return 1
+	}
+	if *output == "" {
+		*output = replaceExt(flags.Arg(0), ".img")
+	}
+	if err := ioutil.WriteFile(*output, image, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "min link: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func readObject(file string) (*compiler.Object, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return compiler.ReadObject(f)
+}
@@ -0,0 +1,76 @@
+// Command min is the toolchain for the min language.
+//
+// Usage:
+//
+//	min [flags] command [arguments]
+//
+// The commands are:
+//
+//	ast    print the syntax tree of a source file
+//	build  compile a program, or modules into objects
+//	fmt    reformat min source files
+//	link   link objects into a program image
+//	run    compile and run a program, or run an image
+//
+// The flags before the command are the parser and compiler
+// logging flags (-prs-*, -cmp-*).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	short string
+	run   func(cmd *command, args []string) int
+}
+
+var commands = []*command{
+	cmdAst,
+	cmdBuild,
+	cmdFmt,
+	cmdLink,
+	cmdRun,
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: min [flags] command [arguments]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-6s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintf(os.Stderr, "\nflags:\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+	}
+
+	name, args := flag.Arg(0), flag.Args()[1:]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			os.Exit(cmd.run(cmd, args))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "min: unknown command %q\n", name)
+	usage()
+}
+
+// commandFlags returns the flag set for cmd.
+func commandFlags(cmd *command) *flag.FlagSet {
+	flags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: min %s\n", cmd.usage)
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+	return flags
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hfern/min/vm"
+)
+
+var cmdRun = &command{
+	name:  "run",
+	usage: "run [-I dir]... [limits] file.min|file.img",
+	short: "compile and run a program, or run an image",
+	run:   runRun,
+}
+
+// runRun runs the program in the named source file or image. The
+// value that main returns, or that the program exits with, is
+// the exit status of min, so that scripts can be tested like any
+// other command:
+//
+//	min run check.min || echo failed
+//
+// min run exits with status 2 if the program cannot be compiled
+// or traps, which it does when it goes over one of the limits
+// given as flags. A program may return 2 too: only failures print
+// a message on standard error. The operating system keeps the low
+// 8 bits of the status.
+func runRun(cmd *command, args []string) int {
+	flags := commandFlags(cmd)
+	var search dirs
+	flags.Var(&search, "I", "look for imports in dir too (may be repeated)")
+	instructions := flags.Int64("max-instructions", 0, "stop the program after `n` instructions")
+	stack := flags.Int("max-stack", 0, "stop the program once it has more than `n` words on its stack")
+	memory := flags.Int64("max-memory", 0, "stop the program once it uses more than `n` bytes of memory")
+	timeout := flags.Duration("timeout", 0, "stop the program after `d`")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+	}
+
+	file := flags.Arg(0)
+	var bytecode []byte
+	if filepath.Ext(file) == ".img" {
+		image, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "min run: %s\n", err)
+			return 2
+		}
+		bytecode = image
+	} else {
+		cmp, err := newCompiler(file, search)
+		if err == nil {
+			err = cmp.Compile()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "min run: %s: %s\n", file, err)
+			return 2
+		}
+		bytecode = cmp.Bytecode()
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	machine := vm.NewMachine(bytecode)
+	machine.MaxInstructions = *instructions
+	machine.MaxStack = *stack
+	machine.MaxMemory = *memory
+	if err := machine.Trap(machine.RunContext(ctx)); err != nil {
+		fmt.Fprintf(os.Stderr, "min run: %s: %s\n", file, err)
+		return 2
+	}
+	return int(machine.Registers[vm.REGA])
+}
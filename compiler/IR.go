@@ -17,6 +17,7 @@ type IRContext struct {
 	PassNumber int
 	Program    *Program
 	IRArray    *IRArray
+	Labels     map[string]int // the offsets of the labels, by symbol
 }
 
 type IRLiteral struct {
@@ -34,16 +35,67 @@ type IRLabel struct {
 //    JE B, B, B
 type IRJump struct {
 	symbol    string
-	_position int // jumplocation
+	_position int  // jumplocation
+	_local    bool // the label is in the code being assembled
 }
 
+// Calls target, leaving its result in returnreg and any further
+// results in results. The registers in saved are pushed before
+// the call and popped after it.
+// Struct arguments are passed as their addresses; a routine that
+// returns a struct takes the address to return it at as args[0].
+//
+//	STPR %saved...
+//	STRPS $returnreg
+//	ADD $returnreg (offset of the return point)
+//	STPR $returnreg            // return address
+//	STPR/STPS %args...         // last argument first
+//	SETL $returnreg %jumploc{4 bytes}
+//	JE $returnreg $returnreg $returnreg
+//	STPP $returnreg            // result
+//	STPP %results...
+//	STPP %saved...             // in reverse
+//
+// A call through via, which holds the address of the routine's
+// ENTRY, pushes the number of arguments and jumps with JR instead
+// of SETL and JE.
 type IRFuncCall struct {
-	returnreg vm.Register
-	args      []vm.Register
+	returnreg *Register
+	results   []*Register
+	args      []Value
+	saved     []*Register
 	target    *Routine
+	via       *Register
 	caller    *Routine
 	calltok   *parser.Node
 	__jumpto  int
+	__local   bool // target is in the code being assembled
+}
+
+// Loads the address of a global, which the linker places in the
+// data segment.
+//
+//	LOAD/STORE %reg address{4 bytes}
+type IRGlobal struct {
+	op      vm.Operation
+	reg     *Register
+	address int // in the module's data
+}
+
+// Loads the handle of a string constant, which the linker
+// renumbers.
+//
+//	SETL %reg handle{4 bytes}
+type IRString struct {
+	reg    *Register
+	handle int // in the module's strings
+}
+
+// relocatable is a segment whose code holds addresses that are
+// only known once it is linked. relocate records them in o for
+// the segment placed at offset at of o's code.
+type relocatable interface {
+	relocate(ctx IRContext, o *Object, at int) error
 }
 
 func NewIRArray() IRArray { return make([]IRSegment, 0) }
@@ -77,10 +129,10 @@ func (j *IRJump) Size() int {
 	return 4
 }
 func (j *IRJump) Pass(ctx IRContext) bool {
-	if ctx.PassNumber == 1 {
-		// TODO: Make this work.
-		// function should set _position to byte value of
-		// respective symbol
+	if ctx.PassNumber == 2 {
+		j._position, j._local = labelAddress(ctx, j.symbol)
+	}
+	if ctx.PassNumber >= 2 {
 		return true
 	}
 	return false
@@ -92,33 +144,44 @@ func (j *IRJump) Emit() []byte {
 }
 
 func (l *IRFuncCall) Size() int {
-	// Push
-	variables_to_pack := l.target.vmap.VariablesAlive(l.calltok.Tok.End())
-	pack := (1 + 1) * len(variables_to_pack) // eachreg: STPR %reg
+	pack := (1 + 1) * len(l.saved) // eachreg: STPR %reg
 
-	push_return_location := 1 + 1     // VM_OPSTRPS $returnreg
-	push_return_location += 1 + 1 + 1 // ADD $returnreg 14 // jump forward additional 14 bytes
-	push_return_location += 1 + 1     // STPR $returnreg
-	jump := 1 + 1 + 4                 // SETL $returnreg %jumploc{4 bytes}
-	jump += 1 + 1 + 1                 // JE $returnreg $returnreg $returnreg
+	push_return_location := 1 + 1 // VM_OPSTRPS $returnreg
+	push_return_location += l.returnOffset()
+	unpack := 1 + 1                    // STPP $returnreg
+	unpack += (1 + 1) * len(l.results) // eachresult: STPP %reg
+	unpack += (1 + 1) * len(l.saved)   // eachreg: STPP %reg
+	return pack + push_return_location + unpack
+}
+
+// returnOffset is the distance from the instruction after STRPS
+// to the return point.
+func (l *IRFuncCall) returnOffset() int {
+	offset := 1 + 1 + 1             // ADD $returnreg offset
+	offset += 1 + 1                 // STPR $returnreg
+	offset += (1 + 1) * len(l.args) // eacharg: STPR %reg or STPS imm8
+	if l.via != nil {
+		offset += 1 + 1 // STPS (number of arguments)
+		offset += 1 + 1 // JR %via
+		return offset
+	}
+	offset += 1 + 1 + 4     // SETL $returnreg %jumploc{4 bytes}
+	offset += 1 + 1 + 1 + 1 // JE $returnreg $returnreg $returnreg
+	return offset
+}
 
-	unpack := (1 + 1) * len(variables_to_pack) // eachreg: STPP %reg
-	return pack + push_return_location + jump + unpack
+// jumpField is the offset of %jumploc in the call's code.
+func (l *IRFuncCall) jumpField() int {
+	field := (1 + 1) * len(l.saved) // eachreg: STPR %reg
+	field += 1 + 1                  // STRPS $returnreg
+	field += l.returnOffset()
+	field -= 4 + 1 + 1 + 1 + 1 // %jumploc and JE
+	return field
 }
+
 func (l *IRFuncCall) Pass(ctx IRContext) bool {
-	if ctx.PassNumber == 2 {
-		l.__jumpto = 0
-		for _, ir := range ([]IRSegment)(*ctx.IRArray) {
-			switch raw := (ir).(type) {
-			case *IRLabel:
-				if raw.symbol == l.target.Symbol() {
-					return true
-				}
-			default:
-				l.__jumpto += ir.Size()
-				break
-			}
-		}
+	if ctx.PassNumber == 2 && l.via == nil {
+		l.__jumpto, l.__local = labelAddress(ctx, l.target.Symbol())
 	}
 	if ctx.PassNumber >= 2 {
 		return true
@@ -128,22 +191,127 @@ func (l *IRFuncCall) Pass(ctx IRContext) bool {
 func (l *IRFuncCall) Emit() []byte {
 	codesegment := make([]byte, 0, l.Size())
 
-	variables_to_pack := l.target.vmap.VariablesAlive(l.calltok.Tok.End())
-	for _, variable := range variables_to_pack {
-		byteadd(&codesegment, vm.STPR, byte(variable.register.id))
+	for _, reg := range l.saved {
+		byteadd(&codesegment, vm.STPR, reg)
 	}
 
 	retreg := l.returnreg
 
 	byteadd(&codesegment, vm.STRPS, retreg)
-	byteadd(&codesegment, vm.ADD, retreg, byte(14))
+	byteadd(&codesegment, vm.ADD, retreg, byte(l.returnOffset()))
 	byteadd(&codesegment, vm.STPR, retreg)
-	byteadd(&codesegment, vm.SETL, retreg)
-	setL(&codesegment, l.__jumpto)
-	byteadd(&codesegment, vm.JE, retreg, retreg, retreg)
+	for i := len(l.args) - 1; i >= 0; i-- {
+		if arg := l.args[i]; arg.Type == ValueConstant {
+			byteadd(&codesegment, vm.STPS, byte(arg.Constant))
+		} else {
+			byteadd(&codesegment, vm.STPR, arg.Register)
+		}
+	}
+	if l.via != nil {
+		byteadd(&codesegment, vm.STPS, byte(len(l.args)))
+		byteadd(&codesegment, vm.JR, l.via)
+	} else {
+		byteadd(&codesegment, vm.SETL, retreg)
+		setL(&codesegment, l.__jumpto)
+		byteadd(&codesegment, vm.JE, retreg, retreg, retreg)
+	}
 
-	for i := len(variables_to_pack) - 1; i <= 0; i-- {
-		byteadd(&codesegment, vm.STPP, variables_to_pack[i].register.id)
+	byteadd(&codesegment, vm.STPP, retreg)
+	for _, reg := range l.results {
+		byteadd(&codesegment, vm.STPP, reg)
+	}
+	for i := len(l.saved) - 1; i >= 0; i-- {
+		byteadd(&codesegment, vm.STPP, l.saved[i])
 	}
 	return codesegment
 }
+
+func (j *IRJump) relocate(ctx IRContext, o *Object, at int) error {
+	if j._local {
+		o.Fixups = append(o.Fixups, Fixup{Offset: at, Kind: FixupLabel, Value: j._position})
+		return nil
+	}
+	// the entry of a routine of another module
+	target, ok := ctx.Program.routine(j.symbol)
+	if !ok {
+		return errorUnknownLabel(j.symbol)
+	}
+	o.Relocations = append(o.Relocations, Relocation{Offset: at, Symbol: target.exported()})
+	return nil
+}
+
+func (l *IRFuncCall) relocate(_ IRContext, o *Object, at int) error {
+	if l.via != nil {
+		return nil
+	}
+	at += l.jumpField()
+	if l.__local {
+		o.Fixups = append(o.Fixups, Fixup{Offset: at, Kind: FixupLabel, Value: l.__jumpto})
+		return nil
+	}
+	o.Relocations = append(o.Relocations, Relocation{
+		Offset:    at,
+		Symbol:    l.target.exported(),
+		Addend:    entrySize,
+		Signature: l.target.signature(),
+	})
+	return nil
+}
+
+func (g *IRGlobal) Size() int {
+	return 1 + 1 + 4
+}
+func (g *IRGlobal) Pass(_ IRContext) bool {
+	return true
+}
+func (g *IRGlobal) Emit() []byte {
+	code := make([]byte, 0, g.Size())
+	byteadd(&code, g.op, g.reg)
+	setL(&code, g.address)
+	return code
+}
+func (g *IRGlobal) relocate(_ IRContext, o *Object, at int) error {
+	o.Fixups = append(o.Fixups, Fixup{Offset: at + 1 + 1, Kind: FixupData, Value: g.address})
+	return nil
+}
+
+func (s *IRString) Size() int {
+	return 1 + 1 + 4
+}
+func (s *IRString) Pass(_ IRContext) bool {
+	return true
+}
+func (s *IRString) Emit() []byte {
+	code := make([]byte, 0, s.Size())
+	byteadd(&code, vm.SETL, s.reg)
+	setL(&code, s.handle)
+	return code
+}
+func (s *IRString) relocate(_ IRContext, o *Object, at int) error {
+	o.Fixups = append(o.Fixups, Fixup{Offset: at + 1 + 1, Kind: FixupString, Value: s.handle})
+	return nil
+}
+
+// labelAddress returns the byte offset of the label for symbol
+// in the code being assembled.
+func labelAddress(ctx IRContext, symbol string) (int, bool) {
+	address, ok := ctx.Labels[symbol]
+	return address, ok
+}
+
+// labelOffsets returns the byte offsets of the labels of ir, by
+// symbol. The sizes of segments do not change from pass to pass.
+func labelOffsets(ir IRArray) map[string]int {
+	labels := make(map[string]int)
+	address := 0
+	for _, segment := range ir {
+		if label, ok := segment.(*IRLabel); ok {
+			if _, seen := labels[label.symbol]; !seen {
+				labels[label.symbol] = address
+			}
+			continue
+		}
+		address += segment.Size()
+	}
+	return labels
+}
@@ -0,0 +1,278 @@
+package compiler
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hfern/min/parser"
+)
+
+// Module is one source file of a program. A program is made of
+// its main module and the modules that it imports, directly or
+// not:
+//
+//	import "lib/math.min";
+//	import "../util.min" as u;
+//
+// Every module has routines, externs, constants, globals and
+// structs of its own, so their names only need to differ within
+// a module.
+// The routines of an imported module are called with the name of
+// the file, or the one it is imported as: math.sq(x).
+//
+// The path of an import is relative to the directory of the
+// importing file, then to each directory of the search path; see
+// Compiler.SetSearchPath. Compiler.SetFS makes them paths in a
+// file system other than that of the OS. A module is loaded once
+// however many modules import it, and no module may import
+// itself, directly or not.
+type Module struct {
+	name            string // what the module is imported as
+	file            string // the path it was read from
+	path            string // absolute, to tell modules apart
+	sourcecode      string
+	tree            *parser.VMTree
+	imports         map[string]moduleImport
+	symbols         SymbolMap
+	routinesByNames map[string]*Routine
+	structs         map[string]*Struct
+	externs         map[string]*Extern
+	globals         int // size of the module's data
+	strings         []string
+	stringHandles   map[string]int
+	prefix          string // of the symbols of its object; see Object
+	__program       *Program
+	__index         int // in Program.modules
+}
+
+// moduleImport is a module as imported at node.
+type moduleImport struct {
+	module *Module
+	node   *parser.Node
+}
+
+var moduleName = regexp.MustCompile(`^[a-zA-Z]+[a-zA-Z0-9]*$`)
+
+func NewModule(file, source string, tree *parser.VMTree) *Module {
+	m := Module{file: file, sourcecode: source, tree: tree}
+	if file != "" {
+		m.path, _ = filepath.Abs(file)
+	}
+	m.name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	m.imports = make(map[string]moduleImport)
+	m.symbols = NewSymbolMap()
+	m.routinesByNames = make(map[string]*Routine)
+	m.structs = make(map[string]*Struct)
+	m.externs = make(map[string]*Extern)
+	m.stringHandles = make(map[string]int)
+	return &m
+}
+
+// addModule adds m to the program. The symbols of modules with
+// the same name, from different directories, are told apart by
+// their index.
+func (p *Program) addModule(m *Module) {
+	m.__program = p
+	m.__index = len(p.modules)
+	m.prefix = m.name
+	if m.file == "" {
+		m.prefix = "main"
+	}
+	for _, other := range p.modules {
+		if other.prefix == m.prefix {
+			m.prefix += "#" + strconv.Itoa(m.__index)
+			break
+		}
+	}
+	p.modules = append(p.modules, m)
+}
+
+// module returns the module loaded from path, or nil.
+func (p *Program) module(path string) *Module {
+	for _, m := range p.modules {
+		if m.path == path {
+			return m
+		}
+	}
+	return nil
+}
+
+func (m *Module) RoutineExists(name string) bool {
+	if _, ok := m.routinesByNames[name]; ok {
+		return true
+	}
+	return false
+}
+
+func (m *Module) linkRoutine(rout *Routine) error {
+	name := rout.GetName()
+	_, builtin := builtins[name]
+	if _, conversion := conversions[name]; builtin || conversion {
+		return errorBuiltinRedefined(m, rout)
+	}
+	if m.RoutineExists(name) {
+		return err_routine_already_exists(m, m.routinesByNames[name], rout)
+	}
+	m.routinesByNames[name] = rout
+	return nil
+}
+
+// qualify makes the name of a routine of the module unique in the
+// program, for the labels of its code.
+func (m *Module) qualify(name string) string {
+	if m.__index == 0 {
+		return name
+	}
+	return strconv.Itoa(m.__index) + ":" + name
+}
+
+// at places an error found in the module in its file. Errors in
+// the main module are left as they are.
+func (m *Module) at(err error) error {
+	if err == nil || m.__index == 0 {
+		return err
+	}
+	return newError(m.file, ": ", err)
+}
+
+// lookup_routine returns the routine that a call names: one of the
+// routine's module, or one of an imported module for module.name.
+func (r *Routine) lookup_routine(name string) (*Routine, bool) {
+	m := r.__module
+	if dot := strings.IndexByte(name, '.'); dot >= 0 {
+		imported, ok := m.imports[name[:dot]]
+		if !ok {
+			return nil, false
+		}
+		m, name = imported.module, name[dot+1:]
+	}
+	target, ok := m.routinesByNames[name]
+	return target, ok
+}
+
+// load_imports loads the modules that m imports and those that
+// they import in turn. importing are the modules whose imports
+// are being loaded, m last, to find cycles.
+func (c *Compiler) load_imports(m *Module, importing []*Module) error {
+	importing = append(importing, m)
+	for _, node := range m.tree.ASTTree.Children {
+		if node.Tok.Rule != parser.Ruleimport {
+			continue
+		}
+		if c.noimports {
+			return errorImportsDisabled(m, node)
+		}
+		file := unquote(node.Child(parser.Rulestring).Source())
+		found, path, err := c.resolve(m, file)
+		if err != nil {
+			return errorImportNotFound(m, node, file)
+		}
+
+		for i, previous := range importing {
+			if previous.path == path {
+				return errorImportCycle(append(importing[i:], previous))
+			}
+		}
+		imported := c.program.module(path)
+		if imported == nil {
+			if imported, err = c.load_module(m, node, found); err != nil {
+				return err
+			}
+			if err := c.load_imports(imported, importing); err != nil {
+				return err
+			}
+		}
+
+		name := imported.name
+		if alias := node.Child(parser.Rulemodule); alias != nil {
+			name = alias.Source()
+		}
+		if !moduleName.MatchString(name) {
+			return errorModuleName(m, node, name)
+		}
+		if previous, ok := m.imports[name]; ok {
+			return errorModuleAlreadyImported(m, node, name, previous.node)
+		}
+		m.imports[name] = moduleImport{module: imported, node: node}
+	}
+	return nil
+}
+
+// resolve finds the file that m imports as file and returns it
+// with its absolute path.
+func (c *Compiler) resolve(m *Module, file string) (string, string, error) {
+	if c.files != nil {
+		return c.resolve_fs(m, file)
+	}
+	candidates := []string{file}
+	if !filepath.IsAbs(file) {
+		candidates = []string{filepath.Join(filepath.Dir(m.file), file)}
+		for _, dir := range c.searchpath {
+			candidates = append(candidates, filepath.Join(dir, file))
+		}
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			path, err := filepath.Abs(candidate)
+			return candidate, path, err
+		}
+	}
+	return "", "", os.ErrNotExist
+}
+
+// resolve_fs is resolve for imports read from c.files, where
+// the path of a module is its file.
+func (c *Compiler) resolve_fs(m *Module, file string) (string, string, error) {
+	if path.IsAbs(file) {
+		return "", "", os.ErrNotExist
+	}
+	candidates := []string{path.Join(path.Dir(m.file), file)}
+	for _, dir := range c.searchpath {
+		candidates = append(candidates, path.Join(dir, file))
+	}
+	for _, candidate := range candidates {
+		if !fs.ValidPath(candidate) {
+			continue
+		}
+		if info, err := fs.Stat(c.files, candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, candidate, nil
+		}
+	}
+	return "", "", os.ErrNotExist
+}
+
+// load_module reads and parses the module in file, which m
+// imports at node, and adds it to the program.
+func (c *Compiler) load_module(m *Module, node *parser.Node, file string) (*Module, error) {
+	var source []byte
+	var err error
+	if c.files != nil {
+		source, err = fs.ReadFile(c.files, file)
+	} else {
+		source, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, errorImportUnreadable(m, node, err)
+	}
+	if len(source) == 0 {
+		return nil, errorImportSyntax(m, node, file)
+	}
+	tree := &parser.VMTree{Buffer: string(source)}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		return nil, errorImportSyntax(m, node, file)
+	}
+	tree.ParseTree()
+	imported := NewModule(file, string(source), tree)
+	if c.files != nil {
+		imported.path = file
+	}
+	c.program.addModule(imported)
+	return imported, nil
+}
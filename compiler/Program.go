@@ -1,37 +1,63 @@
 package compiler
 
+import "github.com/hfern/min/parser"
+
 type Program struct {
-	symbols         SymbolMap
-	__compiler      *Compiler
-	routines        []*Routine
-	routinesByNames map[string]*Routine
-	sourcecode      string
+	__compiler *Compiler
+	modules    []*Module // the main module first; see Module
+	routines   []*Routine
+	entries    map[string]*Routine       // by the symbol of their entry
+	declared   map[*parser.Node]*Routine // by the node that declares them
+	bytecode   []byte
 }
 
 func NewProgram() Program {
 	p := Program{}
-	p.symbols = NewSymbolMap()
 	p.routines = make([]*Routine, 0)
-	p.routinesByNames = make(map[string]*Routine)
+	p.entries = make(map[string]*Routine)
+	p.declared = make(map[*parser.Node]*Routine)
 	return p
 }
 
-func (p *Program) RoutineExists(name string) bool {
-	if _, ok := p.routinesByNames[name]; ok {
-		return true
-	}
-	return false
-}
-
 func (p *Program) addRoutine(rout *Routine) {
 	p.routines = append(p.routines, rout)
+	p.entries[rout.Symbol("entry")] = rout
+	p.declared[rout.__node] = rout
 }
 
-func (p *Program) linkRoutine(rout *Routine) error {
-	name := rout.GetName()
-	if p.RoutineExists(name) {
-		return err_routine_already_exists(p, p.routinesByNames[name], rout)
+// assemble compiles each module into an object and links them
+// into the program's byte code, the main module first.
+func (p *Program) assemble() error {
+	main, ok := p.modules[0].routinesByNames["main"]
+	if !ok {
+		return errorNoMain()
+	}
+	if len(main.args) > 0 || len(main.returns) != 1 || main.returns[0] != KindInt || main.returned != nil {
+		return errorMainArguments(main.__module, main)
 	}
-	p.routinesByNames[name] = rout
+
+	objects, err := p.objects()
+	if err != nil {
+		return err
+	}
+	bytecode, err := Link(objects)
+	if err != nil {
+		return err
+	}
+	p.bytecode = bytecode
 	return nil
 }
+
+// objects compiles each module into an object, the main module
+// first.
+func (p *Program) objects() ([]*Object, error) {
+	objects := make([]*Object, len(p.modules))
+	for i, m := range p.modules {
+		o, err := m.object()
+		if err != nil {
+			return nil, err
+		}
+		objects[i] = o
+	}
+	return objects, nil
+}
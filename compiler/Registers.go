@@ -21,6 +21,10 @@ func (r *Register) Unlock() {
 	r.reserved = false
 }
 
+func (r *Register) Byte() byte {
+	return byte(r.id)
+}
+
 type RegisterMap struct {
 	registers [vm.NUM_REGS]*Register
 }
@@ -50,7 +54,7 @@ func (c *RegisterMap) ReserveRegister() *Register {
 func (c *RegisterMap) RegistersInUse() []*Register {
 	inuse := make([]*Register, 0, 5)
 	for _, reg := range c.registers {
-		if !reg.Locked() {
+		if reg.Locked() {
 			inuse = append(inuse, reg)
 		}
 	}
@@ -10,11 +10,33 @@ type Routine struct {
 	registers    RegisterMap
 	vmap         VariablePool
 	args         []string
+	returns      []Kind           // of each result
+	returned     *Struct          // if the routine returns a struct
+	__declared   parser.NodeArray // the type names of the results, if declared
 	__func_calls parser.NodeArray
 	__program    *Program
+	__module     *Module
 	__node       *parser.Node
 	__name       string
 	__IR         IRArray
+
+	// control flow; see control.go
+	__blocks int
+	__loops  []loop
+	__labels map[string]*parser.Node
+	__jumps  parser.NodeArray
+
+	// arrays; see arrays.go
+	__frame  *Register
+	__arrays map[*parser.Node]array
+	// structs; see structs.go
+	__records map[*parser.Node]int // offsets in the frame
+	__result  *Register            // where to return a struct
+	// the names reserved anywhere in the routine; see types.go
+	__locals map[string]bool
+	// closures; see closures.go
+	__parent   *Routine         // the routine this one is nested in
+	__captures parser.NodeArray // the variables captured from it
 }
 
 /**
@@ -26,12 +48,38 @@ func (r *Routine) lex() {
 	r.register_arguments(r.lex_arguments())
 	r.register_variable_positions(r.lex_variables())
 	r.register_funccalls(r.lex_funccalls())
+	r.register_locals(r.lex_locals())
+}
+
+// lex_locals returns the variables reserved in the routine,
+// wherever they are.
+func (r *Routine) lex_locals() parser.NodeArray {
+	reserved := r.nodes(parser.Rulereserved)
+	locals := make(parser.NodeArray, len(reserved))
+	for i, node := range reserved {
+		locals[i] = node.Child(parser.Rulevariable)
+	}
+	// so are the names of the routines nested in it
+	for _, node := range r.nodes(parser.Ruleroutine) {
+		locals = append(locals, nested_name(node))
+	}
+	return locals
+}
+
+func (r *Routine) register_locals(nodes parser.NodeArray) {
+	r.__locals = make(map[string]bool)
+	for _, argname := range r.args {
+		r.__locals[argname] = true
+	}
+	for _, node := range nodes {
+		r.__locals[node.Source()] = true
+	}
 }
 
 // Determines variable positions
 func (r *Routine) lex_variables() parser.NodeArray {
-	codeblock := r.__node.Child(parser.Rulecodeblock)
-	variables := codeblock.GetNodesByRule(parser.Rulevariable)
+	// Variables of nested routines are their own
+	variables := r.nodes(parser.Rulevariable)
 	// Variables that are in a parser.Rulefuncidentifier
 	// are not variables but actually function call
 	// function identifiers
@@ -46,6 +94,9 @@ func (r *Routine) lex_variables() parser.NodeArray {
 		return true
 	})
 	log_number_funccalls_saved(r.__name, number_pruned)
+	for _, node := range r.nodes(parser.Ruleroutine) {
+		variables = append(variables, nested_name(node))
+	}
 	return variables
 }
 
@@ -56,7 +107,7 @@ func (r *Routine) register_variable_positions(nodes parser.NodeArray) {
 }
 
 func (r *Routine) lex_funccalls() parser.NodeArray {
-	return r.__node.Child(parser.Rulecodeblock).GetNodesByRule(parser.Rulefunccall)
+	return r.nodes(parser.Rulefunccall)
 }
 
 func (r *Routine) register_funccalls(nodes parser.NodeArray) {
@@ -99,6 +150,13 @@ func (r *Routine) register_arguments(argnodes []*parser.Node) {
 	}
 }
 
+// local returns the variable called name if it has been
+// reserved.
+func (r *Routine) local(name string) (*VariableMeta, bool) {
+	variable, ok := r.vmap._map[name]
+	return variable, ok && variable.Allocated()
+}
+
 func (r *Routine) GetName() string {
 	return r.__name
 }
@@ -115,45 +173,135 @@ func (r *Routine) generate_ir() error {
 }
 
 func (r *Routine) generate_ir_head() error {
+	// Indirect calls enter at ENTRY, which checks the number of
+	// arguments they pushed; see IRFuncCall
+	entry := make([]byte, 0, entrySize)
+	byteadd(&entry, vm.ENTRY)
+	setL(&entry, len(r.args))
+	r.__IR.Add(&IRLabel{symbol: r.Symbol("entry")}, &IRLiteral{code: entry})
 	// Push the function symbol to the IR array
 	r.__IR.Add(&IRLabel{symbol: r.Symbol()})
+	// A closure is called with its address under the arguments
+	if err := r.load_captures(); err != nil {
+		return err
+	}
+	// The address to return a struct at comes before the arguments
+	if r.returned != nil {
+		result, err := r.temporary(r.__node)
+		if err != nil {
+			return err
+		}
+		r.__result = result
+		r.emit(vm.STPP, r.__result)
+	}
 	// Pop arguments from the stack into their registers
 	initcode := make([]byte, 0, (1+1)*len(r.args))
-	for _, argname := range r.args {
+	arguments := r.lex_arguments()
+	for i, argname := range r.args {
 		variable := r.vmap._map[argname]
 		variable.Allocate()
 		variable.register = r.registers.ReserveRegister()
+		if variable.register == nil {
+			return errorCannotReserveRegister(r, argname, arguments[i])
+		}
 		byteadd(&initcode, vm.STPP, byte(variable.register.id))
 	}
 	r.__IR.Add(&IRLiteral{code: initcode})
-	return nil
+	for _, name := range arguments {
+		if variable := r.vmap._map[name.Source()]; variable.cell {
+			if err := move_to_cell(r, variable, name); err != nil {
+				return err
+			}
+		}
+	}
+	if err := r.layout_frame(); err != nil {
+		return err
+	}
+	return r.copy_arguments()
 }
 
 func (r *Routine) generate_ir_body() error {
 
 	r.__IR.Add(&IRLabel{symbol: r.Symbol("body")})
 
-	statements_arr := parser.NodeArray(r.__node.Child(parser.Rulecodeblock).Children)
-
-	statements_arr.Filter(func(nd *parser.Node) bool {
-		if nd.Tok.Rule == parser.Rulecodestatement {
-			return true
-		}
-		return false
-	})
+	if err := genir_codeblock(r, r.__node.Child(parser.Rulecodeblock)); err != nil {
+		return err
+	}
+	if err := r.check_jumps(); err != nil {
+		return err
+	}
 
-	for _, statement := range statements_arr.CastPrimitive() {
-		if err := genir_codestatement(r, statement); err != nil {
+	// Routines that run off their end return 0, or empty strings
+	if r.returned != nil {
+		if err := clear_struct(r, r.returned, r.__result, r.__node); err != nil {
 			return err
 		}
 	}
+	values := make([]Value, len(r.returns))
+	for i, kind := range r.returns {
+		if kind == KindString {
+			values[i] = Value{Type: ValueConstant, Kind: KindString, Constant: r.__module.intern(""), Handle: true}
+		}
+	}
+	return genir_return(r, values, r.__node)
+}
 
-	return nil
+// emit appends an instruction to the routine's IR.
+func (r *Routine) emit(code ...interface{}) {
+	instruction := make([]byte, 0, len(code))
+	byteadd(&instruction, code...)
+	r.__IR.Add(&IRLiteral{code: instruction})
+}
+
+// emit_long emits "OP %reg imm32".
+func (r *Routine) emit_long(op vm.Operation, reg *Register, n int) {
+	code := make([]byte, 0, 1+1+4)
+	byteadd(&code, op, reg)
+	setL(&code, n)
+	r.__IR.Add(&IRLiteral{code: code})
+}
+
+// temporary reserves a register for an intermediate value.
+// It is freed with Unlock, or Value.release.
+func (r *Routine) temporary(node *parser.Node) (*Register, error) {
+	reg := r.registers.ReserveRegister()
+	if reg == nil {
+		return nil, errorCannotReserveTemporary(r, node)
+	}
+	return reg, nil
+}
+
+// exported is the name of the routine's symbol in its module's
+// object; see Object.
+func (r *Routine) exported() string {
+	return r.__module.prefix + "." + r.GetName()
+}
+
+// signature describes the kinds of the routine's arguments and
+// results, which its callers in other objects must agree on:
+// "(int, float) int".
+func (r *Routine) signature() string {
+	args := make([]string, len(r.args))
+	for i, name := range r.args {
+		variable := r.vmap._map[name]
+		args[i] = variable.kind.String()
+		if variable.structure != nil {
+			args[i] = variable.structure.name
+		}
+	}
+	results := make([]string, len(r.returns))
+	for i, kind := range r.returns {
+		results[i] = kind.String()
+	}
+	if r.returned != nil {
+		results = []string{r.returned.name}
+	}
+	return "(" + strings.Join(args, ", ") + ") " + strings.Join(results, ", ")
 }
 
 func (r *Routine) Symbol(subdivision ...string) string {
 	extended := strings.Join(subdivision, "$")
-	return strings.Join([]string{"", r.GetName(), extended}, "$")
+	return strings.Join([]string{"", r.path(), extended}, "$")
 }
 
 func NewRoutine() *Routine {
@@ -162,6 +310,7 @@ func NewRoutine() *Routine {
 	rout.vmap = NewVariablePool()
 	rout.__func_calls = parser.NewNodeArray()
 	rout.__IR = NewIRArray()
+	rout.__labels = make(map[string]*parser.Node)
 	return &rout
 }
 
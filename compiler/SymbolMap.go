@@ -20,3 +20,8 @@ func (m *SymbolMap) Exists(sym string) bool {
 	}
 	return false
 }
+
+// Get returns the value of sym, or nil if it does not exist.
+func (m *SymbolMap) Get(sym string) interface{} {
+	return m._map[sym]
+}
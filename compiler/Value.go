@@ -5,10 +5,29 @@ type ValueType byte
 const (
 	ValueConstant ValueType = iota
 	ValueVariable
+	ValueTemporary
 )
 
 type Value struct {
 	Type     ValueType
-	Constant int
+	Kind     Kind
+	Constant int     // of a constant int
+	Float    float64 // of a constant float
 	Register *Register
+	Handle   bool // the constant is the handle of a string
+}
+
+// release frees the register of a temporary value once it has
+// been used.
+func (v Value) release() {
+	if v.Type == ValueTemporary {
+		v.Register.Unlock()
+	}
+}
+
+// immediate reports whether v is a constant that an instruction
+// can take as its imm8 operand. The handles of strings are not,
+// as the linker renumbers them.
+func (v Value) immediate() bool {
+	return v.Type == ValueConstant && v.Kind != KindFloat && !v.Handle && fitsImmediate(v.Constant)
 }
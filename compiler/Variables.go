@@ -12,14 +12,22 @@ import (
 
 type VariableMeta struct {
 	name      string
-	locations []parser.State16
+	locations []parser.State32
 	register  *Register
 	allocated bool
+	length    int  // of an array, whose address is in register
+	kind      Kind // of the variable or the elements of the array
+	// the type name that declared kind, if there is one
+	declared  *parser.Node
+	structure *Struct // if the variable is a struct
+	// the register holds the address of the variable's cell on
+	// the heap, as it is captured by a closure
+	cell bool
 }
 
 func NewVariableMeta() VariableMeta {
 	v := VariableMeta{allocated: false}
-	v.locations = make([]parser.State16, 0, 5)
+	v.locations = make([]parser.State32, 0, 5)
 	return v
 }
 
@@ -68,7 +76,7 @@ func (p *VariablePool) AddInstance(varnode *parser.Node) {
 /**
  * Free registers of variables no longer referenced.
  */
-func (p *VariablePool) Free(location parser.State16) {
+func (p *VariablePool) Free(location parser.State32) {
 	for _, v := range p._map {
 		index := len(v.locations) - 1
 		if index < 0 {
@@ -0,0 +1,147 @@
+package compiler
+
+import (
+	"math"
+
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// array is the place of an array in the frame of its routine.
+type array struct {
+	offset int
+	length int
+}
+
+// layout_frame gives every array and struct reserved in the
+// routine, and every struct argument, a place in the routine's
+// frame. The frame is allocated in the VM's memory when the
+// routine is entered and freed when it returns, so every call
+// has arrays and structs of its own:
+//
+//	ALLOC %frame size
+//	...
+//	FREE %frame        // before every return
+func (r *Routine) layout_frame() error {
+	r.__arrays = make(map[*parser.Node]array)
+	r.__records = make(map[*parser.Node]int)
+	size := 0
+	for _, name := range r.lex_arguments() {
+		if s := r.__module.struct_of(name.Parent()); s != nil {
+			r.__records[name.Parent()] = size
+			size += len(s.fields)
+		}
+	}
+	for _, node := range r.nodes(parser.Rulereserved) {
+		lengthnode := node.Child(parser.Ruleexpr)
+		if lengthnode == nil {
+			if s := r.__module.struct_of(node); s != nil {
+				r.__records[node] = size
+				size += len(s.fields)
+			}
+			continue
+		}
+		name := node.Child(parser.Rulevariable)
+		length, err := genir_expr(r, lengthnode)
+		if err != nil {
+			return err
+		}
+		if length.Type != ValueConstant || length.Kind != KindInt || length.Constant <= 0 {
+			length.release()
+			return errorArrayLength(r, name)
+		}
+		r.__arrays[node] = array{offset: size, length: length.Constant}
+		if size += length.Constant; size > math.MaxInt32 {
+			return errorArrayLength(r, name)
+		}
+	}
+	if size == 0 {
+		return nil
+	}
+
+	frame, err := r.temporary(r.__node)
+	if err != nil {
+		return err
+	}
+	r.__frame = frame
+	r.emit_long(vm.ALLOC, frame, size)
+	return nil
+}
+
+// reserve_array points the register of variable at the array's
+// place in the frame.
+func reserve_array(r *Routine, variable *VariableMeta, a array, node *parser.Node) error {
+	variable.length = a.length
+	if err := load_constant(r, variable.register, a.offset, node); err != nil {
+		return err
+	}
+	r.emit(vm.ADDREG, variable.register, r.__frame)
+	return nil
+}
+
+// genir_element loads buf[i] into a temporary.
+func genir_element(r *Routine, node *parser.Node) (Value, error) {
+	address, array, err := element_address(r, node)
+	if err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.LOADM, address.Register, address.Register)
+	address.Kind = array.kind
+	return address, nil
+}
+
+// store_element stores v into buf[i].
+func store_element(r *Routine, node *parser.Node, v Value) error {
+	address, array, err := element_address(r, node)
+	if err != nil {
+		return err
+	}
+	if v, err = convert(r, v, array.kind, node); err != nil {
+		return err
+	}
+	if v, err = materialize(r, v, node); err != nil {
+		return err
+	}
+	r.emit(vm.STOREM, v.Register, address.Register)
+	address.release()
+	v.release()
+	return nil
+}
+
+// element_address computes the address of buf[i] into a
+// temporary and returns it with buf. Constant indexes are checked
+// now, all others when the program runs.
+func element_address(r *Routine, node *parser.Node) (Value, *VariableMeta, error) {
+	name := node.Child(parser.Rulevariable)
+	variable, ok := r.local(name.Source())
+	if !ok {
+		if r.__module.symbols.Exists(name.Source()) {
+			return Value{}, nil, errorNotAnArray(r, name)
+		}
+		return Value{}, nil, errorUndeclaredVariable(r, name)
+	}
+	if variable.length == 0 {
+		return Value{}, nil, errorNotAnArray(r, name)
+	}
+
+	index, err := genir_expr(r, node.Child(parser.Ruleexpr))
+	if err != nil {
+		return Value{}, nil, err
+	}
+	if index, err = convert(r, index, KindInt, node); err != nil {
+		return Value{}, nil, err
+	}
+	if index.Type == ValueConstant {
+		if index.Constant < 0 || index.Constant >= variable.length {
+			return Value{}, nil, errorIndexOutOfRange(r, node, index.Constant, variable.length)
+		}
+	} else {
+		r.emit_long(vm.BOUNDS, index.Register, variable.length)
+	}
+	address, err := writable(r, index, node)
+	if err != nil {
+		return Value{}, nil, err
+	}
+	r.emit(vm.ADDREG, address.Register, variable.register)
+	return address, variable, nil
+}
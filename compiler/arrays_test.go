@@ -0,0 +1,63 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/hfern/min/vm"
+)
+
+func TestArrays(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want int64
+	}{
+		{
+			"sum",
+			"routine main<> { res a[5], i, s; for (i = 0; i < 5; i = i + 1) { a[i] = i * i; } s = 0; for (i = 0; i < 5; i = i + 1) { s = s + a[i]; } return s; }",
+			30,
+		},
+		{
+			"zero",
+			"routine main<> { res a[3]; return a[0] + a[1] + a[2]; }",
+			0,
+		},
+		{
+			"two arrays",
+			"routine main<> { res a[2], b[2]; a[1] = 5; b[0] = 7; b[1] = 9; return a[1] * 100 + b[0] * 10 + b[1]; }",
+			579,
+		},
+		{
+			"constant length",
+			"const N = 4; routine main<> { res a[N * 2]; a[N * 2 - 1] = 3; return a[7]; }",
+			3,
+		},
+		{
+			"a frame per call",
+			"routine f<n> { res a[2]; a[0] = n; if (n > 0) { f(n - 1); } return a[0]; } routine main<> { return f(3); }",
+			3,
+		},
+	}
+	for _, test := range tests {
+		if got := run(t, test.src); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.name, got, test.want)
+		}
+	}
+
+	for _, index := range []string{"3", "-1"} {
+		compileError(t, "routine main<> { res a[3]; return a["+index+"]; }", "is out of range for an array of length 3.")
+	}
+	for _, index := range []string{"n", "n - 4"} {
+		src := "routine main<> { res a[3], n; n = 3; return a[" + index + "]; }"
+		if code := trap(t, src); code != vm.ERRINDEX {
+			t.Errorf("a[%s] gives code %d, want %d", index, code, vm.ERRINDEX)
+		}
+	}
+	if code := trap(t, "routine main<> { res a[3], n; n = 5; a[n] = 1; return 0; }"); code != vm.ERRINDEX {
+		t.Errorf("a[5] = 1 gives code %d, want %d", code, vm.ERRINDEX)
+	}
+
+	compileError(t, "routine main<> { res a[0]; return 0; }", "must be a positive constant")
+	compileError(t, "routine main<> { res a[2]; return a; }", `Array "a" at line 1 is used without an index.`)
+	compileError(t, "routine main<> { res x; return x[0]; }", `"x" at line 1 is indexed but is not an array.`)
+}
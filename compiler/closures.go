@@ -0,0 +1,287 @@
+package compiler
+
+import (
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// A routine declared in the code block of another is a closure:
+//
+//	routine counter<> {
+//		res n;
+//		n = 0;
+//		routine next<> { n = n + 1; return n; }
+//		return next;
+//	}
+//
+// Its name is a variable of the routine around it, which is set
+// to the closure when the declaration is run and is called like
+// any variable holding a reference. Like referenced routines,
+// closures take ints and return one int.
+//
+// The variables of the routines around a closure that it uses
+// are captured. Closures can outlive the frames of those
+// routines, so captured variables are moved to cells on the
+// heap: their registers hold the addresses of the cells instead
+// of their values. A closure is a record on the heap, made by
+// the declaration, with the address of the routine's ENTRY and
+// then those of the cells it captures; see vm.JR:
+//
+//	NEW %closure (1 + number of captures)
+//	SETL %word entry
+//	STOREM %word %closure
+//	STOREM %cell %closure+i  // for each capture
+//
+// A closure that captures nothing is only the address of its
+// ENTRY, like a reference.
+//
+// The heap is never freed, so every closure that captures and
+// every cell stays there until the program ends. A loop that
+// makes closures uses more memory with each turn, up to the
+// limit of the machine, where it traps with ERRMEMORYLIMIT; see
+// vm.Machine.MaxMemory.
+
+// enclosing_routine returns the routine node that node is in, or
+// nil.
+func enclosing_routine(node *parser.Node) *parser.Node {
+	for node = node.Parent(); node != nil; node = node.Parent() {
+		if node.Tok.Rule == parser.Ruleroutine {
+			return node
+		}
+	}
+	return nil
+}
+
+// nodes returns the nodes of rule in the routine's code block,
+// leaving out what is in the routines nested in it.
+func (r *Routine) nodes(rule parser.Rule) parser.NodeArray {
+	return r.__node.Child(parser.Rulecodeblock).GetNodesByRuleOutside(rule, parser.Ruleroutine)
+}
+
+// path names the routine after those it is nested in: outer.inner.
+func (r *Routine) path() string {
+	if r.__parent != nil {
+		return r.__parent.path() + "." + r.GetName()
+	}
+	return r.__module.qualify(r.GetName())
+}
+
+// nested returns the routine declared at node in the routine.
+func (r *Routine) nested(node *parser.Node) *Routine {
+	return r.__program.declared[node]
+}
+
+// link_nested checks the names of the routines nested in r,
+// which are variables of r, and works out the variables that
+// they capture from r and the routines around it.
+func (r *Routine) link_nested() error {
+	declared := make(map[string]*parser.Node)
+	for _, name := range r.lex_arguments() {
+		declared[name.Source()] = name
+	}
+	for _, node := range r.nodes(parser.Rulereserved) {
+		name := node.Child(parser.Rulevariable)
+		declared[name.Source()] = name
+	}
+	for _, node := range r.nodes(parser.Ruleroutine) {
+		name := nested_name(node)
+		if previous, ok := declared[name.Source()]; ok {
+			return errorSymbolAlreadyExists(r.__module, name, previous)
+		}
+		declared[name.Source()] = name
+	}
+	if r.__parent == nil {
+		return nil
+	}
+
+	for _, node := range r.nodes(parser.Rulevariable) {
+		name := node.Source()
+		if r.__locals[name] || node.Parent().Child(parser.Rulemodule) != nil {
+			// math.sq names a routine of a module
+			continue
+		}
+		// every routine between this one and the one that
+		// reserved the variable captures it to pass it on
+		for outer := r.__parent; outer != nil; outer = outer.__parent {
+			if outer.__locals[name] {
+				outer.vmap._map[name].cell = true
+				for inner := r; inner != outer; inner = inner.__parent {
+					inner.capture(node)
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// nested_name returns the name of the nested routine at node.
+func nested_name(node *parser.Node) *parser.Node {
+	return node.Child(parser.RulefuncIdDecl).Child(parser.Rulevariable)
+}
+
+// capture adds the variable that node names to the captures of
+// the routine.
+func (r *Routine) capture(node *parser.Node) {
+	for _, captured := range r.__captures {
+		if captured.Source() == node.Source() {
+			return
+		}
+	}
+	r.__captures = append(r.__captures, node)
+	if !r.vmap.Exists(node.Source()) {
+		r.vmap.AddInstance(node)
+	}
+	r.vmap._map[node.Source()].cell = true
+}
+
+// captured returns where the kind of the variable called name,
+// captured by the routine, is kept and the type name that
+// declared it, if any. The kind is nil if name is not captured.
+func (r *Routine) captured(name string) (*Kind, *parser.Node) {
+	for _, node := range r.__captures {
+		if node.Source() == name {
+			return r.__parent.kind_variable(name)
+		}
+	}
+	return nil, nil
+}
+
+// load_captures pops the address of the closure that the
+// routine was called through and loads the addresses of the
+// cells of its captures from it.
+func (r *Routine) load_captures() error {
+	if len(r.__captures) == 0 {
+		return nil
+	}
+	closure, err := r.temporary(r.__node)
+	if err != nil {
+		return err
+	}
+	defer closure.Unlock()
+	r.emit(vm.STPP, closure)
+	for i, node := range r.__captures {
+		variable := r.vmap._map[node.Source()]
+		if err := reserve_variable(r, node); err != nil {
+			return err
+		}
+		kind, declared := r.captured(node.Source())
+		variable.kind, variable.declared = *kind, declared
+		if err := field_address(r, variable.register, closure, 1+i, node); err != nil {
+			return err
+		}
+		r.emit(vm.LOADM, variable.register, variable.register)
+	}
+	return nil
+}
+
+// genir_closure runs the declaration of a nested routine: it
+// reserves the routine's name and sets it to a new closure.
+func genir_closure(r *Routine, node *parser.Node) error {
+	target := r.nested(node)
+	name := nested_name(node)
+	if !referable(target) {
+		return errorNestedRoutine(r, name)
+	}
+	if err := reserve_variable(r, name); err != nil {
+		return err
+	}
+	variable := r.vmap._map[name.Source()]
+	variable.length = 0
+	if variable.cell {
+		// the closure captures itself to call itself
+		if err := reserve_cell(r, variable, name); err != nil {
+			return err
+		}
+	}
+
+	closure, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	word, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	defer word.Unlock()
+	if len(target.__captures) == 0 {
+		r.emit(vm.SETL, closure)
+		r.__IR.Add(&IRJump{symbol: target.Symbol("entry")})
+	} else {
+		r.emit_long(vm.NEW, closure, 1+len(target.__captures))
+		r.emit(vm.SETL, word)
+		r.__IR.Add(&IRJump{symbol: target.Symbol("entry")})
+		r.emit(vm.STOREM, word, closure)
+	}
+	for i, captured := range target.__captures {
+		cell, ok := r.local(captured.Source())
+		if !ok {
+			return errorUndeclaredVariable(r, captured)
+		}
+		if err := field_address(r, word, closure, 1+i, node); err != nil {
+			return err
+		}
+		r.emit(vm.STOREM, cell.register, word)
+	}
+	return store_variable(r, variable, Value{Type: ValueTemporary, Register: closure}, node)
+}
+
+// reserve_cell gives a captured variable that has just been
+// reserved a new cell, which holds 0.
+func reserve_cell(r *Routine, variable *VariableMeta, node *parser.Node) error {
+	if variable.length > 0 || variable.structure != nil {
+		return errorCapturedAggregate(r, node)
+	}
+	r.emit_long(vm.NEW, variable.register, 1)
+	return nil
+}
+
+// move_to_cell moves a captured argument, which is in its
+// register, to a new cell.
+func move_to_cell(r *Routine, variable *VariableMeta, node *parser.Node) error {
+	if variable.structure != nil {
+		return errorCapturedAggregate(r, node)
+	}
+	value, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	r.emit(vm.MOV, value, variable.register)
+	r.emit_long(vm.NEW, variable.register, 1)
+	r.emit(vm.STOREM, value, variable.register)
+	value.Unlock()
+	return nil
+}
+
+// load_variable returns the value of a scalar variable, loading
+// it from its cell if it has one.
+func load_variable(r *Routine, variable *VariableMeta, node *parser.Node) (Value, error) {
+	if !variable.cell {
+		return Value{Type: ValueVariable, Kind: variable.kind, Register: variable.register}, nil
+	}
+	value, err := r.temporary(node)
+	if err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.LOADM, value, variable.register)
+	return Value{Type: ValueTemporary, Kind: variable.kind, Register: value}, nil
+}
+
+// store_variable stores v into a scalar variable or its cell.
+func store_variable(r *Routine, variable *VariableMeta, v Value, node *parser.Node) error {
+	v, err := convert(r, v, variable.kind, node)
+	if err != nil {
+		return err
+	}
+	if !variable.cell {
+		err = store(r, variable.register, v, node)
+		v.release()
+		return err
+	}
+	if v, err = materialize(r, v, node); err != nil {
+		return err
+	}
+	r.emit(vm.STOREM, v.Register, variable.register)
+	v.release()
+	return nil
+}
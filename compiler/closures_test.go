@@ -0,0 +1,75 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/hfern/min/vm"
+)
+
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want int64
+	}{
+		{
+			"captured",
+			"routine main<> { res n; n = 5; routine get<> { return n; } return get(); }",
+			5,
+		},
+		{
+			"sees later assignments",
+			"routine main<> { res n; n = 1; routine get<> { return n; } n = 7; return get(); }",
+			7,
+		},
+		{
+			"assigns",
+			"routine main<> { res n; n = 1; routine bump<> { n = n + 1; return n; } bump(); bump(); return n; }",
+			3,
+		},
+		{
+			"outlives its frame",
+			"routine counter<> { res n; n = 0; routine next<> { n = n + 1; return n; } return next; } " +
+				"routine main<> { res c; c = counter(); c(); c(); return c(); }",
+			3,
+		},
+		{
+			"frames of their own",
+			"routine counter<> { res n; n = 0; routine next<> { n = n + 1; return n; } return next; } " +
+				"routine main<> { res a, b; a = counter(); b = counter(); a(); a(); b(); return a() * 10 + b(); }",
+			32,
+		},
+		{
+			"captured argument",
+			"routine adder<x> { routine add<y> { return x + y; } return add; } " +
+				"routine main<> { res f; f = adder(10); return f(5); }",
+			15,
+		},
+		{
+			"nested twice",
+			"routine main<> { res n; n = 2; routine outer<> { routine inner<> { return n * 3; } return inner(); } return outer(); }",
+			6,
+		},
+		{
+			"captures nothing",
+			"routine main<> { routine one<> { return 1; } return one() + one(); }",
+			2,
+		},
+	}
+	for _, test := range tests {
+		if got := run(t, test.src); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+// Closures are never freed, so making them in a loop reaches the
+// memory limit of the machine rather than the end of the host's
+// memory.
+func TestClosuresReachMemoryLimit(t *testing.T) {
+	src := "routine make<x> { routine get<> { return x; } return get; } " +
+		"routine main<> { res f; while (1) { f = make(1); } return 0; }"
+	if code := trap(t, src); code != vm.ERRMEMORYLIMIT {
+		t.Errorf("got code %d, want %d", code, vm.ERRMEMORYLIMIT)
+	}
+}
@@ -2,12 +2,15 @@ package compiler
 
 import (
 	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
 )
 
 func genir_codestatement(r *Routine, node *parser.Node) error {
 	return handlepairs(r, node.Children[0], []ruleHandler{
 		{parser.Ruleoperation, genir_operation},
 		{parser.Rulelogicblock, genir_logicblock},
+		{parser.Ruleloop, genir_loop},
+		{parser.Ruleroutine, genir_closure},
 	})
 }
 
@@ -18,8 +21,12 @@ func genir_operation(rout *Routine, node *parser.Node) error {
 		{parser.Rulereservation, genir_reservation},
 		{parser.Rulereturning, genir_returning},
 		{parser.Ruleassignment, genir_assignment},
+		{parser.Ruledestructuring, genir_destructuring},
 		{parser.Rulelabeling, genir_labeling},
 		{parser.Rulejumping, genir_jumping},
+		{parser.Rulefunccall, genir_calling},
+		{parser.Rulebreaking, genir_breaking},
+		{parser.Rulecontinuing, genir_continuing},
 	})
 }
 
@@ -37,35 +44,209 @@ func reserve_variable(r *Routine, node *parser.Node) error {
 // Allocate a list of variables.
 // res a;
 // res a, b, c, ...;
+// res buf[64], ...;
+// res p: Point, ...;
 func genir_reservation(r *Routine, node *parser.Node) error {
 	for _, child := range node.Children {
-		if child.Tok.Rule == parser.Rulevariable {
-			if err := reserve_variable(r, child); err != nil {
+		if child.Tok.Rule != parser.Rulereserved {
+			continue
+		}
+		name := child.Child(parser.Rulevariable)
+		if err := reserve_variable(r, name); err != nil {
+			return err
+		}
+		variable := r.vmap._map[name.Source()]
+		variable.length = 0
+		if array, ok := r.__arrays[child]; ok {
+			if err := reserve_array(r, variable, array, name); err != nil {
+				return err
+			}
+		}
+		if offset, ok := r.__records[child]; ok {
+			if err := reserve_record(r, variable, offset, name); err != nil {
+				return err
+			}
+		}
+		if variable.cell {
+			if err := reserve_cell(r, variable, name); err != nil {
 				return err
 			}
 		}
 	}
 	return nil
 }
+
+// return a;
+// return q, r;
 func genir_returning(r *Routine, node *parser.Node) error {
-	panic("Not Implemented!")
+	exprs := returned_exprs(node)
+	if r.returned != nil {
+		if err := assign_struct(r, r.returned, r.__result, exprs[0]); err != nil {
+			return err
+		}
+		return genir_return(r, []Value{{Type: ValueConstant}}, node)
+	}
+	values := make([]Value, len(exprs))
+	for i, expr := range exprs {
+		value, err := genir_expr(r, expr)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+	return genir_return(r, values, node)
+}
+
+// returned_exprs returns the expressions of a return statement.
+func returned_exprs(node *parser.Node) parser.NodeArray {
+	exprs := parser.NodeArray{}
+	for _, child := range node.Children {
+		if child.Tok.Rule == parser.Ruleexpr {
+			exprs = append(exprs, child)
+		}
+	}
+	return exprs
+}
+
+// genir_return returns values to the caller, which left the
+// return address on top of the stack. They are pushed last
+// first, so that the caller pops the first one first.
+func genir_return(r *Routine, values []Value, node *parser.Node) error {
+	for i, value := range values {
+		value, err := convert(r, value, r.returns[i], node)
+		if err != nil {
+			return err
+		}
+		if value.Type == ValueConstant && !value.immediate() {
+			if value, err = materialize(r, value, node); err != nil {
+				return err
+			}
+		}
+		values[i] = value
+	}
+	address, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	r.emit(vm.STPP, address)
+	if r.__frame != nil {
+		r.emit(vm.FREE, r.__frame)
+	}
+	for i := len(values) - 1; i >= 0; i-- {
+		if value := values[i]; value.Type == ValueConstant {
+			r.emit(vm.STPS, byte(value.Constant))
+		} else {
+			r.emit(vm.STPR, value.Register)
+		}
+	}
+	r.emit(vm.JE, address, address, address)
+	address.Unlock()
+	for _, value := range values {
+		value.release()
+	}
 	return nil
 }
+
 func genir_assignment(r *Routine, node *parser.Node) error {
-	panic("Not Implemented!")
-	return nil
+	name := node.Child(parser.Rulevariable)
+	if name != nil {
+		if variable, ok := r.local(name.Source()); ok && variable.structure != nil {
+			return assign_struct(r, variable.structure, variable.register, node.Child(parser.Ruleexpr))
+		}
+	}
+	value, err := genir_expr(r, node.Child(parser.Ruleexpr))
+	if err != nil {
+		return err
+	}
+	return assign(r, node, value)
 }
-func genir_labeling(r *Routine, node *parser.Node) error {
-	panic("Not Implemented!")
+
+// print(x);
+// A call on its own drops its results.
+func genir_calling(r *Routine, node *parser.Node) error {
+	name := node.Child(parser.Rulefuncidentifier).Source()
+	target, ok := r.lookup_routine(name)
+	if _, local := r.local(name); ok && !local && target.returned == nil && len(target.returns) != 1 {
+		values, err := genir_call(r, node, target, nil)
+		for _, value := range values {
+			value.release()
+		}
+		return err
+	}
+	value, err := genir_funccall(r, node)
+	if err != nil {
+		return err
+	}
+	value.release()
 	return nil
 }
-func genir_jumping(r *Routine, node *parser.Node) error {
-	panic("Not Implemented!")
+
+// q, r = divmod(x, y);
+func genir_destructuring(r *Routine, node *parser.Node) error {
+	targets := node.GetNodesByRule(parser.Ruletarget)
+	expr := node.Child(parser.Ruleexpr)
+	call := single_value(expr)
+	if call == nil || call.Tok.Rule != parser.Rulefunccall {
+		return errorResultCount(r, expr, expr.Source(), 1, len(targets))
+	}
+	name := call.Child(parser.Rulefuncidentifier).Source()
+	if _, local := r.local(name); local {
+		return errorResultCount(r, call, name, 1, len(targets))
+	}
+	target, ok := r.lookup_routine(name)
+	if !ok {
+		if _, builtin := builtins[name]; builtin {
+			return errorResultCount(r, call, name, 1, len(targets))
+		}
+		if _, conversion := conversions[name]; conversion {
+			return errorResultCount(r, call, name, 1, len(targets))
+		}
+		if _, extern := r.lookup_extern(name); extern {
+			return errorResultCount(r, call, name, 1, len(targets))
+		}
+		return errorUnknownRoutine(r, name, call)
+	}
+	if len(target.returns) != len(targets) {
+		return errorResultCount(r, call, name, len(target.returns), len(targets))
+	}
+
+	values, err := genir_call(r, call, target, nil)
+	if err != nil {
+		return err
+	}
+	for i, assigned := range targets {
+		if err := assign(r, assigned, values[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func genir_logicblock(r *Routine, node *parser.Node) error {
-	//TODO(hunter): implement logic blocks
-	panic("Not Implemented!")
-	return nil
+// assign stores value into what is assigned to by an assignment
+// or a target of a destructuring: a variable, an element of an
+// array or a field of a struct.
+func assign(r *Routine, node *parser.Node, value Value) error {
+	if element := node.Child(parser.Ruleelement); element != nil {
+		return store_element(r, element, value)
+	}
+	if member := node.Child(parser.Rulemember); member != nil {
+		return store_member(r, member, value)
+	}
+	name := node.Child(parser.Rulevariable)
+	if variable, ok := r.local(name.Source()); ok {
+		if variable.length > 0 {
+			return errorArrayAsValue(r, name)
+		}
+		if variable.structure != nil {
+			return errorStructAsValue(r, name)
+		}
+		return store_variable(r, variable, value, node)
+	}
+	switch symbol := r.__module.symbols.Get(name.Source()).(type) {
+	case *Global:
+		return store_global(r, symbol, value, node)
+	case *Constant:
+		return errorAssignmentToConstant(r, name)
+	}
+	return errorUndeclaredVariable(r, name)
 }
@@ -0,0 +1,59 @@
+package compiler
+
+import (
+	"testing"
+)
+
+const divmod = "routine divmod<a, b> { return a / b, a % b; } "
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want int64
+	}{
+		{"two", "routine main<> { res q, r; q, r = divmod(17, 5); return q * 10 + r; }", 32},
+		{"swapped", "routine main<> { res q, r; r, q = divmod(17, 5); return q * 10 + r; }", 23},
+		{
+			"three",
+			"routine three<> { return 1, 2, 3; } routine main<> { res a, b, c; a, b, c = three(); return a * 100 + b * 10 + c; }",
+			123,
+		},
+		{
+			"into an array and a global",
+			"global G; routine main<> { res a[2]; a[1], G = divmod(9, 4); return a[1] * 10 + G; }",
+			21,
+		},
+		{
+			"into fields",
+			"struct P { x, y } routine main<> { res p: P; p.x, p.y = divmod(9, 4); return p.x * 10 + p.y; }",
+			21,
+		},
+		{
+			"mixed kinds",
+			"routine split<f: float> : int, float { return int(f), f - float(int(f)); } " +
+				"routine main<> { res i, f; i, f = split(2.5); return i * 10 + int(f * 10.0); }",
+			25,
+		},
+	}
+	for _, test := range tests {
+		if got := run(t, divmod+test.src); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.name, got, test.want)
+		}
+	}
+
+	invalid := []struct {
+		src  string
+		want string
+	}{
+		{"routine main<> { res a, b, c; a, b, c = divmod(1, 2); return 0; }", `Expected 3 values at line 1 but "divmod" gives 2.`},
+		{"routine main<> { res a; a = divmod(1, 2); return a; }", `Expected 1 value at line 1 but "divmod" gives 2.`},
+		{"routine main<> { return divmod(1, 2) + 1; }", `but "divmod" gives 2.`},
+		{"routine main<> { res a, b; a, b = 1 + 2; return 0; }", `Expected 2 values at line 1 but "1 + 2" gives 1.`},
+		{"routine main<> { res a, b; a, b = length(\"x\"); return 0; }", `Expected 2 values at line 1 but "length" gives 1.`},
+		{"routine f<x> { if (x) { return 1, 2; } return 1; } routine main<> { return 0; }", `Return at line 1 gives 1 value but routine "f" returns 2.`},
+	}
+	for _, test := range invalid {
+		compileError(t, divmod+test.src, test.want)
+	}
+}
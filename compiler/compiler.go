@@ -2,13 +2,21 @@ package compiler
 
 import (
 	//"fmt"
+	"io/fs"
+
 	"github.com/hfern/min/parser"
 	//"log"
 )
 
 type Compiler struct {
-	tree    *parser.VMTree
-	program *Program
+	tree       *parser.VMTree
+	program    *Program
+	source     string
+	path       string   // of the main module
+	searchpath []string // for imports; see Module
+	files      fs.FS    // that imports are read from, if not nil
+	noimports  bool     // imports are an error
+	separately bool     // only the main module is compiled
 }
 
 func NewCompiler() *Compiler {
@@ -24,31 +32,114 @@ func (c *Compiler) SetTree(t *parser.VMTree) {
 }
 
 func (c *Compiler) SetSource(source string) {
-	c.program.sourcecode = source
+	c.source = source
+}
+
+// SetPath sets the file that the source was read from. Imports
+// are relative to its directory, or to the working directory if
+// it is not set.
+func (c *Compiler) SetPath(path string) {
+	c.path = path
+}
+
+// SetSearchPath sets the directories in which imports that are
+// not found next to the importing file are looked for, in order.
+func (c *Compiler) SetSearchPath(dirs ...string) {
+	c.searchpath = dirs
+}
+
+// SetFS makes imports read from fsys rather than from the file
+// system of the OS. Their paths, and those of the search path,
+// are then slash separated and relative to the root of fsys, and
+// cannot leave it.
+func (c *Compiler) SetFS(fsys fs.FS) {
+	c.files = fsys
+}
+
+// DisableImports makes importing a module an error, for programs
+// that should not read files.
+func (c *Compiler) DisableImports() {
+	c.noimports = true
 }
 
+// Compile compiles the main module and the modules it imports
+// into a program; see Bytecode.
 func (c *Compiler) Compile() error {
-	var err error = nil
+	if err := c.compile(); err != nil {
+		return err
+	}
+	return c.program.assemble()
+}
+
+// CompileImage compiles the main module and the modules it
+// imports into an image, whose routines are called one by one by
+// the program embedding the VM; see LinkImage.
+func (c *Compiler) CompileImage() (*Image, error) {
+	if err := c.compile(); err != nil {
+		return nil, err
+	}
+	objects, err := c.program.objects()
+	if err != nil {
+		return nil, err
+	}
+	return LinkImage(objects)
+}
+
+// CompileObject compiles the main module on its own into an
+// object, which is linked with the objects of the modules it
+// imports; see Link. The imported modules are only checked, to
+// call their routines.
+func (c *Compiler) CompileObject() (*Object, error) {
+	c.separately = true
+	if err := c.compile(); err != nil {
+		return nil, err
+	}
+	return c.program.modules[0].object()
+}
 
+func (c *Compiler) compile() (err error) {
 	defer func() {
-		rec_err := recover()
-		if rec_err != nil {
-			err = rec_err.(error)
+		switch rec_err := recover().(type) {
+		case nil:
+		case error:
+			err = rec_err
+		default:
+			err = newError("Internal compiler error: ", rec_err)
 		}
-		return
 	}()
 
-	routineNodes := c.tree.ASTTree.GetNodesByRule(parser.Ruleroutine)
+	main := NewModule(c.path, c.source, c.tree)
+	c.program.addModule(main)
+	if err = c.load_imports(main, nil); err != nil {
+		return err
+	}
 
-	for _, node := range routineNodes {
-		rout := NewRoutineNP(node, c.program)
-		c.program.addRoutine(rout)
+	for _, m := range c.program.modules {
+		routineNodes := m.tree.ASTTree.GetNodesByRule(parser.Ruleroutine)
+
+		// routines come before those nested in them
+		routines := make(map[*parser.Node]*Routine, len(routineNodes))
+		for _, node := range routineNodes {
+			rout := NewRoutineNP(node, c.program)
+			rout.__module = m
+			rout.__parent = routines[enclosing_routine(node)]
+			routines[node] = rout
+			c.program.addRoutine(rout)
+		}
 	}
 
 	if err = c.lex_all(); err != nil {
 		return err
 	}
 
+	if err = c.lex_declarations(); err != nil {
+		return err
+	}
+
+	if err = c.program.check_types(); err != nil {
+		return err
+	}
+
 	if err = c.generate_ir(); err != nil {
 		return err
 	}
@@ -56,14 +147,28 @@ func (c *Compiler) Compile() error {
 	return err
 }
 
+// Bytecode returns the byte code of the last compiled program.
+func (c *Compiler) Bytecode() []byte {
+	return c.program.bytecode
+}
+
 func (c *Compiler) lex_all() error {
 	// TODO: Consider making function
 	// process routines in paralell
 	for _, rout := range c.program.routines {
 		rout.lex()
-		err := c.program.linkRoutine(rout)
+		if rout.__parent != nil {
+			// nested routines are variables, not linked by name
+			continue
+		}
+		err := rout.__module.linkRoutine(rout)
 		if err != nil {
-			return err
+			return rout.__module.at(err)
+		}
+	}
+	for _, rout := range c.program.routines {
+		if err := rout.link_nested(); err != nil {
+			return rout.__module.at(err)
 		}
 	}
 	return nil
@@ -71,9 +176,12 @@ func (c *Compiler) lex_all() error {
 
 func (c *Compiler) generate_ir() error {
 	for _, rout := range c.program.routines {
+		if c.separately && rout.__module.__index != 0 {
+			continue
+		}
 		err := rout.generate_ir()
 		if err != nil {
-			return err
+			return rout.__module.at(err)
 		}
 	}
 	return nil
@@ -0,0 +1,171 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// compile compiles src as the main module of a program found at
+// path, which may be "".
+func compile(src, path string, search ...string) (*Compiler, error) {
+	tree := &parser.VMTree{Buffer: src}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		return nil, err
+	}
+	tree.ParseTree()
+
+	cmp := NewCompiler()
+	cmp.SetTree(tree)
+	cmp.SetSource(src)
+	cmp.SetPath(path)
+	cmp.SetSearchPath(search...)
+	return cmp, cmp.Compile()
+}
+
+// compileError fails unless compiling src fails with an error that
+// contains want.
+func compileError(t *testing.T, src, want string) {
+	t.Helper()
+	_, err := compile(src, "")
+	if err == nil {
+		t.Errorf("%q compiles, want an error with %q", src, want)
+	} else if !strings.Contains(err.Error(), want) {
+		t.Errorf("%q: got error %q, want one with %q", src, err, want)
+	}
+}
+
+// run compiles and runs src, and returns the result of main.
+func run(t *testing.T, src string) int64 {
+	t.Helper()
+	cmp, err := compile(src, "")
+	if err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	m := vm.NewMachine(cmp.Bytecode())
+	m.Stdout = io.Discard
+	if err := m.Trap(m.Run()); err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	return m.Registers[vm.REGA]
+}
+
+// trap compiles and runs src, and returns the code of the trap
+// that it stops with.
+func trap(t *testing.T, src string) vm.Operation {
+	t.Helper()
+	cmp, err := compile(src, "")
+	if err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	m := vm.NewMachine(cmp.Bytecode())
+	m.Stdout = io.Discard
+	m.MaxMemory = 1 << 20
+	return m.Run()
+}
+
+// benchmarkProgram returns a program of roughly n lines made of
+// many small routines.
+func benchmarkProgram(n int) string {
+	src := &strings.Builder{}
+	fmt.Fprintf(src, "routine main<> {\n\treturn 1;\n}\n\n")
+	for i := 0; i < n/7; i++ {
+		fmt.Fprintf(src, "// routine %d\n", i)
+		fmt.Fprintf(src, "routine r%d<a, b> {\n", i)
+		fmt.Fprintf(src, "\tres c, d;\n")
+		fmt.Fprintf(src, "\tres e;\n")
+		fmt.Fprintf(src, "\troutine f<> { return e; }\n")
+		fmt.Fprintf(src, "}\n\n")
+	}
+	return src.String()
+}
+
+func benchmarkCompile(b *testing.B, lines int) {
+	src := benchmarkProgram(lines)
+	b.SetBytes(int64(len(src)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compileSource(b, src)
+	}
+}
+
+// compileSource parses and compiles src the way the command does.
+func compileSource(b *testing.B, src string) {
+	tree := &parser.VMTree{Buffer: src}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		b.Fatal(err)
+	}
+	tree.ParseTree()
+
+	cmp := NewCompiler()
+	cmp.SetTree(tree)
+	cmp.SetSource(src)
+	if err := cmp.Compile(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkCompile1k(b *testing.B)  { benchmarkCompile(b, 1000) }
+func BenchmarkCompile10k(b *testing.B) { benchmarkCompile(b, 10000) }
+func BenchmarkCompile40k(b *testing.B) { benchmarkCompile(b, 40000) }
+
+// Compiling four times the lines should take about four times as
+// long; a step that is quadratic in the program makes it sixteen.
+// The slack is for the caches, which a larger program outgrows.
+func BenchmarkCompileScaling(b *testing.B) {
+	small, large := benchmarkProgram(10000), benchmarkProgram(40000)
+	var tsmall, tlarge time.Duration
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		compileSource(b, small)
+		tsmall += time.Since(start)
+
+		start = time.Now()
+		compileSource(b, large)
+		tlarge += time.Since(start)
+	}
+	ratio := float64(tlarge) / float64(tsmall)
+	b.ReportMetric(ratio, "ratio")
+	if ratio > 5 {
+		b.Errorf("40k lines compile %.1f times slower than 10k lines, want at most 5", ratio)
+	}
+}
+
+func TestCallStatement(t *testing.T) {
+	tests := []struct {
+		src  string
+		want int64
+	}{
+		{`global N; routine bump<n> { N = N + n; return N; } routine main<> { bump(2); bump(3); return N; }`, 5},
+		{`global N; routine two<> { N = 7; return 1, 2; } routine main<> { two(); return N; }`, 7},
+		{`routine main<> { res f; f = &seven; f(); return 1; } routine seven<> { return 7; }`, 1},
+		{`routine main<> { res s; s = "ab"; prints(s); length(s); return 0; }`, 0},
+		{`routine main<> { res i; for (i = 0; i < 3; i = i + 1) { print(i); } return i; }`, 3},
+	}
+	for _, test := range tests {
+		if got := run(t, test.src); got != test.want {
+			t.Errorf("%q gives %d, want %d", test.src, got, test.want)
+		}
+	}
+	compileError(t, `routine main<> { nothing(); return 0; }`, `nothing`)
+	compileError(t, `routine main<> { exit(); return 0; }`, `exit`)
+}
+
+// The result of main is the exit status of the program.
+func TestMainResult(t *testing.T) {
+	for _, src := range []string{
+		"routine main<> { return 1.5; }",
+		"routine main<> { return \"x\"; }",
+		"routine main<x> { return x; }",
+		"routine main<> { return 1, 2; }",
+	} {
+		compileError(t, src, `Routine "main" at line 1 must take no arguments and return one int.`)
+	}
+}
@@ -0,0 +1,465 @@
+package compiler
+
+import (
+	"strconv"
+
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// loop holds the labels that break and continue jump to from
+// within a loop.
+type loop struct {
+	continuelabel string
+	breaklabel    string
+}
+
+type relation struct {
+	jumps    []vm.Operation // taken if any of them jumps
+	fjumps   []vm.Operation // the same for floats
+	negation parser.Rule    // for ints; NaN is neither < nor >=
+}
+
+var relations = map[parser.Rule]relation{
+	parser.Ruletokeq: {[]vm.Operation{vm.JE}, []vm.Operation{vm.FJE}, parser.Ruletokne},
+	parser.Ruletokne: {[]vm.Operation{vm.JNE}, []vm.Operation{vm.FJNE}, parser.Ruletokeq},
+	parser.Ruletoklt: {[]vm.Operation{vm.JL}, []vm.Operation{vm.FJL}, parser.Ruletokge},
+	parser.Ruletokgt: {[]vm.Operation{vm.JG}, []vm.Operation{vm.FJG}, parser.Ruletokle},
+	parser.Ruletokle: {[]vm.Operation{vm.JL, vm.JE}, []vm.Operation{vm.FJL, vm.FJE}, parser.Ruletokgt},
+	parser.Ruletokge: {[]vm.Operation{vm.JG, vm.JE}, []vm.Operation{vm.FJG, vm.FJE}, parser.Ruletoklt},
+}
+
+// genir_codeblock generates the statements of a code block.
+func genir_codeblock(r *Routine, node *parser.Node) error {
+	for _, statement := range node.Children {
+		if statement.Tok.Rule != parser.Rulecodestatement {
+			continue
+		}
+		if err := genir_codestatement(r, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// if (cond) { ... } else { ... }
+//
+//	    branch to $else unless cond
+//	    ...
+//	    jump $end
+//	$else:
+//	    ...
+//	$end:
+func genir_logicblock(r *Routine, node *parser.Node) error {
+	ifblock := node.Child(parser.Ruleifblock)
+	elseblock := node.Child(parser.Ruleelseblock)
+	block := strconv.Itoa(r.next_block())
+	elselabel := r.Symbol("if", block, "else")
+	endlabel := r.Symbol("if", block, "end")
+
+	if err := genir_branch(r, ifblock.Child(parser.Rulecomparison_paren), false, elselabel); err != nil {
+		return err
+	}
+	if err := genir_codeblock(r, ifblock.Child(parser.Rulecodeblock)); err != nil {
+		return err
+	}
+	if elseblock != nil {
+		if err := genir_goto(r, endlabel, node); err != nil {
+			return err
+		}
+	}
+	r.__IR.Add(&IRLabel{symbol: elselabel})
+	if elseblock != nil {
+		if err := genir_codeblock(r, elseblock.Child(parser.Rulecodeblock)); err != nil {
+			return err
+		}
+		r.__IR.Add(&IRLabel{symbol: endlabel})
+	}
+	return nil
+}
+
+func genir_loop(r *Routine, node *parser.Node) error {
+	return handlepairs(r, node.Children[0], []ruleHandler{
+		{parser.Rulewhileloop, genir_whileloop},
+		{parser.Ruleforloop, genir_forloop},
+	})
+}
+
+// while (cond) { ... }
+//
+//	$continue:
+//	    branch to $break unless cond
+//	    ...
+//	    jump $continue
+//	$break:
+func genir_whileloop(r *Routine, node *parser.Node) error {
+	l := r.push_loop(strconv.Itoa(r.next_block()))
+	r.__IR.Add(&IRLabel{symbol: l.continuelabel})
+	if err := genir_branch(r, node.Child(parser.Rulecomparison_paren), false, l.breaklabel); err != nil {
+		return err
+	}
+	if err := genir_codeblock(r, node.Child(parser.Rulecodeblock)); err != nil {
+		return err
+	}
+	if err := genir_goto(r, l.continuelabel, node); err != nil {
+		return err
+	}
+	r.__IR.Add(&IRLabel{symbol: l.breaklabel})
+	r.pop_loop()
+	return nil
+}
+
+// for (init; cond; step) { ... }
+//
+//	    init
+//	$top:
+//	    branch to $break unless cond
+//	    ...
+//	$continue:
+//	    step
+//	    jump $top
+//	$break:
+//
+// A missing condition is always true.
+func genir_forloop(r *Routine, node *parser.Node) error {
+	if init := node.Child(parser.Ruleforinit); init != nil {
+		if err := genir_assignment(r, init.Child(parser.Ruleassignment)); err != nil {
+			return err
+		}
+	}
+	block := strconv.Itoa(r.next_block())
+	l := r.push_loop(block)
+	toplabel := r.Symbol("loop", block, "top")
+	r.__IR.Add(&IRLabel{symbol: toplabel})
+	if cond := node.Child(parser.Rulecondition); cond != nil {
+		if err := genir_branch(r, cond, false, l.breaklabel); err != nil {
+			return err
+		}
+	}
+	if err := genir_codeblock(r, node.Child(parser.Rulecodeblock)); err != nil {
+		return err
+	}
+	r.__IR.Add(&IRLabel{symbol: l.continuelabel})
+	if step := node.Child(parser.Ruleforstep); step != nil {
+		if err := genir_assignment(r, step.Child(parser.Ruleassignment)); err != nil {
+			return err
+		}
+	}
+	if err := genir_goto(r, toplabel, node); err != nil {
+		return err
+	}
+	r.__IR.Add(&IRLabel{symbol: l.breaklabel})
+	r.pop_loop()
+	return nil
+}
+
+func genir_breaking(r *Routine, node *parser.Node) error {
+	if len(r.__loops) == 0 {
+		return errorOutsideLoop(r, node)
+	}
+	return genir_goto(r, r.__loops[len(r.__loops)-1].breaklabel, node)
+}
+
+func genir_continuing(r *Routine, node *parser.Node) error {
+	if len(r.__loops) == 0 {
+		return errorOutsideLoop(r, node)
+	}
+	return genir_goto(r, r.__loops[len(r.__loops)-1].continuelabel, node)
+}
+
+func genir_labeling(r *Routine, node *parser.Node) error {
+	name := node.Child(parser.Rulevariable)
+	if previous, ok := r.__labels[name.Source()]; ok {
+		return errorLabelAlreadyExists(r, name, previous)
+	}
+	r.__labels[name.Source()] = name
+	r.__IR.Add(&IRLabel{symbol: r.Symbol("label", name.Source())})
+	return nil
+}
+
+func genir_jumping(r *Routine, node *parser.Node) error {
+	name := node.Child(parser.Rulevariable)
+	// the label may come later in the routine; see check_jumps
+	r.__jumps = append(r.__jumps, name)
+	return genir_goto(r, r.Symbol("label", name.Source()), node)
+}
+
+// check_jumps makes sure that every jump in the routine has a
+// label to go to.
+func (r *Routine) check_jumps() error {
+	for _, name := range r.__jumps {
+		if _, ok := r.__labels[name.Source()]; !ok {
+			return errorUndefinedLabel(r, name)
+		}
+	}
+	return nil
+}
+
+// genir_branch jumps to the label for target if the condition
+// in node evaluates to when, and falls through otherwise. The
+// operands of && and || are evaluated left to right and only
+// for as long as the outcome is undecided.
+func genir_branch(r *Routine, node *parser.Node, when bool, target string) error {
+	operands := operands(node)
+	switch node.Tok.Rule {
+	case parser.Rulecomparison_paren:
+		return genir_branch(r, node.Child(parser.Rulecondition), when, target)
+	case parser.Rulecondition:
+		return genir_branch(r, node.Child(parser.Ruleexpr), when, target)
+	case parser.Ruleexpr, parser.Ruleconjunction:
+		if len(operands) == 1 {
+			return genir_branch(r, operands[0], when, target)
+		}
+		// a || b jumps as soon as one operand is true and
+		// a && b as soon as one is false. Otherwise the jump
+		// depends on the last operand.
+		decisive := node.Tok.Rule == parser.Ruleexpr
+		skip := r.Symbol("bool", strconv.Itoa(r.next_block()), "skip")
+		for _, operand := range operands[:len(operands)-1] {
+			label := target
+			if decisive != when {
+				label = skip
+			}
+			if err := genir_branch(r, operand, decisive, label); err != nil {
+				return err
+			}
+		}
+		if err := genir_branch(r, operands[len(operands)-1], when, target); err != nil {
+			return err
+		}
+		r.__IR.Add(&IRLabel{symbol: skip})
+		return nil
+	case parser.Rulecomparison:
+		if len(operands) == 1 {
+			return genir_branch(r, operands[0], when, target)
+		}
+		rel := node.Child(parser.Rulecomparisontoken).Children[0].Tok.Rule
+		return genir_compare(r, node, operands[0], operands[1], rel, when, target)
+	case parser.Rulesum, parser.Ruleterm:
+		if len(operands) == 1 {
+			return genir_branch(r, operands[0], when, target)
+		}
+	case parser.Ruleunary:
+		if node.Child(parser.Ruletoknot) != nil {
+			return genir_branch(r, node.Child(parser.Ruleunary), !when, target)
+		}
+		if primary := node.Child(parser.Ruleprimary); primary != nil {
+			return genir_branch(r, primary, when, target)
+		}
+	case parser.Ruleprimary:
+		if inner := node.Child(parser.Ruleexpr); inner != nil {
+			return genir_branch(r, inner, when, target)
+		}
+	}
+	// any other value is true unless it is 0
+	return genir_compare(r, node, node, nil, parser.Ruletokne, when, target)
+}
+
+// genir_boolean evaluates a condition to 1 if it holds and 0
+// if it does not.
+func genir_boolean(r *Routine, node *parser.Node) (Value, error) {
+	block := strconv.Itoa(r.next_block())
+	falselabel := r.Symbol("bool", block, "false")
+	endlabel := r.Symbol("bool", block, "end")
+
+	if err := genir_branch(r, node, false, falselabel); err != nil {
+		return Value{}, err
+	}
+	result, err := r.temporary(node)
+	if err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.SET, result, byte(1))
+	if err := genir_goto(r, endlabel, node); err != nil {
+		return Value{}, err
+	}
+	r.__IR.Add(&IRLabel{symbol: falselabel})
+	r.emit(vm.SET, result, byte(0))
+	r.__IR.Add(&IRLabel{symbol: endlabel})
+	return Value{Type: ValueTemporary, Register: result}, nil
+}
+
+// genir_compare jumps to target if lhs rel rhs is when. A nil
+// rhs compares against 0. Strings compare by their text.
+func genir_compare(r *Routine, node, lhs, rhs *parser.Node, rel parser.Rule, when bool, target string) error {
+	a, err := genir_expr(r, lhs)
+	if err != nil {
+		return err
+	}
+	b := Value{Type: ValueConstant, Constant: 0}
+	if rhs != nil {
+		if b, err = genir_expr(r, rhs); err != nil {
+			return err
+		}
+	} else if a.Kind == KindString {
+		return errorKindMismatch(r, lhs, KindString, KindInt)
+	}
+	if a.Kind == KindString || b.Kind == KindString {
+		if a, err = compare_strings(r, node, a, b); err != nil {
+			return err
+		}
+		b = Value{Type: ValueConstant, Constant: 0}
+	}
+	if a.Kind == KindFloat || b.Kind == KindFloat {
+		return genir_fcompare(r, node, a, b, rel, when, target)
+	}
+	if !when {
+		rel = relations[rel].negation
+	}
+
+	if a.Type == ValueConstant && b.Type == ValueConstant {
+		// the outcome is known now
+		if compare(rel, a.Constant, b.Constant) {
+			return genir_goto(r, target, node)
+		}
+		return nil
+	}
+
+	if a, err = materialize(r, a, node); err != nil {
+		return err
+	}
+	if b, err = materialize(r, b, node); err != nil {
+		return err
+	}
+	address, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	r.emit_jump(relations[rel].jumps, a.Register, b.Register, address, target)
+	address.Unlock()
+	a.release()
+	b.release()
+	return nil
+}
+
+// compare_strings compares the strings a and b with STRCMP, whose
+// -1, 0 or 1 compares with 0 as a does with b.
+func compare_strings(r *Routine, node *parser.Node, a, b Value) (Value, error) {
+	if a.Kind != b.Kind {
+		return Value{}, errorStringComparison(r, node, a.Kind, b.Kind)
+	}
+	result, err := writable(r, a, node)
+	if err != nil {
+		return Value{}, err
+	}
+	if b, err = materialize(r, b, node); err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.STRCMP, result.Register, b.Register)
+	b.release()
+	result.Kind = KindInt
+	return result, nil
+}
+
+// genir_fcompare is genir_compare for floats. Since a NaN
+// compares false to anything, "unless a < b" cannot become
+// "if a >= b"; it jumps around the jump to target instead.
+func genir_fcompare(r *Routine, node *parser.Node, a, b Value, rel parser.Rule, when bool, target string) error {
+	a, err := convert(r, a, KindFloat, node)
+	if err != nil {
+		return err
+	}
+	if b, err = convert(r, b, KindFloat, node); err != nil {
+		return err
+	}
+	if a.Type == ValueConstant && b.Type == ValueConstant {
+		if compare_floats(rel, a.Float, b.Float) == when {
+			return genir_goto(r, target, node)
+		}
+		return nil
+	}
+
+	if a, err = materialize(r, a, node); err != nil {
+		return err
+	}
+	if b, err = materialize(r, b, node); err != nil {
+		return err
+	}
+	address, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	if when {
+		r.emit_jump(relations[rel].fjumps, a.Register, b.Register, address, target)
+	} else {
+		skip := r.Symbol("fcmp", strconv.Itoa(r.next_block()), "skip")
+		r.emit_jump(relations[rel].fjumps, a.Register, b.Register, address, skip)
+		r.emit_jump([]vm.Operation{vm.JE}, address, address, address, target)
+		r.__IR.Add(&IRLabel{symbol: skip})
+	}
+	address.Unlock()
+	a.release()
+	b.release()
+	return nil
+}
+
+func compare(rel parser.Rule, a, b int) bool {
+	switch rel {
+	case parser.Ruletokeq:
+		return a == b
+	case parser.Ruletokne:
+		return a != b
+	case parser.Ruletoklt:
+		return a < b
+	case parser.Ruletokgt:
+		return a > b
+	case parser.Ruletokle:
+		return a <= b
+	}
+	return a >= b
+}
+
+func compare_floats(rel parser.Rule, a, b float64) bool {
+	switch rel {
+	case parser.Ruletokeq:
+		return a == b
+	case parser.Ruletokne:
+		return a != b
+	case parser.Ruletoklt:
+		return a < b
+	case parser.Ruletokgt:
+		return a > b
+	case parser.Ruletokle:
+		return a <= b
+	}
+	return a >= b
+}
+
+// genir_goto jumps to the label for symbol unconditionally.
+func genir_goto(r *Routine, symbol string, node *parser.Node) error {
+	address, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	r.emit_jump([]vm.Operation{vm.JE}, address, address, address, symbol)
+	address.Unlock()
+	return nil
+}
+
+// emit_jump loads the location of the label for symbol into
+// address and emits "OP a b address" for each of jumps.
+func (r *Routine) emit_jump(jumps []vm.Operation, a, b, address *Register, symbol string) {
+	r.emit(vm.SETL, address)
+	r.__IR.Add(&IRJump{symbol: symbol})
+	for _, op := range jumps {
+		r.emit(op, a, b, address)
+	}
+}
+
+func (r *Routine) next_block() int {
+	r.__blocks++
+	return r.__blocks
+}
+
+func (r *Routine) push_loop(block string) loop {
+	l := loop{
+		continuelabel: r.Symbol("loop", block, "continue"),
+		breaklabel:    r.Symbol("loop", block, "break"),
+	}
+	r.__loops = append(r.__loops, l)
+	return l
+}
+
+func (r *Routine) pop_loop() {
+	r.__loops = r.__loops[:len(r.__loops)-1]
+}
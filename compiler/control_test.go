@@ -0,0 +1,33 @@
+package compiler
+
+import (
+	"testing"
+)
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int64
+	}{
+		{"while", "res i, s; i = 0; s = 0; while (i < 5) { s = s + i; i = i + 1; } return s;", 10},
+		{"while never", "res i; i = 9; while (i < 5) { i = i + 1; } return i;", 9},
+		{"for", "res i, s; s = 0; for (i = 1; i <= 4; i = i + 1) { s = s * 10 + i; } return s;", 1234},
+		{"for without parts", "res i; i = 0; for (;;) { i = i + 1; if (i == 3) { break; } } return i;", 3},
+		{"break", "res i; i = 0; while (1) { if (i == 7) { break; } i = i + 1; } return i;", 7},
+		{"continue", "res i, s; s = 0; for (i = 0; i < 6; i = i + 1) { if (i % 2 == 0) { continue; } s = s + i; } return s;", 9},
+		{"continue in while", "res i, s; i = 0; s = 0; while (i < 6) { i = i + 1; if (i == 2) { continue; } s = s + i; } return s;", 19},
+		{"nested", "res i, j, n; n = 0; for (i = 0; i < 3; i = i + 1) { for (j = 0; j < 10; j = j + 1) { if (j == i) { break; } n = n + 1; } } return n;", 3},
+	}
+	for _, test := range tests {
+		if got := run(t, "routine main<> { "+test.body+" }"); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestOutsideLoop(t *testing.T) {
+	compileError(t, "routine main<> { break; return 0; }", `"break" outside of a loop at line 1.`)
+	compileError(t, "routine main<> { if (1) { continue; } return 0; }", `"continue" outside of a loop at line 1.`)
+	compileError(t, "routine main<> { while (1) { routine f<> { break; return 0; } } return 0; }", `"break" outside of a loop at line 1.`)
+}
@@ -7,12 +7,12 @@ import (
 	"strings"
 )
 
-func err_routine_already_exists(p *Program, oldr, newr *Routine) error {
+func err_routine_already_exists(m *Module, oldr, newr *Routine) error {
 	errstr := fmt.Sprintf(
 		"Error at line %d: routine \"%s\" already defined at line %d.",
-		line_no(&p.sourcecode, newr.__node.Tok.Begin()),
+		line_no(&m.sourcecode, newr.__node.Tok.Begin()),
 		newr.GetName(),
-		line_no(&p.sourcecode, oldr.__node.Tok.Begin()),
+		line_no(&m.sourcecode, oldr.__node.Tok.Begin()),
 	)
 	return errors.New(errstr)
 }
@@ -21,10 +21,10 @@ func newError(reasons ...interface{}) error {
 	return errors.New(fmt.Sprint(reasons...))
 }
 
-func errorExpectingOneOf(tok parser.State16, src *string, expected []parser.Rule) error {
+func errorExpectingOneOf(tok parser.State32, src *string, expected []parser.Rule) error {
 	expected_str := make([]string, 0, len(expected))
-	for i, expect := range expected {
-		expected_str[i] = parser.Rul3s[expect]
+	for _, expect := range expected {
+		expected_str = append(expected_str, parser.Rul3s[expect])
 	}
 	return newError(
 		"Unexpected ",
@@ -42,7 +42,665 @@ func errorCannotReserveRegister(r *Routine, variable string, node *parser.Node)
 		"Cannot reserve an unused register for use by variable \"",
 		variable,
 		"\" at line ",
-		line_no(&r.__program.sourcecode, node.Tok.Begin()),
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
 		"! (Try splitting program into more functions.)",
 	)
 }
+
+func errorCannotReserveTemporary(r *Routine, node *parser.Node) error {
+	return newError(
+		"Cannot reserve an unused register for an intermediate value at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		"! (Try splitting the expression into smaller ones.)",
+	)
+}
+
+func errorUndeclaredVariable(r *Routine, node *parser.Node) error {
+	return newError(
+		"Variable \"",
+		node.Source(),
+		"\" used at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" before it was reserved. (Declare it with res.)",
+	)
+}
+
+func errorUnknownRoutine(r *Routine, name string, node *parser.Node) error {
+	return newError(
+		"Call to undefined routine \"",
+		name,
+		"\" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		".",
+	)
+}
+
+func errorArgumentCount(r *Routine, name string, takes, given int, node *parser.Node) error {
+	return newError(
+		"Routine \"",
+		name,
+		"\" takes ",
+		takes,
+		" arguments but is called with ",
+		given,
+		" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		".",
+	)
+}
+
+func errorDivisionByZero(r *Routine, node *parser.Node) error {
+	return newError(
+		"Division by zero at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		".",
+	)
+}
+
+func errorNumberOutOfRange(r *Routine, node *parser.Node) error {
+	return newError(
+		"Number ",
+		node.Source(),
+		" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" is out of range.",
+	)
+}
+
+func errorConstantOutOfRange(r *Routine, c int, node *parser.Node) error {
+	return newError(
+		"Constant ",
+		c,
+		" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" does not fit in 32 bits.",
+	)
+}
+
+func errorNoMain() error {
+	return newError("Program has no routine \"main\".")
+}
+
+func errorMainArguments(m *Module, main *Routine) error {
+	return newError(
+		"Routine \"main\" at line ",
+		line_no(&m.sourcecode, main.__node.Tok.Begin()),
+		" must take no arguments and return one int.",
+	)
+}
+
+func errorOutsideLoop(r *Routine, node *parser.Node) error {
+	return newError(
+		"\"",
+		node.Source(),
+		"\" outside of a loop at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		".",
+	)
+}
+
+func errorLabelAlreadyExists(r *Routine, name, previous *parser.Node) error {
+	return newError(
+		"Error at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		": label \"",
+		name.Source(),
+		"\" already defined at line ",
+		line_no(&r.__module.sourcecode, previous.Tok.Begin()),
+		".",
+	)
+}
+
+func errorUndefinedLabel(r *Routine, name *parser.Node) error {
+	return newError(
+		"Jump to undefined label \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		".",
+	)
+}
+
+func errorSymbolAlreadyExists(m *Module, name, previous *parser.Node) error {
+	return newError(
+		"Error at line ",
+		line_no(&m.sourcecode, name.Tok.Begin()),
+		": \"",
+		name.Source(),
+		"\" already declared at line ",
+		line_no(&m.sourcecode, previous.Tok.Begin()),
+		".",
+	)
+}
+
+func errorNotConstant(m *Module, name *parser.Node) error {
+	return newError(
+		"The value of constant \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&m.sourcecode, name.Tok.Begin()),
+		" is not known when compiling.",
+	)
+}
+
+func errorUndefinedConstant(m *Module, name, variable *parser.Node) error {
+	return newError(
+		"Undefined constant \"",
+		variable.Source(),
+		"\" in the value of constant \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&m.sourcecode, variable.Tok.Begin()),
+		".",
+	)
+}
+
+func errorConstantDeclaredLater(m *Module, name, variable, later *parser.Node) error {
+	return newError(
+		"Undefined constant \"",
+		variable.Source(),
+		"\" in the value of constant \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&m.sourcecode, variable.Tok.Begin()),
+		". (It is declared at line ",
+		line_no(&m.sourcecode, later.Tok.Begin()),
+		"; constants may only use the constants declared before them.)",
+	)
+}
+
+func errorAssignmentToConstant(r *Routine, name *parser.Node) error {
+	return newError(
+		"Cannot assign to constant \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		".",
+	)
+}
+
+func errorArrayLength(r *Routine, name *parser.Node) error {
+	return newError(
+		"The length of array \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		" must be a positive constant that fits in 32 bits.",
+	)
+}
+
+func errorNotAnArray(r *Routine, name *parser.Node) error {
+	return newError(
+		"\"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		" is indexed but is not an array.",
+	)
+}
+
+func errorArrayAsValue(r *Routine, name *parser.Node) error {
+	return newError(
+		"Array \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		" is used without an index.",
+	)
+}
+
+func errorIndexOutOfRange(r *Routine, node *parser.Node, index, length int) error {
+	return newError(
+		"Index ",
+		index,
+		" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" is out of range for an array of length ",
+		length,
+		".",
+	)
+}
+
+func errorBuiltinRedefined(m *Module, rout *Routine) error {
+	return newError(
+		"Error at line ",
+		line_no(&m.sourcecode, rout.__node.Tok.Begin()),
+		": routine \"",
+		rout.GetName(),
+		"\" has the name of a builtin routine.",
+	)
+}
+
+func errorFloatOperand(r *Routine, operator *parser.Node) error {
+	return newError(
+		"Operator ",
+		operator.Source(),
+		" at line ",
+		line_no(&r.__module.sourcecode, operator.Tok.Begin()),
+		" cannot be applied to a float.",
+	)
+}
+
+func errorFloatAsInt(r *Routine, node *parser.Node) error {
+	return newError(
+		"A float is used as an int at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		". (Convert it with int().)",
+	)
+}
+
+func errorFloatConversion(r *Routine, f float64, node *parser.Node) error {
+	return newError(
+		"Constant ",
+		f,
+		" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" cannot be converted to an int.",
+	)
+}
+
+func errorTypeMismatch(r *Routine, node *parser.Node, given Kind, declared *parser.Node) error {
+	hint := ""
+	if given == KindFloat && declared.Source() == "int" {
+		hint = " (Convert it with int().)"
+	}
+	return newError(
+		"Type mismatch at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		", column ",
+		column_no(&r.__module.sourcecode, node.Tok.Begin()),
+		": \"",
+		node.Source(),
+		"\" is ",
+		article(given),
+		" but ",
+		declared.Source(),
+		" is declared at line ",
+		line_no(&r.__module.sourcecode, declared.Tok.Begin()),
+		".",
+		hint,
+	)
+}
+
+func errorKindMismatch(r *Routine, node *parser.Node, given, expected Kind) error {
+	return newError(
+		"Type mismatch at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		", column ",
+		column_no(&r.__module.sourcecode, node.Tok.Begin()),
+		": \"",
+		node.Source(),
+		"\" is ",
+		article(given),
+		" where ",
+		article(expected),
+		" is expected.",
+	)
+}
+
+func errorStringOperand(r *Routine, operator *parser.Node) error {
+	return newError(
+		"Operator ",
+		operator.Source(),
+		" at line ",
+		line_no(&r.__module.sourcecode, operator.Tok.Begin()),
+		" cannot be applied to a string.",
+	)
+}
+
+func errorStringComparison(r *Routine, node *parser.Node, a, b Kind) error {
+	return newError(
+		"Cannot compare ",
+		article(a),
+		" with ",
+		article(b),
+		" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		".",
+	)
+}
+
+// article returns the name of k with "a" or "an".
+func article(k Kind) string {
+	if k == KindInt {
+		return "an int"
+	}
+	return "a " + k.String()
+}
+
+func errorAnnotationConflict(r *Routine, name, declared, previous *parser.Node) error {
+	return newError(
+		"Error at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		": \"",
+		name.Source(),
+		"\" is declared ",
+		declared.Source(),
+		" but was declared ",
+		previous.Source(),
+		" at line ",
+		line_no(&r.__module.sourcecode, previous.Tok.Begin()),
+		".",
+	)
+}
+
+func errorUnknownType(m *Module, typename *parser.Node) error {
+	return newError(
+		"Unknown type \"",
+		typename.Source(),
+		"\" at line ",
+		line_no(&m.sourcecode, typename.Tok.Begin()),
+		". (Types are int, float, string and the structs declared.)",
+	)
+}
+
+func errorFieldType(m *Module, field *parser.Node) error {
+	return newError(
+		"Field \"",
+		field.Source(),
+		"\" at line ",
+		line_no(&m.sourcecode, field.Tok.Begin()),
+		" must be an int, a float or a string.",
+	)
+}
+
+func errorArrayOfStructs(r *Routine, name *parser.Node) error {
+	return newError(
+		"Array \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		" must hold ints, floats or strings.",
+	)
+}
+
+func errorNotAStruct(r *Routine, name *parser.Node) error {
+	return newError(
+		"\"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		" is not a struct and has no fields.",
+	)
+}
+
+func errorUnknownField(r *Routine, node *parser.Node, s *Struct) error {
+	return newError(
+		"Struct ",
+		s.name,
+		" has no field \"",
+		node.Child(parser.Rulefieldname).Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		".",
+	)
+}
+
+func errorStructAsValue(r *Routine, node *parser.Node) error {
+	return newError(
+		"Struct \"",
+		node.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" is used as a value. (Use its fields, or assign it to a struct.)",
+	)
+}
+
+func errorNotStruct(r *Routine, node *parser.Node, s *Struct) error {
+	return newError(
+		"Type mismatch at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		", column ",
+		column_no(&r.__module.sourcecode, node.Tok.Begin()),
+		": \"",
+		node.Source(),
+		"\" is not a ",
+		s.name,
+		". (Structs are given by variables and calls.)",
+	)
+}
+
+func errorStructResults(r *Routine, typename *parser.Node) error {
+	return newError(
+		"Routine \"",
+		r.GetName(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, typename.Tok.Begin()),
+		" returns a struct along with other values. (A struct is returned alone.)",
+	)
+}
+
+func errorReturnCount(r *Routine, node *parser.Node, returns, given int) error {
+	return newError(
+		"Return at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" gives ",
+		values(given),
+		" but routine \"",
+		r.GetName(),
+		"\" returns ",
+		returns,
+		".",
+	)
+}
+
+func errorResultCount(r *Routine, node *parser.Node, name string, returns, expected int) error {
+	return newError(
+		"Expected ",
+		values(expected),
+		" at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" but \"",
+		name,
+		"\" gives ",
+		returns,
+		".",
+	)
+}
+
+// values is "1 value" or "n values".
+func values(n int) string {
+	if n == 1 {
+		return "1 value"
+	}
+	return fmt.Sprint(n, " values")
+}
+
+func errorNotReferable(r *Routine, node *parser.Node, name string) error {
+	return newError(
+		"Routine \"",
+		name,
+		"\" referred to at line ",
+		line_no(&r.__module.sourcecode, node.Tok.Begin()),
+		" must take ints and return one int to be called through a reference.",
+	)
+}
+
+func errorNestedRoutine(r *Routine, name *parser.Node) error {
+	return newError(
+		"Nested routine \"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		" must take ints and return one int.",
+	)
+}
+
+func errorCapturedAggregate(r *Routine, name *parser.Node) error {
+	return newError(
+		"\"",
+		name.Source(),
+		"\" at line ",
+		line_no(&r.__module.sourcecode, name.Tok.Begin()),
+		" is an array or a struct and cannot be captured by a nested routine.",
+	)
+}
+
+func errorImportNotFound(m *Module, node *parser.Node, file string) error {
+	return newError(
+		"Cannot find module \"",
+		file,
+		"\" imported at line ",
+		line_no(&m.sourcecode, node.Tok.Begin()),
+		".",
+	)
+}
+
+func errorImportsDisabled(m *Module, node *parser.Node) error {
+	return newError(
+		"Cannot import at line ",
+		line_no(&m.sourcecode, node.Tok.Begin()),
+		": imports are disabled.",
+	)
+}
+
+func errorImportUnreadable(m *Module, node *parser.Node, err error) error {
+	return newError(
+		"Cannot read module imported at line ",
+		line_no(&m.sourcecode, node.Tok.Begin()),
+		": ",
+		err,
+	)
+}
+
+func errorImportSyntax(m *Module, node *parser.Node, file string) error {
+	return newError(
+		"Module \"",
+		file,
+		"\" imported at line ",
+		line_no(&m.sourcecode, node.Tok.Begin()),
+		" has syntax errors.",
+	)
+}
+
+func errorModuleAlreadyImported(m *Module, node *parser.Node, name string, previous *parser.Node) error {
+	return newError(
+		"Error at line ",
+		line_no(&m.sourcecode, node.Tok.Begin()),
+		": module \"",
+		name,
+		"\" already imported at line ",
+		line_no(&m.sourcecode, previous.Tok.Begin()),
+		". (Import one of them with \"as\" and another name.)",
+	)
+}
+
+func errorImportCycle(cycle []*Module) error {
+	files := make([]string, len(cycle))
+	for i, m := range cycle {
+		files[i] = m.file
+	}
+	return newError("Import cycle: ", strings.Join(files, " imports "), ".")
+}
+
+func errorModuleName(m *Module, node *parser.Node, name string) error {
+	return newError(
+		"Module imported at line ",
+		line_no(&m.sourcecode, node.Tok.Begin()),
+		" is called \"",
+		name,
+		"\", which is not a name. (Import it with \"as\" and a name.)",
+	)
+}
+
+func errorNoObjects() error {
+	return newError("Nothing to link.")
+}
+
+func errorBadObject(err error) error {
+	return newError("Not an object: ", err, ".")
+}
+
+func errorObjectFormat(module string, format int) error {
+	return newError(
+		"Object of module \"",
+		module,
+		"\" has format ",
+		format,
+		", not ",
+		objectFormat,
+		". (Compile it again.)",
+	)
+}
+
+func errorBadFixup(o *Object, offset int) error {
+	return newError("Object of module \"", o.Module, "\" is corrupt at offset ", offset, ".")
+}
+
+func errorDuplicateSymbol(name string, previous, o *Object) error {
+	return newError(
+		"Symbol \"",
+		name,
+		"\" defined by the objects of modules \"",
+		previous.Module,
+		"\" and \"",
+		o.Module,
+		"\".",
+	)
+}
+
+func errorUndefinedSymbol(name string, o *Object) error {
+	return newError(
+		"Symbol \"",
+		name,
+		"\" used by the object of module \"",
+		o.Module,
+		"\" is not defined.",
+	)
+}
+
+func errorSignatureMismatch(name string, o *Object, expected, defined string) error {
+	return newError(
+		"Symbol \"",
+		name,
+		"\" is called as \"",
+		expected,
+		"\" by the object of module \"",
+		o.Module,
+		"\" but defined as \"",
+		defined,
+		"\".",
+	)
+}
+
+func errorUnknownLabel(symbol string) error {
+	return newError("No routine is labelled ", symbol, ".")
+}
+
+func errorNoMainSymbol(o *Object) error {
+	return newError(
+		"The object of module \"",
+		o.Module,
+		"\", which is linked first, has no routine \"main\".",
+	)
+}
+
+func errorMainSignature(main Symbol) error {
+	return newError(
+		"Symbol \"",
+		main.Name,
+		"\" is defined as \"",
+		main.Signature,
+		"\", but main must take no arguments and return one int.",
+	)
+}
+
+func errorExternBuiltin(m *Module, name *parser.Node) error {
+	return newError(
+		"Error at line ",
+		line_no(&m.sourcecode, name.Tok.Begin()),
+		": extern routine \"",
+		name.Source(),
+		"\" has the name of a builtin routine.",
+	)
+}
+
+func errorExternType(m *Module, declared *parser.Node) error {
+	return newError(
+		"Error at line ",
+		line_no(&m.sourcecode, declared.Tok.Begin()),
+		": the arguments of extern routines are ints, not \"",
+		declared.Source(),
+		"\".",
+	)
+}
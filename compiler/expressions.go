@@ -0,0 +1,510 @@
+package compiler
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+type binaryOperator struct {
+	immediate vm.Operation // OP %reg imm8
+	register  vm.Operation // OP %reg %reg
+	fold      func(a, b int) int
+
+	// operators on ints only have no float forms
+	float vm.Operation // FOP %reg %reg
+	ffold func(a, b float64) float64
+}
+
+var binaryOperators = map[parser.Rule]binaryOperator{
+	parser.Ruletokadd: {vm.ADD, vm.ADDREG, func(a, b int) int { return a + b },
+		vm.FADD, func(a, b float64) float64 { return a + b }},
+	parser.Ruletoksub: {vm.SUB, vm.SUBREG, func(a, b int) int { return a - b },
+		vm.FSUB, func(a, b float64) float64 { return a - b }},
+	parser.Ruletokmul: {vm.MUL, vm.MULREG, func(a, b int) int { return a * b },
+		vm.FMUL, func(a, b float64) float64 { return a * b }},
+	parser.Ruletokdiv: {vm.DIV, vm.DIVREG, func(a, b int) int { return a / b },
+		vm.FDIV, func(a, b float64) float64 { return a / b }},
+	parser.Ruletokmod: {immediate: vm.MOD, register: vm.MODREG, fold: func(a, b int) int { return a % b }},
+
+	parser.Ruletokbitand: {immediate: vm.AND, register: vm.ANDREG, fold: func(a, b int) int { return a & b }},
+	parser.Ruletokbitor:  {immediate: vm.OR, register: vm.ORREG, fold: func(a, b int) int { return a | b }},
+	parser.Ruletokxor:    {immediate: vm.XOR, register: vm.XORREG, fold: func(a, b int) int { return a ^ b }},
+	// the VM shifts by the low six bits of the count
+	parser.Ruletokshl: {immediate: vm.SHL, register: vm.SHLREG, fold: func(a, b int) int { return a << uint(b&63) }},
+	parser.Ruletokshr: {immediate: vm.SHR, register: vm.SHRREG, fold: func(a, b int) int { return a >> uint(b&63) }},
+}
+
+// genir_expr evaluates an expression. Constant subexpressions
+// are folded into a ValueConstant; everything else is computed
+// into temporary registers. The caller releases the result once
+// it has been used.
+func genir_expr(r *Routine, node *parser.Node) (Value, error) {
+	switch node.Tok.Rule {
+	case parser.Ruleexpr, parser.Ruleconjunction, parser.Rulecomparison:
+		if operands := operands(node); len(operands) == 1 {
+			return genir_expr(r, operands[0])
+		}
+		return genir_boolean(r, node)
+	case parser.Rulesum, parser.Ruleterm:
+		return genir_binary(r, node)
+	case parser.Ruleunary:
+		if node.Child(parser.Ruletoknot) != nil {
+			return genir_boolean(r, node)
+		}
+		return genir_unary(r, node)
+	case parser.Ruleprimary:
+		if inner := node.Child(parser.Ruleexpr); inner != nil {
+			return genir_expr(r, inner)
+		}
+		return genir_expr(r, node.Child(parser.Rulevalue))
+	case parser.Rulevalue:
+		return genir_expr(r, node.Children[0])
+	case parser.Rulenumber:
+		return genir_number(r, node)
+	case parser.Rulefloat:
+		return genir_float(r, node)
+	case parser.Rulevariable:
+		return genir_variable(r, node)
+	case parser.Ruleelement:
+		return genir_element(r, node)
+	case parser.Rulemember:
+		return genir_member(r, node)
+	case parser.Rulestring:
+		return genir_string(r, node)
+	case parser.Rulefunccall:
+		return genir_funccall(r, node)
+	case parser.Rulereference:
+		return genir_reference(r, node)
+	}
+	return Value{}, errorExpectingOneOf(node.Tok, &r.__module.sourcecode, []parser.Rule{
+		parser.Ruleexpr, parser.Ruleconjunction, parser.Rulecomparison,
+		parser.Rulesum, parser.Ruleterm, parser.Ruleunary, parser.Ruleprimary,
+		parser.Rulevalue, parser.Rulenumber, parser.Rulefloat, parser.Rulestring,
+		parser.Rulevariable, parser.Ruleelement, parser.Rulemember, parser.Rulefunccall,
+		parser.Rulereference,
+	})
+}
+
+// operands returns the children of a node of an expression
+// that are not operators.
+func operands(node *parser.Node) parser.NodeArray {
+	operands := make(parser.NodeArray, 0, len(node.Children))
+	for _, child := range node.Children {
+		switch child.Tok.Rule {
+		case parser.Ruletokor, parser.Ruletokand, parser.Rulecomparisontoken:
+			continue
+		}
+		if _, ok := binaryOperators[child.Tok.Rule]; !ok {
+			operands = append(operands, child)
+		}
+	}
+	return operands
+}
+
+// genir_binary folds the operands of a sum or term from left
+// to right: a - b + c is (a - b) + c.
+func genir_binary(r *Routine, node *parser.Node) (Value, error) {
+	var result Value
+	var operator *parser.Node
+	first := true
+	for _, child := range node.Children {
+		if _, ok := binaryOperators[child.Tok.Rule]; ok {
+			operator = child
+			continue
+		}
+		operand, err := genir_expr(r, child)
+		if err != nil {
+			return Value{}, err
+		}
+		if first {
+			result, first = operand, false
+			continue
+		}
+		if result, err = genir_operator(r, operator, result, operand); err != nil {
+			return Value{}, err
+		}
+	}
+	return result, nil
+}
+
+// genir_operator applies a binary operator to a and b. The
+// result is computed in a's register when a is a temporary.
+func genir_operator(r *Routine, node *parser.Node, a, b Value) (Value, error) {
+	operator := binaryOperators[node.Tok.Rule]
+	if a.Kind == KindString || b.Kind == KindString {
+		return Value{}, errorStringOperand(r, node)
+	}
+	if a.Kind == KindFloat || b.Kind == KindFloat {
+		return genir_foperator(r, node, operator, a, b)
+	}
+	divides := node.Tok.Rule == parser.Ruletokdiv || node.Tok.Rule == parser.Ruletokmod
+	if divides && b.Type == ValueConstant && b.Constant == 0 {
+		return Value{}, errorDivisionByZero(r, node)
+	}
+	if a.Type == ValueConstant && b.Type == ValueConstant {
+		return Value{Type: ValueConstant, Constant: operator.fold(a.Constant, b.Constant)}, nil
+	}
+
+	result, err := writable(r, a, node)
+	if err != nil {
+		return Value{}, err
+	}
+	if b.immediate() {
+		r.emit(operator.immediate, result.Register, byte(b.Constant))
+		return result, nil
+	}
+	if b, err = materialize(r, b, node); err != nil {
+		return Value{}, err
+	}
+	r.emit(operator.register, result.Register, b.Register)
+	b.release()
+	return result, nil
+}
+
+// genir_unary negates its operand with - and inverts its bits
+// with ~.
+func genir_unary(r *Routine, node *parser.Node) (Value, error) {
+	negates := node.Child(parser.Ruletoksub) != nil
+	if !negates && node.Child(parser.Ruletokbitnot) == nil {
+		return genir_expr(r, node.Child(parser.Ruleprimary))
+	}
+	operand, err := genir_expr(r, node.Child(parser.Ruleunary))
+	if err != nil {
+		return Value{}, err
+	}
+	if operand.Kind == KindString {
+		return Value{}, errorStringOperand(r, node.Children[0])
+	}
+	if operand.Kind == KindFloat {
+		if !negates {
+			return Value{}, errorFloatOperand(r, node.Children[0])
+		}
+		return genir_fnegate(r, operand, node)
+	}
+	if operand.Type == ValueConstant {
+		if negates {
+			operand.Constant = -operand.Constant
+		} else {
+			operand.Constant = ^operand.Constant
+		}
+		return operand, nil
+	}
+	result, err := writable(r, operand, node)
+	if err != nil {
+		return Value{}, err
+	}
+	if negates {
+		r.emit(vm.NEG, result.Register)
+	} else {
+		r.emit(vm.NOT, result.Register)
+	}
+	return result, nil
+}
+
+// genir_number parses a decimal, hexadecimal (0x) or binary (0b)
+// literal, which may separate its digits with underscores.
+// Constants are computed with 64 bits and only need to fit in 32
+// once they are loaded; see load_constant.
+func genir_number(r *Routine, node *parser.Node) (Value, error) {
+	number, err := strconv.ParseInt(node.Source(), 0, 64)
+	if err != nil {
+		return Value{}, errorNumberOutOfRange(r, node)
+	}
+	return Value{Type: ValueConstant, Constant: int(number)}, nil
+}
+
+// genir_variable looks a name up in the variables reserved so
+// far, then in the constants and globals of the program.
+func genir_variable(r *Routine, node *parser.Node) (Value, error) {
+	if variable, ok := r.local(node.Source()); ok {
+		if variable.length > 0 {
+			return Value{}, errorArrayAsValue(r, node)
+		}
+		if variable.structure != nil {
+			return Value{}, errorStructAsValue(r, node)
+		}
+		return load_variable(r, variable, node)
+	}
+	switch symbol := r.__module.symbols.Get(node.Source()).(type) {
+	case *Constant:
+		return symbol.value, nil
+	case *Global:
+		return load_global(r, symbol, node)
+	}
+	return Value{}, errorUndeclaredVariable(r, node)
+}
+
+// genir_funccall calls a routine, builtin or conversion and
+// returns its result in a temporary. Calling a variable calls
+// the routine it refers to; variables hide routines of the same
+// name, as nested routines are variables.
+func genir_funccall(r *Routine, node *parser.Node) (Value, error) {
+	identifier := node.Child(parser.Rulefuncidentifier)
+	name := identifier.Source()
+	argnodes := call_arguments(node)
+	if kind, ok := conversions[name]; ok {
+		return genir_conversion(r, name, kind, argnodes, node)
+	}
+	if b, ok := builtins[name]; ok {
+		return genir_builtin(r, name, b, argnodes, node)
+	}
+
+	target, ok := r.lookup_routine(name)
+	if _, local := r.local(name); local || (!ok && r.__module.symbols.Exists(name)) {
+		return genir_indirect_call(r, node, identifier.Child(parser.Rulevariable), argnodes)
+	}
+	if e, extern := r.lookup_extern(name); extern && !ok {
+		return genir_host(r, e, argnodes, node)
+	}
+	if !ok {
+		return Value{}, errorUnknownRoutine(r, name, node)
+	}
+	if target.returned != nil {
+		return Value{}, errorStructAsValue(r, node)
+	}
+	if len(target.returns) != 1 {
+		return Value{}, errorResultCount(r, node, name, len(target.returns), 1)
+	}
+	results, err := genir_call(r, node, target, nil)
+	if err != nil {
+		return Value{}, err
+	}
+	return results[0], nil
+}
+
+// call_arguments returns the argument expressions of a call.
+func call_arguments(node *parser.Node) parser.NodeArray {
+	argnodes := parser.NodeArray{}
+	if params := node.Child(parser.Rulecallparams); params != nil {
+		for _, child := range params.Children {
+			if child.Tok.Rule == parser.Ruleexpr {
+				argnodes = append(argnodes, child)
+			}
+		}
+	}
+	return argnodes
+}
+
+// genir_call calls target and returns its results in
+// temporaries. A routine that returns a struct is passed the
+// address in into to return it at.
+func genir_call(r *Routine, node *parser.Node, target *Routine, into *Value) ([]Value, error) {
+	name := target.GetName()
+	argnodes := call_arguments(node)
+	if len(argnodes) != len(target.args) {
+		return nil, errorArgumentCount(r, name, len(target.args), len(argnodes), node)
+	}
+
+	args := make([]Value, 0, 1+len(argnodes))
+	if into != nil {
+		args = append(args, *into)
+	}
+	for i, argnode := range argnodes {
+		param := target.vmap._map[target.args[i]]
+		if param.structure != nil {
+			arg, err := struct_argument(r, param.structure, argnode)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			continue
+		}
+		arg, err := genir_argument(r, argnode, param.kind)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return emit_call(r, node, &IRFuncCall{target: target}, args, target.returns)
+}
+
+// genir_reference returns the address that &name calls the
+// routine at. Arguments and results are not checked by indirect
+// calls, so only routines that take and return ints can be
+// referenced.
+func genir_reference(r *Routine, node *parser.Node) (Value, error) {
+	name := node.Child(parser.Rulefuncidentifier).Source()
+	target, ok := r.lookup_routine(name)
+	if !ok {
+		return Value{}, errorUnknownRoutine(r, name, node)
+	}
+	if !referable(target) {
+		return Value{}, errorNotReferable(r, node, name)
+	}
+
+	reg, err := r.temporary(node)
+	if err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.SETL, reg)
+	r.__IR.Add(&IRJump{symbol: target.Symbol("entry")})
+	return Value{Type: ValueTemporary, Register: reg}, nil
+}
+
+// referable reports whether target takes ints and returns one
+// int, and so can be called through a reference.
+func referable(target *Routine) bool {
+	ok := len(target.returns) == 1 && target.returns[0] == KindInt && target.returned == nil
+	for _, argname := range target.args {
+		param := target.vmap._map[argname]
+		ok = ok && param.kind == KindInt && param.structure == nil
+	}
+	return ok
+}
+
+// genir_indirect_call calls the routine that the variable name
+// refers to with argnodes. The routine checks the number of
+// arguments when it is entered; see generate_ir_head.
+func genir_indirect_call(r *Routine, node, name *parser.Node, argnodes parser.NodeArray) (Value, error) {
+	callee, err := genir_variable(r, name)
+	if err != nil {
+		return Value{}, err
+	}
+	if callee, err = convert(r, callee, KindInt, name); err != nil {
+		return Value{}, err
+	}
+	if callee, err = materialize(r, callee, name); err != nil {
+		return Value{}, err
+	}
+
+	args := make([]Value, len(argnodes))
+	for i, argnode := range argnodes {
+		if args[i], err = genir_argument(r, argnode, KindInt); err != nil {
+			return Value{}, err
+		}
+	}
+	results, err := emit_call(r, node, &IRFuncCall{via: callee.Register}, args, []Kind{KindInt})
+	if err != nil {
+		return Value{}, err
+	}
+	callee.release()
+	return results[0], nil
+}
+
+// genir_argument evaluates an argument of kind for a call.
+func genir_argument(r *Routine, node *parser.Node, kind Kind) (Value, error) {
+	arg, err := genir_expr(r, node)
+	if err != nil {
+		return Value{}, err
+	}
+	if arg, err = convert(r, arg, kind, node); err != nil {
+		return Value{}, err
+	}
+	if arg.Type == ValueConstant && !arg.immediate() {
+		// STPS only pushes an imm8
+		return materialize(r, arg, node)
+	}
+	return arg, nil
+}
+
+// emit_call completes call with args and adds it to the routine,
+// returning the results, of kinds returns, in temporaries. Every
+// register in use is saved around the call, except for the
+// temporaries holding the arguments, which the call consumes.
+func emit_call(r *Routine, node *parser.Node, call *IRFuncCall, args []Value, returns []Kind) ([]Value, error) {
+	saved := make([]*Register, 0, vm.NUM_REGS)
+	for _, reg := range r.registers.RegistersInUse() {
+		consumed := false
+		for _, arg := range args {
+			consumed = consumed || (arg.Type == ValueTemporary && arg.Register == reg)
+		}
+		if !consumed {
+			saved = append(saved, reg)
+		}
+	}
+	results := make([]*Register, len(returns))
+	values := make([]Value, len(returns))
+	for i, kind := range returns {
+		result, err := r.temporary(node)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+		values[i] = Value{Type: ValueTemporary, Kind: kind, Register: result}
+	}
+	for _, arg := range args {
+		arg.release()
+	}
+
+	call.returnreg, call.results = results[0], results[1:]
+	call.args, call.saved = args, saved
+	call.caller, call.calltok = r, node
+	r.__IR.Add(call)
+	return values, nil
+}
+
+// store copies v into the register dst.
+func store(r *Routine, dst *Register, v Value, node *parser.Node) error {
+	switch {
+	case v.Type == ValueConstant && v.Kind == KindFloat:
+		load_float(r, dst, v.Float)
+	case v.Type == ValueConstant && v.Handle:
+		load_string(r, dst, v.Constant)
+	case v.Type == ValueConstant:
+		return load_constant(r, dst, v.Constant, node)
+	case v.Register != dst:
+		r.emit(vm.MOV, dst, v.Register)
+	}
+	return nil
+}
+
+// load_constant sets dst to c, in a single byte if it fits and
+// in four otherwise. SETL cannot load constants that do not fit
+// in 32 bits.
+func load_constant(r *Routine, dst *Register, c int, node *parser.Node) error {
+	if fitsImmediate(c) {
+		r.emit(vm.SET, dst, byte(c))
+		return nil
+	}
+	if c < math.MinInt32 || c > math.MaxInt32 {
+		return errorConstantOutOfRange(r, c, node)
+	}
+	code := make([]byte, 0, 1+1+4)
+	byteadd(&code, vm.SETL, dst)
+	setL(&code, c)
+	r.__IR.Add(&IRLiteral{code: code})
+	return nil
+}
+
+// materialize returns v in a register, loading constants into a
+// temporary.
+func materialize(r *Routine, v Value, node *parser.Node) (Value, error) {
+	if v.Type != ValueConstant {
+		return v, nil
+	}
+	reg, err := r.temporary(node)
+	if err != nil {
+		return Value{}, err
+	}
+	if err := store(r, reg, v, node); err != nil {
+		reg.Unlock()
+		return Value{}, err
+	}
+	return Value{Type: ValueTemporary, Kind: v.Kind, Register: reg}, nil
+}
+
+// writable returns v in a temporary that may be overwritten,
+// copying it out of a variable's register if need be.
+func writable(r *Routine, v Value, node *parser.Node) (Value, error) {
+	if v.Type == ValueTemporary {
+		return v, nil
+	}
+	reg, err := r.temporary(node)
+	if err != nil {
+		return Value{}, err
+	}
+	if err := store(r, reg, v, node); err != nil {
+		reg.Unlock()
+		return Value{}, err
+	}
+	return Value{Type: ValueTemporary, Kind: v.Kind, Register: reg}, nil
+}
+
+// fitsImmediate reports whether c fits the signed imm8 operand
+// of SET, STPS and the arithmetic instructions.
+func fitsImmediate(c int) bool {
+	return c >= -128 && c <= 127
+}
@@ -0,0 +1,184 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/hfern/min/vm"
+)
+
+// program returns a program whose main returns expr.
+func program(expr string) string {
+	return "routine main<> { return " + expr + "; }"
+}
+
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"2 * 3 + 4 * 5", 26},
+		{"10 - 4 - 3", 3},
+		{"100 / 10 / 5", 2},
+		{"10 - (4 - 3)", 9},
+		{"2 * (3 + (4 - 1) * 2)", 18},
+		{"-2 * 3", -6},
+		{"-(2 + 3) * 2", -10},
+		{"- -4", 4},
+		{"7 - -2", 9},
+		{"1 + 2 < 4", 1},
+		{"2 * 3 == 6", 1},
+		{"((((1))))", 1},
+	}
+	for _, test := range tests {
+		if got := run(t, program(test.expr)); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.expr, got, test.want)
+		}
+	}
+	// comparisons do not chain
+	if _, err := compile(program("1 < 2 < 3"), ""); err == nil {
+		t.Error("1 < 2 < 3 compiles")
+	}
+}
+
+func TestLogic(t *testing.T) {
+	// yes(n) and no(n) record n in the digits of G and return 1
+	// and 0
+	const marks = "global G; routine yes<n> { G = G * 10 + n; return 1; } " +
+		"routine no<n> { G = G * 10 + n; return 0; } "
+	tests := []struct {
+		cond string
+		want int64 // the marks, then 1 if cond holds
+	}{
+		{"yes(1) && yes(2)", 121},
+		{"no(1) && yes(2)", 10},
+		{"yes(1) || yes(2)", 11},
+		{"no(1) || yes(2)", 121},
+		{"no(1) || no(2) && yes(3)", 120},
+		{"yes(1) && no(2) || yes(3)", 1231},
+		{"!no(1) && yes(4)", 141},
+		{"!(yes(1) || yes(2))", 10},
+		{"yes(2) > 0 && no(3) > 0", 230},
+	}
+	for _, test := range tests {
+		src := marks + "routine main<> { res r; r = 0; if (" + test.cond + ") { r = 1; } return G * 10 + r; }"
+		if got := run(t, src); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.cond, got, test.want)
+		}
+	}
+
+	values := []struct {
+		expr string
+		want int64
+	}{
+		{"3 && 4", 1},
+		{"0 || 5", 1},
+		{"0 || 0", 0},
+		{"!7", 0},
+		{"!0", 1},
+		{"1 < 2 && 2 < 3", 1},
+	}
+	for _, test := range values {
+		if got := run(t, program(test.expr)); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int64
+	}{
+		{"17 % 5", 2},
+		{"-17 % 5", -2},
+		{"12 & 10", 8},
+		{"12 | 10", 14},
+		{"12 ^ 10", 6},
+		{"~6", -7},
+		{"3 << 4", 48},
+		{"48 >> 4", 3},
+		{"-16 >> 2", -4},
+		{"1 + 2 << 3", 17}, // << binds like *
+		{"6 & 3 | 8", 10},  // & binds like *, | like +
+		{"1 | 2 ^ 3", 0},   // | and ^ bind alike, to the left
+		{"2 * 7 % 4", 2},
+		{"1 << 40 >> 38", 4},
+	}
+	for _, test := range tests {
+		if got := run(t, program(test.expr)); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.expr, got, test.want)
+		}
+	}
+	if code := trap(t, "routine main<> { res z; z = 0; return 5 % z; }"); code != vm.ERRDIVZERO {
+		t.Errorf("5 %% 0 gives code %d, want %d", code, vm.ERRDIVZERO)
+	}
+}
+
+func TestLiterals(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int64
+	}{
+		{"0", 0},
+		{"-0", 0},
+		{"0x1f", 31},
+		{"0XFF", 255},
+		{"0b101", 5},
+		{"0B1111_0000", 240},
+		{"1_000_000", 1000000},
+		{"0xdead_beef & 0xff", 0xef},
+		{"2147483647", 2147483647},
+		{"-2147483648", -2147483648},
+		{"(2147483647 + 1) / 2", 1073741824}, // folded with 64 bits
+		{"0x7fffffff * 4 / 4", 0x7fffffff},
+	}
+	for _, test := range tests {
+		if got := run(t, program(test.expr)); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.expr, got, test.want)
+		}
+	}
+
+	compileError(t, program("2147483648"), "Constant 2147483648 at line 1 does not fit in 32 bits.")
+	compileError(t, program("-2147483649"), "Constant -2147483649 at line 1 does not fit in 32 bits.")
+	compileError(t, program("0x1_0000_0000"), "Constant 4294967296 at line 1 does not fit in 32 bits.")
+	compileError(t, program("99999999999999999999"), "Number 99999999999999999999 at line 1 is out of range.")
+	for _, bad := range []string{"01", "0x", "1__0", "1_", "0b2"} {
+		if _, err := compile(program(bad), ""); err == nil {
+			t.Errorf("%s compiles", bad)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	const ops = "routine add<a, b> { return a + b; } routine mul<a, b> { return a * b; } "
+	tests := []struct {
+		name string
+		src  string
+		want int64
+	}{
+		{"call", "routine main<> { res f; f = &add; return f(2, 3); }", 5},
+		{"reassigned", "routine main<> { res f, n; f = &add; n = f(2, 3); f = &mul; return n * 100 + f(2, 3); }", 506},
+		{"argument", "routine apply<f, x, y> { return f(x, y); } routine main<> { return apply(&mul, 4, 5); }", 20},
+		{"result", "routine pick<m> { if (m) { return &mul; } return &add; } routine main<> { res f; f = pick(1); return f(6, 7); }", 42},
+		{"global", "global F; routine main<> { F = &add; return F(1, 1); }", 2},
+		{"array", "routine main<> { res fs[2], g; fs[0] = &add; fs[1] = &mul; g = fs[1]; return g(3, 3); }", 9},
+	}
+	for _, test := range tests {
+		if got := run(t, ops+test.src); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.name, got, test.want)
+		}
+	}
+
+	for _, args := range []string{"1", "1, 2, 3", ""} {
+		src := ops + "routine main<> { res f; f = &add; return f(" + args + "); }"
+		if code := trap(t, src); code != vm.ERRARITY {
+			t.Errorf("add(%s) through a reference gives code %d, want %d", args, code, vm.ERRARITY)
+		}
+	}
+
+	compileError(t, "routine half<x: float> : float { return x / 2.0; } routine main<> { res f; f = &half; return 0; }",
+		`Routine "half" referred to at line 1 must take ints and return one int to be called through a reference.`)
+	compileError(t, "routine main<> { res f; f = &nothing; return 0; }", "nothing")
+}
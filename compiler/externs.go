@@ -0,0 +1,99 @@
+package compiler
+
+import (
+	"strings"
+
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// Extern is a routine that the program embedding the VM provides,
+// declared at the top level with
+//
+//	extern routine now<>;
+//
+// It is called like any other routine, and registered with the
+// machine under the same name; see vm.Machine.RegisterHost. Its
+// arguments and its result are ints.
+type Extern struct {
+	name string
+	args int
+	node *parser.Node
+}
+
+// declare_extern adds the extern declared at node to the module.
+func (m *Module) declare_extern(node *parser.Node) error {
+	name := node.GetNodeByRule(parser.RulefuncIdDecl).Child(parser.Rulevariable)
+	_, builtin := builtins[name.Source()]
+	if _, conversion := conversions[name.Source()]; builtin || conversion {
+		return errorExternBuiltin(m, name)
+	}
+	if previous, ok := m.externs[name.Source()]; ok {
+		return errorSymbolAlreadyExists(m, name, previous.node)
+	}
+	if rout, ok := m.routinesByNames[name.Source()]; ok {
+		// routines are linked first, but the extern may come first
+		return m.conflict(name, rout.__node.GetNodeByRule(parser.RulefuncIdDecl))
+	}
+	switch previous := m.symbols.Get(name.Source()).(type) {
+	case *Constant:
+		return errorSymbolAlreadyExists(m, name, previous.node)
+	case *Global:
+		return errorSymbolAlreadyExists(m, name, previous.node)
+	}
+
+	e := &Extern{name: name.Source(), node: name}
+	for _, parameter := range node.GetNodesByRule(parser.Ruleparameter) {
+		if annotation := parameter.Child(parser.Ruleannotation); annotation != nil {
+			if declared := annotation.Child(parser.Ruletypename); declared.Source() != "int" {
+				return errorExternType(m, declared)
+			}
+		}
+		e.args++
+	}
+	m.externs[e.name] = e
+	return nil
+}
+
+// lookup_extern returns the extern that a call names: one of the
+// routine's module, or one of an imported module for module.name.
+func (r *Routine) lookup_extern(name string) (*Extern, bool) {
+	m := r.__module
+	if dot := strings.IndexByte(name, '.'); dot >= 0 {
+		imported, ok := m.imports[name[:dot]]
+		if !ok {
+			return nil, false
+		}
+		m, name = imported.module, name[dot+1:]
+	}
+	e, ok := m.externs[name]
+	return e, ok
+}
+
+// genir_host calls the extern e. Its arguments are pushed first
+// to last, then "HOST %a %a n" calls the host function named by
+// the string in a and leaves its result there.
+func genir_host(r *Routine, e *Extern, argnodes parser.NodeArray, node *parser.Node) (Value, error) {
+	if len(argnodes) != e.args {
+		return Value{}, errorArgumentCount(r, e.name, e.args, len(argnodes), node)
+	}
+	for _, argnode := range argnodes {
+		arg, err := genir_argument(r, argnode, KindInt)
+		if err != nil {
+			return Value{}, err
+		}
+		if arg.Type == ValueConstant {
+			r.emit(vm.STPS, byte(arg.Constant))
+		} else {
+			r.emit(vm.STPR, arg.Register)
+		}
+		arg.release()
+	}
+	reg, err := r.temporary(node)
+	if err != nil {
+		return Value{}, err
+	}
+	load_string(r, reg, r.__module.intern(e.name))
+	r.emit(vm.HOST, reg, reg, byte(e.args))
+	return Value{Type: ValueTemporary, Kind: KindInt, Register: reg}, nil
+}
@@ -0,0 +1,155 @@
+package compiler
+
+import (
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// Global is a variable declared at the top level with
+// "global name;". It lives in the VM's data segment at address
+// and starts out as 0.
+type Global struct {
+	address int
+	kind    Kind
+	node    *parser.Node
+}
+
+// Constant is declared at the top level with
+// "const NAME = expr;". expr is folded when the program is
+// compiled and may use the constants declared before it.
+type Constant struct {
+	value Value
+	node  *parser.Node
+}
+
+// lex_declarations adds the constants and globals of each module
+// to its symbols, its structs to its types and its externs, in
+// the order they are declared. Each module numbers its globals from 0; the
+// linker gives every module its part of the data segment.
+func (c *Compiler) lex_declarations() error {
+	for _, m := range c.program.modules {
+		if err := m.lex_declarations(); err != nil {
+			return m.at(err)
+		}
+	}
+	return nil
+}
+
+func (m *Module) lex_declarations() error {
+	// constant expressions are evaluated outside of any routine
+	scope := NewRoutine()
+	scope.__program, scope.__module = m.__program, m
+
+	for _, node := range m.tree.ASTTree.Children {
+		switch node.Tok.Rule {
+		case parser.Ruleconstant:
+			name := node.Child(parser.Rulevariable)
+			if err := m.declare(name); err != nil {
+				return err
+			}
+			if err := m.check_constant(node); err != nil {
+				return err
+			}
+			value, err := genir_expr(scope, node.Child(parser.Ruleexpr))
+			if err != nil {
+				return err
+			}
+			if value.Type != ValueConstant {
+				return errorNotConstant(m, name)
+			}
+			m.symbols.Add(name.Source(), &Constant{value: value, node: name})
+		case parser.Ruleglobal:
+			for _, name := range node.Children {
+				if name.Tok.Rule != parser.Rulevariable {
+					continue
+				}
+				if err := m.declare(name); err != nil {
+					return err
+				}
+				m.symbols.Add(name.Source(), &Global{address: m.globals, node: name})
+				m.globals++
+			}
+		case parser.Rulestruct:
+			if err := m.declare_struct(node); err != nil {
+				return err
+			}
+		case parser.Ruleextern:
+			if err := m.declare_extern(node); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// declare makes sure that no other constant, global, routine or
+// extern of the module has the name of the one declared at node.
+func (m *Module) declare(node *parser.Node) error {
+	switch previous := m.symbols.Get(node.Source()).(type) {
+	case *Constant:
+		return errorSymbolAlreadyExists(m, node, previous.node)
+	case *Global:
+		return errorSymbolAlreadyExists(m, node, previous.node)
+	}
+	if e, ok := m.externs[node.Source()]; ok {
+		return errorSymbolAlreadyExists(m, node, e.node)
+	}
+	if rout, ok := m.routinesByNames[node.Source()]; ok {
+		// routines are linked first, but the declaration may come
+		// first
+		return m.conflict(node, rout.__node.GetNodeByRule(parser.RulefuncIdDecl))
+	}
+	return nil
+}
+
+// conflict returns the error for two declarations of the same name
+// at a and b, reporting the later one.
+func (m *Module) conflict(a, b *parser.Node) error {
+	if b.Tok.Begin() < a.Tok.Begin() {
+		return errorSymbolAlreadyExists(m, a, b)
+	}
+	return errorSymbolAlreadyExists(m, b, a)
+}
+
+// check_constant makes sure that the variables in the value of the
+// constant declared at node are constants or globals declared
+// before it.
+func (m *Module) check_constant(node *parser.Node) error {
+	name := node.Child(parser.Rulevariable)
+	for _, variable := range node.Child(parser.Ruleexpr).GetNodesByRule(parser.Rulevariable) {
+		if variable.Parent().Tok.Rule != parser.Rulevalue || m.symbols.Exists(variable.Source()) {
+			continue
+		}
+		for _, later := range m.tree.ASTTree.Children {
+			if later.Tok.Rule == parser.Ruleconstant && later.Child(parser.Rulevariable).Source() == variable.Source() {
+				return errorConstantDeclaredLater(m, name, variable, later.Child(parser.Rulevariable))
+			}
+		}
+		return errorUndefinedConstant(m, name, variable)
+	}
+	return nil
+}
+
+// load_global loads the value of g into a temporary.
+func load_global(r *Routine, g *Global, node *parser.Node) (Value, error) {
+	reg, err := r.temporary(node)
+	if err != nil {
+		return Value{}, err
+	}
+	r.__IR.Add(&IRGlobal{op: vm.LOAD, reg: reg, address: g.address})
+	return Value{Type: ValueTemporary, Kind: g.kind, Register: reg}, nil
+}
+
+// store_global stores v into g.
+func store_global(r *Routine, g *Global, v Value, node *parser.Node) error {
+	v, err := convert(r, v, g.kind, node)
+	if err != nil {
+		return err
+	}
+	if v, err = materialize(r, v, node); err != nil {
+		return err
+	}
+	r.__IR.Add(&IRGlobal{op: vm.STORE, reg: v.Register, address: g.address})
+	v.release()
+	return nil
+}
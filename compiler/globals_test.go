@@ -0,0 +1,65 @@
+package compiler
+
+import (
+	"testing"
+)
+
+func TestDeclarationErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{
+			"const N = M + 1;\nconst M = 2;\nroutine main<> { return N; }",
+			`Undefined constant "M" in the value of constant "N" at line 1. (It is declared at line 2;`,
+		},
+		{
+			"const N = Q + 1;\nroutine main<> { return N; }",
+			`Undefined constant "Q" in the value of constant "N" at line 1.`,
+		},
+		{
+			"global A;\nroutine A<> { return 1; }\nroutine main<> { return 0; }",
+			`Error at line 2: "A" already declared at line 1.`,
+		},
+		{
+			"routine A<> { return 1; }\nglobal A;\nroutine main<> { return 0; }",
+			`Error at line 2: "A" already declared at line 1.`,
+		},
+		{
+			"routine main<> { return 0; }\nconst main = 1;",
+			`Error at line 2: "main" already declared at line 1.`,
+		},
+		{
+			"extern routine now<>;\nglobal now;\nroutine main<> { return 0; }",
+			`Error at line 2: "now" already declared at line 1.`,
+		},
+		{
+			"global now;\nextern routine now<>;\nroutine main<> { return 0; }",
+			`Error at line 2: "now" already declared at line 1.`,
+		},
+		{
+			"global A;\nconst A = 1;\nroutine main<> { return 0; }",
+			`Error at line 2: "A" already declared at line 1.`,
+		},
+	}
+	for _, test := range tests {
+		compileError(t, test.src, test.want)
+	}
+}
+
+func TestConstants(t *testing.T) {
+	tests := []struct {
+		src  string
+		want int64
+	}{
+		{"const M = 2;\nconst N = M * 3 + 1;\nglobal G;\nroutine main<> { G = N; return G; }", 7},
+		{"const N = -(1 + 2) * 4 - 1;\nroutine main<> { return N; }", -13},
+		{"global A, B;\nroutine set<> { A = 5; B = A * 2; return 0; }\nroutine main<> { set(); return A + B; }", 15},
+		{"global G;\nroutine main<> { G = G + 1; G = G + 1; return G; }", 2},
+	}
+	for _, test := range tests {
+		if got := run(t, test.src); got != test.want {
+			t.Errorf("%q gives %d, want %d", test.src, got, test.want)
+		}
+	}
+}
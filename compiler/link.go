@@ -0,0 +1,188 @@
+package compiler
+
+import (
+	"github.com/hfern/min/vm"
+)
+
+// Link lays out objects into the byte code of a program that
+// calls the main routine of the first object:
+//
+//   - Data: size of the data segment, if there are globals
+//   - Strings: one STR for each string constant
+//   - Call: main
+//   - OpCode: Program End
+//   - Code: Object1
+//   - ...
+//   - Code: ObjectN
+//
+// main's result is left in register A. The globals of each
+// object get their own part of the data segment, and strings
+// that several objects use are loaded once.
+//
+// Every symbol must be exported by one object only, and every
+// relocation must name one of them.
+func Link(objects []*Object) ([]byte, error) {
+	image, err := link(objects, true)
+	if err != nil {
+		return nil, err
+	}
+	return image.Code, nil
+}
+
+// Image is linked code whose routines are called one by one, by
+// the program embedding the VM. It is laid out like the code of
+// Link without the call to main: running it loads the data and
+// the strings and stops at the END at address Return, which the
+// routines it calls return to; see vm.Machine.Call.
+type Image struct {
+	Code    []byte
+	Module  string            // of the first object
+	Symbols map[string]Symbol // with the address of their ENTRY
+	Return  int
+}
+
+// LinkImage links objects into an image. Unlike Link, it does not
+// need a main routine.
+func LinkImage(objects []*Object) (*Image, error) {
+	return link(objects, false)
+}
+
+func link(objects []*Object, main bool) (*Image, error) {
+	if len(objects) == 0 {
+		return nil, errorNoObjects()
+	}
+
+	type definition struct {
+		object *Object
+		symbol Symbol
+	}
+	symbols := make(map[string]definition)
+	for _, o := range objects {
+		for _, symbol := range o.Symbols {
+			if previous, ok := symbols[symbol.Name]; ok {
+				return nil, errorDuplicateSymbol(symbol.Name, previous.object, o)
+			}
+			symbols[symbol.Name] = definition{o, symbol}
+		}
+	}
+	entry, ok := symbols[objects[0].Module+".main"]
+	if main && (!ok || entry.object != objects[0]) {
+		return nil, errorNoMainSymbol(objects[0])
+	}
+	if signature := entry.symbol.Signature; main && signature != "() int" {
+		return nil, errorMainSignature(entry.symbol)
+	}
+
+	// the data and strings of each object
+	data := make([]int, len(objects))
+	handles := make([][]int, len(objects))
+	size := 0
+	var strings []string
+	stringHandles := make(map[string]int)
+	for i, o := range objects {
+		data[i] = size
+		size += o.Data
+		handles[i] = make([]int, len(o.Strings))
+		for j, s := range o.Strings {
+			handle, ok := stringHandles[s]
+			if !ok {
+				handle = len(strings)
+				strings = append(strings, s)
+				stringHandles[s] = handle
+			}
+			handles[i][j] = handle
+		}
+	}
+
+	ir := NewIRArray()
+	if size > 0 {
+		code := make([]byte, 0, 1+4)
+		byteadd(&code, vm.DATA)
+		setL(&code, size)
+		ir.Add(&IRLiteral{code: code})
+	}
+	for _, str := range strings {
+		code := make([]byte, 0, 1+4+len(str))
+		byteadd(&code, vm.STR)
+		setL(&code, len(str))
+		ir.Add(&IRLiteral{code: append(code, str...)})
+	}
+	call := &IRFuncCall{returnreg: &Register{id: uint(vm.REGA)}}
+	if main {
+		ir.Add(call)
+	}
+	ir.Add(&IRLiteral{code: []byte{vm.END.Byte()}})
+
+	// the code of each object
+	base := make([]int, len(objects))
+	for _, segment := range ir {
+		base[0] += segment.Size()
+	}
+	for i := 1; i < len(objects); i++ {
+		base[i] = base[i-1] + len(objects[i-1].Code)
+	}
+	index := make(map[*Object]int, len(objects))
+	for i, o := range objects {
+		index[o] = i
+	}
+	call.__jumpto = base[0] + entry.symbol.Offset + entrySize
+
+	image := &Image{Module: objects[0].Module, Return: base[0] - 1}
+	image.Symbols = make(map[string]Symbol, len(symbols))
+	for name, definition := range symbols {
+		definition.symbol.Offset += base[index[definition.object]]
+		image.Symbols[name] = definition.symbol
+	}
+	bytecode := make([]byte, 0, base[len(objects)-1]+len(objects[len(objects)-1].Code))
+	for _, segment := range ir {
+		bytecode = append(bytecode, segment.Emit()...)
+	}
+	for i, o := range objects {
+		code := append([]byte(nil), o.Code...)
+		for _, fixup := range o.Fixups {
+			value := fixup.Value
+			switch fixup.Kind {
+			case FixupLabel:
+				value += base[i]
+			case FixupData:
+				value += data[i]
+			case FixupString:
+				if value < 0 || value >= len(handles[i]) {
+					return nil, errorBadFixup(o, fixup.Offset)
+				}
+				value = handles[i][value]
+			}
+			if err := patch(code, fixup.Offset, value, o); err != nil {
+				return nil, err
+			}
+		}
+		for _, relocation := range o.Relocations {
+			target, ok := symbols[relocation.Symbol]
+			if !ok {
+				return nil, errorUndefinedSymbol(relocation.Symbol, o)
+			}
+			signature := relocation.Signature
+			if signature != "" && signature != target.symbol.Signature {
+				return nil, errorSignatureMismatch(relocation.Symbol, o, signature, target.symbol.Signature)
+			}
+			value := base[index[target.object]] + target.symbol.Offset + relocation.Addend
+			if err := patch(code, relocation.Offset, value, o); err != nil {
+				return nil, err
+			}
+		}
+		bytecode = append(bytecode, code...)
+	}
+	image.Code = bytecode
+	return image, nil
+}
+
+// patch sets the 4 byte field at offset in the code of o.
+func patch(code []byte, offset, value int, o *Object) error {
+	if offset < 0 || offset+4 > len(code) {
+		return errorBadFixup(o, offset)
+	}
+	field := make([]byte, 0, 4)
+	setL(&field, value)
+	copy(code[offset:], field)
+	return nil
+}
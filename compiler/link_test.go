@@ -0,0 +1,123 @@
+package compiler
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// compileObject compiles the module in file on its own into an
+// object.
+func compileObject(t *testing.T, file string) *Object {
+	t.Helper()
+	src, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := &parser.VMTree{Buffer: string(src)}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		t.Fatalf("%s: %v", file, err)
+	}
+	tree.ParseTree()
+	cmp := NewCompiler()
+	cmp.SetTree(tree)
+	cmp.SetSource(string(src))
+	cmp.SetPath(file)
+	o, err := cmp.CompileObject()
+	if err != nil {
+		t.Fatalf("%s: %v", file, err)
+	}
+	return o
+}
+
+func TestLink(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.min": `import "m.min"; routine main<> { return m.f(2) + m.g(); }`,
+		"m.min":    `global G; routine f<x> { G = x; return x * 10; } routine g<> { return G + length("abc"); }`,
+	})
+	main := compileObject(t, filepath.Join(dir, "main.min"))
+	m := compileObject(t, filepath.Join(dir, "m.min"))
+
+	// objects go through files
+	var buf bytes.Buffer
+	if err := WriteObject(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadObject(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := Link([]*Object{main, read})
+	if err != nil {
+		t.Fatal(err)
+	}
+	machine := vm.NewMachine(code)
+	if err := machine.Trap(machine.Run()); err != nil {
+		t.Fatal(err)
+	}
+	if got := machine.Registers[vm.REGA]; got != 25 {
+		t.Errorf("main returns %d, want 25", got)
+	}
+}
+
+func TestLinkErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.min":  `import "m.min"; routine main<> { return m.f(1); }`,
+		"m.min":     `routine f<x> { return x; }`,
+		"other.min": `routine main<x> { return x; }`,
+		"float.min": `routine main<> { return 1.5; }`,
+	})
+	main := compileObject(t, filepath.Join(dir, "main.min"))
+	m := compileObject(t, filepath.Join(dir, "m.min"))
+	other := compileObject(t, filepath.Join(dir, "other.min"))
+	floating := compileObject(t, filepath.Join(dir, "float.min"))
+
+	// m.f changes after main is compiled against it
+	if err := os.WriteFile(filepath.Join(dir, "m.min"), []byte(`routine f<x, y> { return x + y; }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed := compileObject(t, filepath.Join(dir, "m.min"))
+
+	tests := []struct {
+		name    string
+		objects []*Object
+		want    string
+	}{
+		{"nothing", nil, "Nothing to link."},
+		{"duplicate", []*Object{main, m, m}, `Symbol "m.f" defined by the objects of modules "m" and "m".`},
+		{"undefined", []*Object{main}, `Symbol "m.f" used by the object of module "main" is not defined.`},
+		{"mismatch", []*Object{main, changed}, `Symbol "m.f" is called as "(int) int" by the object of module "main" but defined as "(int, int) int".`},
+		{"no main", []*Object{m, main}, `The object of module "m", which is linked first, has no routine "main".`},
+		{"main arguments", []*Object{other}, `Symbol "other.main" is defined as "(int) int", but main must take no arguments and return one int.`},
+		{"float main", []*Object{floating}, `Symbol "float.main" is defined as "() float", but main must take no arguments and return one int.`},
+	}
+	for _, test := range tests {
+		_, err := Link(test.objects)
+		if err == nil {
+			t.Errorf("%s: linked, want an error with %q", test.name, test.want)
+		} else if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %q, want one with %q", test.name, err, test.want)
+		}
+	}
+
+	// images do not need a main
+	if _, err := LinkImage([]*Object{m}); err != nil {
+		t.Errorf("linking an image without main: %v", err)
+	}
+	o := *m
+	o.Format = objectFormat + 1
+	var buf bytes.Buffer
+	if err := WriteObject(&buf, &o); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadObject(&buf); err == nil || !strings.Contains(err.Error(), "(Compile it again.)") {
+		t.Errorf("got %v reading an object of another format", err)
+	}
+}
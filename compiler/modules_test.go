@@ -0,0 +1,155 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hfern/min/vm"
+)
+
+// writeFiles writes the files, by their slash separated paths,
+// to a temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// compileFile compiles the main module in file.
+func compileFile(file string, search ...string) (*Compiler, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return compile(string(src), file, search...)
+}
+
+// result runs the program that cmp compiled and returns the
+// result of main.
+func result(t *testing.T, cmp *Compiler) int64 {
+	t.Helper()
+	m := vm.NewMachine(cmp.Bytecode())
+	if err := m.Trap(m.Run()); err != nil {
+		t.Fatal(err)
+	}
+	return m.Registers[vm.REGA]
+}
+
+func TestImports(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.min":          `import "lib/m.min"; import "other/m.min" as o; routine f<> { return 1; } routine main<> { return f() + m.f() + o.f(); }`,
+		"lib/m.min":         `import "util.min"; routine f<> { return 10 * util.f(); }`,
+		"lib/util.min":      `routine f<> { return 1; }`,
+		"other/m.min":       `import "../lib/util.min" as u; routine f<> { return 100 * u.f(); }`,
+		"cycle.min":         `import "lib/a.min"; routine main<> { return 0; }`,
+		"lib/a.min":         `import "b.min"; routine f<> { return 0; }`,
+		"lib/b.min":         `import "a.min"; routine f<> { return 0; }`,
+		"self.min":          `import "self.min"; routine main<> { return 0; }`,
+		"twice.min":         `import "lib/m.min"; import "other/m.min"; routine main<> { return 0; }`,
+		"unnamed.min":       `import "lib/two-words.min"; routine main<> { return 0; }`,
+		"lib/two-words.min": `routine f<> { return 0; }`,
+		"broken.min":        `import "lib/broken.min"; routine main<> { return 0; }`,
+		"lost.min":          `import "nowhere.min"; routine main<> { return 0; }`,
+		"lib/broken.min":    `routine f<> { return g(); }`,
+	})
+	cmp, err := compileFile(filepath.Join(dir, "main.min"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result(t, cmp); got != 111 {
+		t.Errorf("main returns %d, want 111", got)
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"cycle.min", "Import cycle: "},
+		{"self.min", "Import cycle: "},
+		{"twice.min", `module "m" already imported at line 1`},
+		{"unnamed.min", `is called "two-words", which is not a name`},
+		{"broken.min", `Call to undefined routine "g"`},
+		{"lost.min", `Cannot find module "nowhere.min" imported at line 1`},
+	}
+	for _, test := range tests {
+		_, err := compileFile(filepath.Join(dir, test.file))
+		if err == nil {
+			t.Errorf("%s compiles, want an error with %q", test.file, test.want)
+		} else if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %q, want one with %q", test.file, err, test.want)
+		}
+	}
+}
+
+// Imports are looked for next to the importing file first, then
+// in the directories of the search path, in order.
+func TestSearchPath(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app/main.min": `import "m.min"; routine main<> { return m.f(); }`,
+		"app/near.min": `import "n.min"; routine main<> { return n.f(); }`,
+		"app/n.min":    `routine f<> { return 1; }`,
+		"first/m.min":  `routine f<> { return 2; }`,
+		"second/m.min": `routine f<> { return 3; }`,
+		"second/n.min": `routine f<> { return 4; }`,
+	})
+	first, second := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+	tests := []struct {
+		file   string
+		search []string
+		want   int64
+	}{
+		{"app/main.min", []string{first, second}, 2},
+		{"app/main.min", []string{second, first}, 3},
+		{"app/near.min", []string{second}, 1},
+	}
+	for _, test := range tests {
+		cmp, err := compileFile(filepath.Join(dir, test.file), test.search...)
+		if err != nil {
+			t.Errorf("%s with %v: %v", test.file, test.search, err)
+			continue
+		}
+		if got := result(t, cmp); got != test.want {
+			t.Errorf("%s with %v returns %d, want %d", test.file, test.search, got, test.want)
+		}
+	}
+
+	_, err := compileFile(filepath.Join(dir, "app/main.min"))
+	if err == nil || !strings.Contains(err.Error(), `Cannot find module "m.min"`) {
+		t.Errorf("got %v without a search path, want the module not to be found", err)
+	}
+}
+
+// The names of routines, constants, globals and structs only need
+// to differ within a module.
+func TestNamesAcrossModules(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.min": `import "m.min";
+const N = 1;
+global G;
+struct P { x }
+routine f<> { return N; }
+routine main<> { res p: P; G = 2; p.x = 4; return f() + G + p.x + m.f(); }`,
+		"m.min": `const N = 8;
+global G;
+struct P { y, x }
+routine f<> { res p: P; G = 16; p.x = 32; return N + G + p.x; }`,
+	})
+	cmp, err := compileFile(filepath.Join(dir, "main.min"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result(t, cmp); got != 63 {
+		t.Errorf("main returns %d, want 63", got)
+	}
+}
@@ -0,0 +1,138 @@
+package compiler
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Object is the code of one module, assembled as if it started
+// at address 0, with what the linker needs to place it in a
+// program; see Link. Its symbols are the module's routines, named
+// module.routine.
+//
+// The fields of the code that depend on where things end up are
+// listed as fixups, for the module's own labels, globals and
+// strings, and as relocations, for the routines of other modules
+// that it calls or refers to.
+type Object struct {
+	Format      int          `json:"format"`
+	Module      string       `json:"module"`
+	Code        []byte       `json:"code"`
+	Data        int          `json:"data"`    // the number of globals
+	Strings     []string     `json:"strings"` // by handle
+	Symbols     []Symbol     `json:"symbols"`
+	Fixups      []Fixup      `json:"fixups"`
+	Relocations []Relocation `json:"relocations"`
+}
+
+// objectFormat is the version of Object that this compiler
+// writes and reads.
+const objectFormat = 1
+
+// Symbol is an exported routine, whose ENTRY is at Offset in the
+// code of its object. The modules of the objects linked into a
+// program must have different names.
+type Symbol struct {
+	Name      string `json:"name"`
+	Offset    int    `json:"offset"`
+	Signature string `json:"signature"`
+}
+
+type FixupKind byte
+
+const (
+	FixupLabel  FixupKind = iota // Value is an offset in the object's code
+	FixupData                    // Value is the address of a global of the object
+	FixupString                  // Value is the handle of a string of the object
+)
+
+// Fixup is a 4 byte field at Offset in the code of an object
+// that holds Value until the object is linked.
+type Fixup struct {
+	Offset int       `json:"offset"`
+	Kind   FixupKind `json:"kind"`
+	Value  int       `json:"value"`
+}
+
+// Relocation is a 4 byte field at Offset in the code of an
+// object that is linked to the address of Symbol plus Addend.
+// Calls expect the routine they call to have Signature; the
+// addresses of routines taken with & are checked when they are
+// called.
+type Relocation struct {
+	Offset    int    `json:"offset"`
+	Symbol    string `json:"symbol"`
+	Addend    int    `json:"addend"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// entrySize is the size of the ENTRY that routines start with.
+// Direct calls jump past it.
+const entrySize = 1 + 4
+
+// object assembles the routines of the module into an object.
+// Calls to the routines of other modules become relocations.
+func (m *Module) object() (*Object, error) {
+	p := m.__program
+	ir := NewIRArray()
+	for _, rout := range p.routines {
+		if rout.__module == m {
+			ir.Add(rout.__IR...)
+		}
+	}
+	ctx := IRContext{Program: p, IRArray: &ir, Labels: labelOffsets(ir)}
+	for ctx.PassNumber = 1; ; ctx.PassNumber++ {
+		done := true
+		for _, segment := range ir {
+			done = segment.Pass(ctx) && done
+		}
+		if done {
+			break
+		}
+	}
+
+	o := &Object{Format: objectFormat, Module: m.prefix, Data: m.globals, Strings: m.strings}
+	for _, rout := range p.routines {
+		if rout.__module != m || rout.__parent != nil {
+			continue
+		}
+		offset, _ := labelAddress(ctx, rout.Symbol("entry"))
+		o.Symbols = append(o.Symbols, Symbol{
+			Name:      rout.exported(),
+			Offset:    offset,
+			Signature: rout.signature(),
+		})
+	}
+	for _, segment := range ir {
+		if segment, ok := segment.(relocatable); ok {
+			if err := segment.relocate(ctx, o, len(o.Code)); err != nil {
+				return nil, err
+			}
+		}
+		o.Code = append(o.Code, segment.Emit()...)
+	}
+	return o, nil
+}
+
+// routine returns the routine whose entry is labelled symbol.
+func (p *Program) routine(symbol string) (*Routine, bool) {
+	rout, ok := p.entries[symbol]
+	return rout, ok
+}
+
+// WriteObject writes o to w.
+func WriteObject(w io.Writer, o *Object) error {
+	return json.NewEncoder(w).Encode(o)
+}
+
+// ReadObject reads an object written by WriteObject.
+func ReadObject(r io.Reader) (*Object, error) {
+	var o Object
+	if err := json.NewDecoder(r).Decode(&o); err != nil {
+		return nil, errorBadObject(err)
+	}
+	if o.Format != objectFormat {
+		return nil, errorObjectFormat(o.Module, o.Format)
+	}
+	return &o, nil
+}
@@ -0,0 +1,130 @@
+package compiler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// builtin is a routine that the VM provides as one instruction,
+// "OP %a %b", which leaves its result in a. A builtin with one
+// argument has it in a and in b, and one without any has neither.
+type builtin struct {
+	op       vm.Operation
+	operands []Kind // of its arguments
+	result   Kind
+}
+
+var builtins = map[string]builtin{
+	"length":  {vm.STRLEN, []Kind{KindString}, KindInt},                // length(s)
+	"concat":  {vm.STRCAT, []Kind{KindString, KindString}, KindString}, // concat(s, t)
+	"compare": {vm.STRCMP, []Kind{KindString, KindString}, KindInt},    // compare(s, t): -1, 0 or 1
+	"charat":  {vm.STRAT, []Kind{KindString, KindInt}, KindInt},        // charat(s, i): byte i of s
+	"sqrt":    {vm.SQRT, []Kind{KindFloat}, KindFloat},                 // sqrt(x)
+
+	// input and output; print and prints return what they write
+	"print":    {vm.PRINT, []Kind{KindInt}, KindInt},        // print(n)
+	"prints":   {vm.PRINTS, []Kind{KindString}, KindString}, // prints(s)
+	"readint":  {vm.READ, nil, KindInt},                     // readint()
+	"readline": {vm.READLN, nil, KindString},                // readline(): "" at the end
+	"eof":      {vm.EOF, nil, KindInt},                      // eof(): 1 once the input is read
+	"exit":     {vm.EXIT, []Kind{KindInt}, KindInt},         // exit(status): main returns status
+}
+
+func genir_builtin(r *Routine, name string, b builtin, argnodes parser.NodeArray, node *parser.Node) (Value, error) {
+	if len(argnodes) != len(b.operands) {
+		return Value{}, errorArgumentCount(r, name, len(b.operands), len(argnodes), node)
+	}
+	if len(b.operands) == 0 {
+		reg, err := r.temporary(node)
+		if err != nil {
+			return Value{}, err
+		}
+		r.emit(b.op, reg, reg)
+		return Value{Type: ValueTemporary, Kind: b.result, Register: reg}, nil
+	}
+	first, err := genir_expr(r, argnodes[0])
+	if err != nil {
+		return Value{}, err
+	}
+	if first, err = convert(r, first, b.operands[0], argnodes[0]); err != nil {
+		return Value{}, err
+	}
+	result, err := writable(r, first, node)
+	if err != nil {
+		return Value{}, err
+	}
+	result.Kind = b.result
+	if len(b.operands) == 1 {
+		r.emit(b.op, result.Register, result.Register)
+		return result, nil
+	}
+
+	second, err := genir_expr(r, argnodes[1])
+	if err != nil {
+		return Value{}, err
+	}
+	if second, err = convert(r, second, b.operands[1], argnodes[1]); err != nil {
+		return Value{}, err
+	}
+	if second, err = materialize(r, second, node); err != nil {
+		return Value{}, err
+	}
+	r.emit(b.op, result.Register, second.Register)
+	second.release()
+	return result, nil
+}
+
+// genir_string returns the handle of a string constant. The
+// constants are loaded by the program before main is called, in
+// the order of their handles; see Link.
+func genir_string(r *Routine, node *parser.Node) (Value, error) {
+	handle := r.__module.intern(unquote(node.Source()))
+	return Value{Type: ValueConstant, Kind: KindString, Constant: handle, Handle: true}, nil
+}
+
+// intern returns the handle of the string constant s, adding it
+// to the module if it is new. Handles are the module's own until
+// its object is linked.
+func (m *Module) intern(s string) int {
+	if handle, ok := m.stringHandles[s]; ok {
+		return handle
+	}
+	m.strings = append(m.strings, s)
+	m.stringHandles[s] = len(m.strings) - 1
+	return len(m.strings) - 1
+}
+
+// load_string loads the handle of a string constant into dst, as
+// an imm32 that the linker rewrites; see IRString.
+func load_string(r *Routine, dst *Register, handle int) {
+	r.__IR.Add(&IRString{reg: dst, handle: handle})
+}
+
+var escapes = map[byte]byte{
+	'n': '\n', 'r': '\r', 't': '\t', '0': 0, '\\': '\\', '"': '"',
+}
+
+// unquote decodes a string literal, which the parser has checked
+// is well formed.
+func unquote(literal string) string {
+	literal = literal[1 : len(literal)-1]
+	var b strings.Builder
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' {
+			b.WriteByte(literal[i])
+			continue
+		}
+		i++
+		if literal[i] == 'x' {
+			c, _ := strconv.ParseUint(literal[i+1:i+3], 16, 8)
+			b.WriteByte(byte(c))
+			i += 2
+			continue
+		}
+		b.WriteByte(escapes[literal[i]])
+	}
+	return b.String()
+}
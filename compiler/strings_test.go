@@ -0,0 +1,67 @@
+package compiler
+
+import (
+	"testing"
+)
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		src  string
+		want int64
+	}{
+		{`routine main<> { return length(concat("ab", "cde")); }`, 5},
+		{`routine main<> { return charat("abc", 1); }`, 'b'},
+		{`routine main<> { return compare("b", "a"); }`, 1},
+		{`routine main<> { res s; s = concat("a", "b"); if (s == "ab") { return 1; } return 0; }`, 1},
+		{`routine main<> { if ("a" != "a") { return 1; } return 0; }`, 0},
+		{`routine main<> { if ("abc" < "abd") { return 1; } return 0; }`, 1},
+		{`routine main<> { res x; x = "x" == "x"; return x; }`, 1},
+		{`routine greet<name> { return length(name); } routine main<> { return greet("bob"); }`, 3},
+		{`routine main<> { res s; s = name(); return length(s); } routine name<> { return "bobby"; }`, 5},
+		{`routine empty<x> { if (x) { return "x"; } } routine main<> { return length(empty(0)); }`, 0},
+		{`global G; routine main<> { G = concat("a", "bc"); return length(G); }`, 3},
+		{`const HELLO = "hello"; routine main<> { return length(HELLO); }`, 5},
+		{`routine main<> { res a[2]; a[1] = "abc"; return length(a[1]); }`, 3},
+		{`routine main<> { res s: string; s = "ab"; return length(s); }`, 2},
+		{`routine size<s: string> { return length(s); } routine main<> { return size("abc"); }`, 3},
+		{`routine size<s> { return length(s); } routine main<> { res t; t = "abcd"; return size(t); }`, 4},
+		{`routine name<> : string { return "bob"; } routine main<> { return length(name()); }`, 3},
+		{`struct person { name: string, age } routine main<> { res p: person; p.name = "al"; return length(p.name); }`, 2},
+	}
+	for _, test := range tests {
+		if got := run(t, test.src); got != test.want {
+			t.Errorf("%q gives %d, want %d", test.src, got, test.want)
+		}
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`routine main<> { res x; x = print("hi"); return x; }`, `""hi"" is a string where an int is expected`},
+		{`routine main<> { return length(1); }`, `"1" is an int where a string is expected`},
+		{`routine main<> { return "a" + 1; }`, `Operator + at line 1 cannot be applied to a string`},
+		{`routine main<> { res s; s = "a" * "b"; return 0; }`, `Operator * at line 1 cannot be applied to a string`},
+		{`routine main<> { return -"a"; }`, `Operator - at line 1 cannot be applied to a string`},
+		{`routine main<> { if ("a" == 1) { return 1; } return 0; }`, `Cannot compare a string with an int at line 1`},
+		{`routine main<> { if ("a") { return 1; } return 0; }`, `""a"" is a string where an int is expected`},
+		{`routine main<> { return int("a"); }`, `""a"" is a string where an int is expected`},
+		{`routine main<> { res s; s = "a"; s = 1; return 0; }`, `"1" is an int where a string is expected`},
+		{`routine main<> { res s; s = 1; s = "a"; return 0; }`, `"1" is an int where a string is expected`},
+		{`routine main<> { res s; s = "a"; s = 1.5; return 0; }`, `"1.5" is a float where a string is expected`},
+		{`routine f<x> { return 1; } routine main<> { res x; x = f("a"); return f(1); }`, `"1" is an int where a string is expected`},
+		{`routine main<> { res s: string; s = 1; return 0; }`, `"1" is an int but string is declared`},
+		{`routine main<> { res s: int; s = "a"; return 0; }`, `""a"" is a string but int is declared`},
+		{`routine main<> { res s: string; return s + 1; }`, `Operator + at line 1 cannot be applied to a string`},
+		{`routine main<> { res s: string; return length(s) + s; }`, `Operator + at line 1 cannot be applied to a string`},
+		{`routine name<> : string { return 1; } routine main<> { return 0; }`, `"1" is an int but string is declared`},
+		{`routine main<> { res n: int; return length(n); }`, `"n" is a string but int is declared`},
+		{`routine main<> { res s: text; return 0; }`, `Unknown type "text" at line 1. (Types are int, float, string and the structs declared.)`},
+		{`extern routine now<n>; routine main<> { return now("a"); }`, `""a"" is a string where an int is expected`},
+	}
+	for _, test := range tests {
+		compileError(t, test.src, test.want)
+	}
+}
@@ -0,0 +1,326 @@
+package compiler
+
+import (
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// Struct is a record type declared at the top level with
+// "struct Name { x, y: float }". A struct value is one word of
+// VM memory for each field, kept in the frame of its routine like
+// an array; a struct variable holds its address.
+//
+// Structs are passed and returned by value. The caller passes
+// the address of a struct argument and the callee copies it into
+// its own frame when it is entered. A routine that returns a
+// struct is given the address to return it at as a hidden first
+// argument; see IRFuncCall.
+type Struct struct {
+	name   string
+	fields []*structField
+	node   *parser.Node
+}
+
+// structField is a field of a struct. Fields hold ints, floats
+// or strings and their kinds are declared or inferred like those
+// of variables.
+type structField struct {
+	name     string
+	offset   int
+	kind     Kind
+	declared *parser.Node // the type name, if there is one
+	node     *parser.Node
+}
+
+// declare_struct adds the struct declared at node to the module.
+func (m *Module) declare_struct(node *parser.Node) error {
+	name := node.Child(parser.Rulevariable)
+	if previous, ok := m.structs[name.Source()]; ok {
+		return errorSymbolAlreadyExists(m, name, previous.node)
+	}
+	s := &Struct{name: name.Source(), node: name}
+	for _, child := range node.Children {
+		if child.Tok.Rule != parser.Rulefield {
+			continue
+		}
+		fieldname := child.Child(parser.Rulefieldname)
+		if previous := s.field(fieldname.Source()); previous != nil {
+			return errorSymbolAlreadyExists(m, fieldname, previous.node)
+		}
+		f := &structField{name: fieldname.Source(), offset: len(s.fields), node: fieldname}
+		if annotation := child.Child(parser.Ruleannotation); annotation != nil {
+			declared := annotation.Child(parser.Ruletypename)
+			kind, structure, err := m.lookup_type(declared)
+			if err != nil {
+				return err
+			}
+			if structure != nil {
+				return errorFieldType(m, fieldname)
+			}
+			f.kind, f.declared = kind, declared
+		}
+		s.fields = append(s.fields, f)
+	}
+	m.structs[s.name] = s
+	return nil
+}
+
+// field returns the field of s called name, or nil.
+func (s *Struct) field(name string) *structField {
+	for _, f := range s.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// lookup_type returns the kind or the struct that a type name
+// names. Struct values are addresses, which are ints.
+func (m *Module) lookup_type(typename *parser.Node) (Kind, *Struct, error) {
+	if kind, ok := kinds[typename.Source()]; ok {
+		return kind, nil, nil
+	}
+	if s, ok := m.structs[typename.Source()]; ok {
+		return KindInt, s, nil
+	}
+	return KindInt, nil, errorUnknownType(m, typename)
+}
+
+// struct_of returns the struct that a parameter or a reserved
+// variable is declared to be, or nil.
+func (m *Module) struct_of(declaration *parser.Node) *Struct {
+	if annotation := declaration.Child(parser.Ruleannotation); annotation != nil {
+		return m.structs[annotation.Child(parser.Ruletypename).Source()]
+	}
+	return nil
+}
+
+// copy_arguments copies the struct arguments of the routine,
+// whose addresses it was passed, into its frame.
+func (r *Routine) copy_arguments() error {
+	for _, name := range r.lex_arguments() {
+		offset, ok := r.__records[name.Parent()]
+		if !ok {
+			continue
+		}
+		variable := r.vmap._map[name.Source()]
+		slot, err := r.temporary(name)
+		if err != nil {
+			return err
+		}
+		if err := field_address(r, slot, r.__frame, offset, name); err != nil {
+			return err
+		}
+		if err := copy_struct(r, variable.structure, slot, variable.register, name); err != nil {
+			return err
+		}
+		r.emit(vm.MOV, variable.register, slot)
+		slot.Unlock()
+	}
+	return nil
+}
+
+// reserve_record points the register of variable at the struct's
+// place in the frame.
+func reserve_record(r *Routine, variable *VariableMeta, offset int, node *parser.Node) error {
+	return field_address(r, variable.register, r.__frame, offset, node)
+}
+
+// genir_member loads p.x into a temporary.
+func genir_member(r *Routine, node *parser.Node) (Value, error) {
+	address, f, err := member_address(r, node)
+	if err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.LOADM, address.Register, address.Register)
+	address.Kind = f.kind
+	return address, nil
+}
+
+// store_member stores v into p.x.
+func store_member(r *Routine, node *parser.Node, v Value) error {
+	address, f, err := member_address(r, node)
+	if err != nil {
+		return err
+	}
+	if v, err = convert(r, v, f.kind, node); err != nil {
+		return err
+	}
+	if v, err = materialize(r, v, node); err != nil {
+		return err
+	}
+	r.emit(vm.STOREM, v.Register, address.Register)
+	address.release()
+	v.release()
+	return nil
+}
+
+// member_address computes the address of p.x into a temporary.
+func member_address(r *Routine, node *parser.Node) (Value, *structField, error) {
+	name := node.Child(parser.Rulevariable)
+	variable, ok := r.local(name.Source())
+	if !ok {
+		if r.__module.symbols.Exists(name.Source()) {
+			return Value{}, nil, errorNotAStruct(r, name)
+		}
+		return Value{}, nil, errorUndeclaredVariable(r, name)
+	}
+	if variable.structure == nil {
+		return Value{}, nil, errorNotAStruct(r, name)
+	}
+	f := variable.structure.field(node.Child(parser.Rulefieldname).Source())
+	if f == nil {
+		return Value{}, nil, errorUnknownField(r, node, variable.structure)
+	}
+
+	address, err := r.temporary(node)
+	if err != nil {
+		return Value{}, nil, err
+	}
+	if err := field_address(r, address, variable.register, f.offset, node); err != nil {
+		return Value{}, nil, err
+	}
+	return Value{Type: ValueTemporary, Register: address}, f, nil
+}
+
+// member_field returns the field that p.x names, or nil if p is
+// not a struct with a field x.
+func (r *Routine) member_field(node *parser.Node) *structField {
+	variable, ok := r.vmap._map[node.Child(parser.Rulevariable).Source()]
+	if !ok || variable.structure == nil {
+		return nil
+	}
+	return variable.structure.field(node.Child(parser.Rulefieldname).Source())
+}
+
+// assign_struct copies the struct that expr evaluates to into
+// the one at the address in dst. expr must be a variable of the
+// same struct or a call of a routine that returns one, which is
+// given dst to return it at.
+func assign_struct(r *Routine, s *Struct, dst *Register, expr *parser.Node) error {
+	value := single_value(expr)
+	switch {
+	case value == nil:
+	case value.Tok.Rule == parser.Rulevariable:
+		if variable, ok := r.local(value.Source()); ok && variable.structure == s {
+			return copy_struct(r, s, dst, variable.register, expr)
+		}
+	case value.Tok.Rule == parser.Rulefunccall:
+		name := value.Child(parser.Rulefuncidentifier).Source()
+		if target, ok := r.lookup_routine(name); ok && target.returned == s {
+			results, err := genir_call(r, value, target, &Value{Type: ValueVariable, Register: dst})
+			if err != nil {
+				return err
+			}
+			results[0].release()
+			return nil
+		}
+	}
+	return errorNotStruct(r, expr, s)
+}
+
+// struct_argument returns the address of the struct passed as an
+// argument, which must be a variable of s.
+func struct_argument(r *Routine, s *Struct, expr *parser.Node) (Value, error) {
+	if value := single_value(expr); value != nil && value.Tok.Rule == parser.Rulevariable {
+		if variable, ok := r.local(value.Source()); ok && variable.structure == s {
+			return Value{Type: ValueVariable, Register: variable.register}, nil
+		}
+	}
+	return Value{}, errorNotStruct(r, expr, s)
+}
+
+// single_value returns the value that an expression is made of
+// alone, without operators, or nil.
+func single_value(node *parser.Node) *parser.Node {
+	for {
+		switch node.Tok.Rule {
+		case parser.Ruleexpr, parser.Ruleconjunction, parser.Rulecomparison,
+			parser.Rulesum, parser.Ruleterm:
+			operands := operands(node)
+			if len(operands) != 1 {
+				return nil
+			}
+			node = operands[0]
+		case parser.Ruleunary:
+			if node = node.Child(parser.Ruleprimary); node == nil {
+				return nil
+			}
+		case parser.Ruleprimary:
+			if inner := node.Child(parser.Ruleexpr); inner != nil {
+				node = inner
+			} else {
+				node = node.Child(parser.Rulevalue)
+			}
+		case parser.Rulevalue:
+			return node.Children[0]
+		default:
+			return nil
+		}
+	}
+}
+
+// copy_struct copies the fields of the struct of s at the
+// address in src to the one at the address in dst.
+func copy_struct(r *Routine, s *Struct, dst, src *Register, node *parser.Node) error {
+	address, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	defer address.Unlock()
+	word, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	defer word.Unlock()
+
+	for _, f := range s.fields {
+		if err := field_address(r, address, src, f.offset, node); err != nil {
+			return err
+		}
+		r.emit(vm.LOADM, word, address)
+		if err := field_address(r, address, dst, f.offset, node); err != nil {
+			return err
+		}
+		r.emit(vm.STOREM, word, address)
+	}
+	return nil
+}
+
+// clear_struct sets the fields of the struct of s at the address
+// in dst to 0.
+func clear_struct(r *Routine, s *Struct, dst *Register, node *parser.Node) error {
+	address, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	defer address.Unlock()
+	zero, err := r.temporary(node)
+	if err != nil {
+		return err
+	}
+	defer zero.Unlock()
+
+	r.emit(vm.SET, zero, byte(0))
+	for _, f := range s.fields {
+		if err := field_address(r, address, dst, f.offset, node); err != nil {
+			return err
+		}
+		r.emit(vm.STOREM, zero, address)
+	}
+	return nil
+}
+
+// field_address sets dst to base + offset.
+func field_address(r *Routine, dst, base *Register, offset int, node *parser.Node) error {
+	if offset == 0 {
+		r.emit(vm.MOV, dst, base)
+		return nil
+	}
+	if err := load_constant(r, dst, offset, node); err != nil {
+		return err
+	}
+	r.emit(vm.ADDREG, dst, base)
+	return nil
+}
@@ -0,0 +1,69 @@
+package compiler
+
+import (
+	"testing"
+)
+
+const point = "struct P { x, y } "
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want int64
+	}{
+		{
+			"fields",
+			"routine main<> { res p: P; p.x = 3; p.y = 4; return p.x * 10 + p.y; }",
+			34,
+		},
+		{
+			"passed",
+			"routine sum<p: P> { return p.x + p.y; } routine main<> { res p: P; p.x = 3; p.y = 4; return sum(p); }",
+			7,
+		},
+		{
+			"passed by value",
+			"routine clear<p: P> { p.x = 0; return 0; } routine main<> { res p: P; p.x = 3; clear(p); return p.x; }",
+			3,
+		},
+		{
+			"returned",
+			"routine make<x, y> : P { res p: P; p.x = x; p.y = y; return p; } routine main<> { res p: P; p = make(5, 6); return p.x * 10 + p.y; }",
+			56,
+		},
+		{
+			"assigned",
+			"routine main<> { res p: P, q: P; p.x = 1; p.y = 2; q = p; p.x = 9; return q.x * 10 + q.y; }",
+			12,
+		},
+		{
+			"returned through calls",
+			"routine make<> : P { res p: P; p.y = 8; return p; } routine pass<> : P { return make(); } routine main<> { res p: P; p = pass(); return p.y; }",
+			8,
+		},
+		{
+			"float field",
+			"struct F { f: float } routine main<> { res v: F; v.f = 2.5; return int(v.f * 2.0); }",
+			5,
+		},
+	}
+	for _, test := range tests {
+		if got := run(t, point+test.src); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.name, got, test.want)
+		}
+	}
+
+	invalid := []struct {
+		src  string
+		want string
+	}{
+		{"routine main<> { res p: P; return p.z; }", `Struct P has no field "z" at line 1.`},
+		{"routine main<> { res p: P; return p; }", `Struct "p" at line 1 is used as a value.`},
+		{"routine f<> : P, int { res p: P; return p, 1; } routine main<> { return 0; }", "returns a struct along with other values."},
+		{"routine main<> { res p: Q; return 0; }", `Unknown type "Q"`},
+	}
+	for _, test := range invalid {
+		compileError(t, point+test.src, test.want)
+	}
+}
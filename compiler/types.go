@@ -0,0 +1,475 @@
+package compiler
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+// Kind is what the bits of a value in a register mean. Strings
+// are the handles of the strings in the VM's table.
+type Kind byte
+
+const (
+	KindInt Kind = iota
+	KindFloat
+	KindString
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindFloat:
+		return "float"
+	case KindString:
+		return "string"
+	}
+	return "int"
+}
+
+// kinds are the type names of the kinds.
+var kinds = map[string]Kind{
+	"int":    KindInt,
+	"float":  KindFloat,
+	"string": KindString,
+}
+
+// conversions are the builtins that convert their argument to a
+// kind: float(x) and int(x), which truncates.
+var conversions = map[string]Kind{
+	"float": KindFloat,
+	"int":   KindInt,
+}
+
+// check_types is the type checker. It sets the kinds declared by
+// annotations and works out those of everything else: a variable,
+// array, global, argument or result holds floats if it is ever
+// given a float, strings if it is given a string, and ints
+// otherwise. Giving a float to something declared int is an
+// error, and so is mixing strings with ints or floats.
+func (p *Program) check_types() error {
+	for _, rout := range p.routines {
+		if err := rout.annotate(); err != nil {
+			return rout.__module.at(err)
+		}
+	}
+	// kinds only ever change from int to float or string, so the
+	// loop ends
+	for changed := true; changed; {
+		changed = false
+		for _, rout := range p.routines {
+			widened, err := rout.infer_kinds(false)
+			if err != nil {
+				return rout.__module.at(err)
+			}
+			changed = widened || changed
+		}
+	}
+	// until now an int could have been a string that was not known
+	// to be one yet
+	for _, rout := range p.routines {
+		if _, err := rout.infer_kinds(true); err != nil {
+			return rout.__module.at(err)
+		}
+	}
+	return nil
+}
+
+// annotate sets the kinds and structs declared by the
+// annotations on the routine's results, parameters and reserved
+// variables, and counts the routine's results.
+func (r *Routine) annotate() error {
+	m := r.__module
+	var typenames parser.NodeArray
+	if results := r.__node.Child(parser.Ruleresults); results != nil {
+		typenames = results.GetNodesByRule(parser.Ruletypename)
+	}
+	if err := r.count_results(len(typenames)); err != nil {
+		return err
+	}
+	r.__declared = make(parser.NodeArray, len(r.returns))
+	for i, name := range typenames {
+		kind, structure, err := m.lookup_type(name)
+		if err != nil {
+			return err
+		}
+		if structure != nil && len(typenames) > 1 {
+			return errorStructResults(r, name)
+		}
+		r.returns[i], r.returned, r.__declared[i] = kind, structure, name
+	}
+
+	for _, name := range append(r.lex_arguments(), r.lex_locals()...) {
+		declaration := name.Parent()
+		annotation := declaration.Child(parser.Ruleannotation)
+		if annotation == nil {
+			continue
+		}
+		declared := annotation.Child(parser.Ruletypename)
+		kind, structure, err := m.lookup_type(declared)
+		if err != nil {
+			return err
+		}
+		if structure != nil && declaration.Child(parser.Ruleexpr) != nil {
+			return errorArrayOfStructs(r, name)
+		}
+		variable := r.vmap._map[name.Source()]
+		if variable.declared != nil && variable.declared.Source() != declared.Source() {
+			return errorAnnotationConflict(r, name, declared, variable.declared)
+		}
+		variable.kind, variable.structure, variable.declared = kind, structure, declared
+	}
+	return nil
+}
+
+// count_results works out how many values the routine returns:
+// as many as its annotation lists, or as its return statements
+// give, which must all give the same number. It returns one
+// value if neither says otherwise.
+func (r *Routine) count_results(declared int) error {
+	count := declared
+	for _, node := range r.nodes(parser.Rulereturning) {
+		given := len(returned_exprs(node))
+		if count == 0 {
+			count = given
+		}
+		if given != count {
+			return errorReturnCount(r, node, count, given)
+		}
+	}
+	if count == 0 {
+		count = 1
+	}
+	r.returns = make([]Kind, count)
+	return nil
+}
+
+// infer_kinds makes one pass over the routine for
+// Program.check_types and reports whether any kind changed. Once
+// the kinds are known, final also reports ints given to strings.
+func (r *Routine) infer_kinds(final bool) (bool, error) {
+	changed := false
+	var err error
+	// widen makes kind float or string if one is given to it at
+	// node; ints may be given to floats
+	widen := func(kind *Kind, declared *parser.Node, given Kind, node *parser.Node) {
+		if *kind == given || err != nil {
+			return
+		}
+		switch {
+		case *kind == KindFloat && given == KindInt:
+		case *kind == KindString && given == KindInt && !final:
+		case declared != nil:
+			err = errorTypeMismatch(r, node, given, declared)
+		case *kind != KindInt:
+			err = errorKindMismatch(r, node, given, *kind)
+		default:
+			*kind, changed = given, true
+		}
+	}
+
+	r.__node.Child(parser.Rulecodeblock).Walk(func(node *parser.Node) parser.SearchCode {
+		switch node.Tok.Rule {
+		case parser.Ruleroutine:
+			// nested routines are checked on their own
+			return parser.SEARCH_SKIP
+		case parser.Ruleassignment:
+			expr := node.Child(parser.Ruleexpr)
+			if kind, declared := r.kind_target(node); kind != nil {
+				widen(kind, declared, r.kind_of(expr), expr)
+			}
+		case parser.Ruledestructuring:
+			call := single_value(node.Child(parser.Ruleexpr))
+			if call == nil || call.Tok.Rule != parser.Rulefunccall {
+				break
+			}
+			target, ok := r.lookup_routine(call.Child(parser.Rulefuncidentifier).Source())
+			if !ok {
+				break
+			}
+			for i, assigned := range node.GetNodesByRule(parser.Ruletarget) {
+				if kind, declared := r.kind_target(assigned); kind != nil && i < len(target.returns) {
+					widen(kind, declared, target.returns[i], call)
+				}
+			}
+		case parser.Rulereturning:
+			for i, expr := range returned_exprs(node) {
+				widen(&r.returns[i], r.__declared[i], r.kind_of(expr), expr)
+			}
+		case parser.Rulefunccall:
+			name := node.Child(parser.Rulefuncidentifier).Source()
+			if kind, _ := r.kind_variable(name); kind != nil {
+				break
+			}
+			if b, ok := builtins[name]; ok {
+				// a variable given to a builtin that takes a
+				// string holds strings
+				for i, arg := range call_arguments(node) {
+					variable := single_value(arg)
+					if i >= len(b.operands) || b.operands[i] != KindString ||
+						variable == nil || variable.Tok.Rule != parser.Rulevariable {
+						continue
+					}
+					if kind, declared := r.kind_variable(variable.Source()); kind != nil {
+						widen(kind, declared, KindString, arg)
+					}
+				}
+				break
+			}
+			target, ok := r.lookup_routine(name)
+			if !ok {
+				break
+			}
+			for i, arg := range call_arguments(node) {
+				if i < len(target.args) {
+					param := target.vmap._map[target.args[i]]
+					widen(&param.kind, param.declared, r.kind_of(arg), arg)
+				}
+			}
+		}
+		if err != nil {
+			return parser.SEARCH_END
+		}
+		return parser.SEARCH_CONTINUE
+	}, nil)
+	return changed, err
+}
+
+// kind_target returns where the kind of what is assigned to in
+// an assignment or a target of a destructuring is kept, and the
+// type name that declared it, if any. The kind is nil if there
+// is no such variable.
+func (r *Routine) kind_target(node *parser.Node) (*Kind, *parser.Node) {
+	if member := node.Child(parser.Rulemember); member != nil {
+		if f := r.member_field(member); f != nil {
+			return &f.kind, f.declared
+		}
+		return nil, nil
+	}
+	name := node.Child(parser.Rulevariable)
+	if element := node.Child(parser.Ruleelement); element != nil {
+		name = element.Child(parser.Rulevariable)
+	}
+	return r.kind_variable(name.Source())
+}
+
+// kind_variable returns where the kind of the local, captured
+// or global variable called name is kept and the type name that
+// declared it, if any. The kind is nil if there is no such
+// variable.
+func (r *Routine) kind_variable(name string) (*Kind, *parser.Node) {
+	if r.__locals[name] {
+		variable := r.vmap._map[name]
+		return &variable.kind, variable.declared
+	}
+	if kind, declared := r.captured(name); kind != nil {
+		return kind, declared
+	}
+	if g, ok := r.__module.symbols.Get(name).(*Global); ok {
+		return &g.kind, nil
+	}
+	return nil, nil
+}
+
+// kind_of returns the kind of the value of an expression,
+// without generating any code for it.
+func (r *Routine) kind_of(node *parser.Node) Kind {
+	switch node.Tok.Rule {
+	case parser.Ruleexpr, parser.Ruleconjunction, parser.Rulecomparison:
+		if operands := operands(node); len(operands) == 1 {
+			return r.kind_of(operands[0])
+		}
+	case parser.Rulesum, parser.Ruleterm:
+		if operands := operands(node); len(operands) == 1 {
+			return r.kind_of(operands[0])
+		}
+		kind := KindInt
+		for _, child := range node.Children {
+			if operator, ok := binaryOperators[child.Tok.Rule]; ok {
+				if operator.ffold == nil {
+					return KindInt
+				}
+				continue
+			}
+			kind = join(kind, r.kind_of(child))
+		}
+		return kind
+	case parser.Ruleunary:
+		if node.Child(parser.Ruletoksub) != nil {
+			return r.kind_of(node.Child(parser.Ruleunary))
+		}
+		if primary := node.Child(parser.Ruleprimary); primary != nil {
+			return r.kind_of(primary)
+		}
+	case parser.Ruleprimary:
+		if inner := node.Child(parser.Ruleexpr); inner != nil {
+			return r.kind_of(inner)
+		}
+		return r.kind_of(node.Child(parser.Rulevalue))
+	case parser.Rulevalue:
+		return r.kind_of(node.Children[0])
+	case parser.Rulefloat:
+		return KindFloat
+	case parser.Rulestring:
+		return KindString
+	case parser.Rulevariable:
+		if kind, _ := r.kind_variable(node.Source()); kind != nil {
+			return *kind
+		}
+		if c, ok := r.__module.symbols.Get(node.Source()).(*Constant); ok {
+			return c.value.Kind
+		}
+	case parser.Rulemember:
+		if f := r.member_field(node); f != nil {
+			return f.kind
+		}
+	case parser.Ruleelement:
+		if kind, _ := r.kind_variable(node.Child(parser.Rulevariable).Source()); kind != nil {
+			return *kind
+		}
+	case parser.Rulefunccall:
+		name := node.Child(parser.Rulefuncidentifier).Source()
+		if kind, ok := conversions[name]; ok {
+			return kind
+		}
+		if b, ok := builtins[name]; ok {
+			return b.result
+		}
+		if kind, _ := r.kind_variable(name); kind != nil {
+			// called through a reference
+			return KindInt
+		}
+		if target, ok := r.lookup_routine(name); ok {
+			return target.returns[0]
+		}
+	}
+	return KindInt
+}
+
+// join is the kind of the result of an operation on a and b.
+func join(a, b Kind) Kind {
+	if a == KindFloat || b == KindFloat {
+		return KindFloat
+	}
+	return KindInt
+}
+
+// convert returns v as a value of kind. Ints become floats where
+// floats are expected, but floats only become ints through int()
+// and strings become nothing else.
+func convert(r *Routine, v Value, kind Kind, node *parser.Node) (Value, error) {
+	if v.Kind == kind {
+		return v, nil
+	}
+	if v.Kind == KindString || kind == KindString {
+		return Value{}, errorKindMismatch(r, node, v.Kind, kind)
+	}
+	if kind == KindInt {
+		return Value{}, errorFloatAsInt(r, node)
+	}
+	if v.Type == ValueConstant {
+		return Value{Type: ValueConstant, Kind: KindFloat, Float: float64(v.Constant)}, nil
+	}
+	v, err := writable(r, v, node)
+	if err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.ITOF, v.Register)
+	v.Kind = KindFloat
+	return v, nil
+}
+
+// genir_conversion compiles float(x) and int(x).
+func genir_conversion(r *Routine, name string, kind Kind, argnodes parser.NodeArray, node *parser.Node) (Value, error) {
+	if len(argnodes) != 1 {
+		return Value{}, errorArgumentCount(r, name, 1, len(argnodes), node)
+	}
+	v, err := genir_expr(r, argnodes[0])
+	if err != nil || v.Kind == kind {
+		return v, err
+	}
+	if v.Kind == KindString {
+		return Value{}, errorKindMismatch(r, argnodes[0], v.Kind, kind)
+	}
+	if kind == KindFloat {
+		return convert(r, v, kind, node)
+	}
+
+	if v.Type == ValueConstant {
+		f := math.Trunc(v.Float)
+		if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return Value{}, errorFloatConversion(r, v.Float, node)
+		}
+		return Value{Type: ValueConstant, Constant: int(f)}, nil
+	}
+	if v, err = writable(r, v, node); err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.FTOI, v.Register)
+	v.Kind = KindInt
+	return v, nil
+}
+
+// genir_float parses a float literal; see genir_number.
+func genir_float(r *Routine, node *parser.Node) (Value, error) {
+	f, err := strconv.ParseFloat(strings.Replace(node.Source(), "_", "", -1), 64)
+	if err != nil {
+		return Value{}, errorNumberOutOfRange(r, node)
+	}
+	return Value{Type: ValueConstant, Kind: KindFloat, Float: f}, nil
+}
+
+// genir_foperator is genir_operator for floats. Ints are
+// converted first.
+func genir_foperator(r *Routine, node *parser.Node, operator binaryOperator, a, b Value) (Value, error) {
+	if operator.ffold == nil {
+		return Value{}, errorFloatOperand(r, node)
+	}
+	a, err := convert(r, a, KindFloat, node)
+	if err != nil {
+		return Value{}, err
+	}
+	if b, err = convert(r, b, KindFloat, node); err != nil {
+		return Value{}, err
+	}
+	if a.Type == ValueConstant && b.Type == ValueConstant {
+		return Value{Type: ValueConstant, Kind: KindFloat, Float: operator.ffold(a.Float, b.Float)}, nil
+	}
+
+	result, err := writable(r, a, node)
+	if err != nil {
+		return Value{}, err
+	}
+	if b, err = materialize(r, b, node); err != nil {
+		return Value{}, err
+	}
+	r.emit(operator.float, result.Register, b.Register)
+	b.release()
+	return result, nil
+}
+
+func genir_fnegate(r *Routine, v Value, node *parser.Node) (Value, error) {
+	if v.Type == ValueConstant {
+		v.Float = -v.Float
+		return v, nil
+	}
+	result, err := writable(r, v, node)
+	if err != nil {
+		return Value{}, err
+	}
+	r.emit(vm.FNEG, result.Register)
+	return result, nil
+}
+
+// load_float sets dst to f.
+func load_float(r *Routine, dst *Register, f float64) {
+	code := make([]byte, 0, 1+1+8)
+	byteadd(&code, vm.SETF, dst)
+	bits := math.Float64bits(f)
+	for shift := 56; shift >= 0; shift -= 8 {
+		code = append(code, byte(bits>>uint(shift)))
+	}
+	r.__IR.Add(&IRLiteral{code: code})
+}
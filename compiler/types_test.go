@@ -0,0 +1,49 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/hfern/min/vm"
+)
+
+func TestFloats(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int64
+	}{
+		{"int(1.5 + 2.25)", 3},
+		{"int((1.5 + 2.25) * 4.0)", 15},
+		{"int(7.0 / 2.0 * 10.0)", 35},
+		{"int(-2.75)", -2},
+		{"int(1e3)", 1000},
+		{"int(2.5E+1)", 25},
+		{"int(1_000.5)", 1000},
+		{"int(sqrt(2.0) * 1000.0)", 1414},
+		{"int(float(7) / 2.0 * 10.0)", 35},
+		{"int(1.0 + 2)", 3}, // the int is converted
+		{"int(0.1 + 0.2 == 0.3)", 0},
+		{"1.5 < 2.0", 1},
+		{"2.0 > 1.5 && 1.5 >= 1.5", 1},
+		{"-1.5 != 1.5", 1},
+	}
+	for _, test := range tests {
+		if got := run(t, program(test.expr)); got != test.want {
+			t.Errorf("%s gives %d, want %d", test.expr, got, test.want)
+		}
+	}
+
+	src := "routine half<x: float> : float { return x / 2.0; } " +
+		"routine main<> { res f; f = 9.0; f = half(f); return int(f * 10.0); }"
+	if got := run(t, src); got != 45 {
+		t.Errorf("half(9.0) * 10 gives %d, want 45", got)
+	}
+
+	for _, f := range []string{"1e30", "-1e30", "0.0 / 0.0"} {
+		src := "routine main<> { res f; f = " + f + "; return int(f); }"
+		if code := trap(t, src); code != vm.ERRFLOATRANGE {
+			t.Errorf("int(%s) gives code %d, want %d", f, code, vm.ERRFLOATRANGE)
+		}
+	}
+
+	compileError(t, "routine main<> { res x: int; x = 1.5; return x; }", `"1.5" is a float but int is declared`)
+}
@@ -19,6 +19,15 @@ func line_no(text *string, position int) int {
 	return strings.Count((*text)[0:position], "\n") + 1
 }
 
+// column_no is the column of position in its line, counting
+// from 1.
+func column_no(text *string, position int) int {
+	if len(*text) <= position {
+		return -1
+	}
+	return position - strings.LastIndex((*text)[0:position], "\n")
+}
+
 // setL appends a 4 byte integer to the by byte array in 
 // network byte order
 func setL(bytearray *[]byte, number int) {
@@ -54,8 +63,8 @@ func handlepairs(rout *Routine, node *parser.Node, pairs []ruleHandler) error {
 	}
 
 	expected := make([]parser.Rule, 0, len(pairs))
-	for i, pair := range pairs {
-		expected[i] = pair.rule
+	for _, pair := range pairs {
+		expected = append(expected, pair.rule)
 	}
-	return errorExpectingOneOf(node.Tok, &rout.__program.sourcecode, expected)
+	return errorExpectingOneOf(node.Tok, &rout.__module.sourcecode, expected)
 }
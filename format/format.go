@@ -0,0 +1,358 @@
+// Package format implements the canonical formatting of min
+// source code used by min fmt.
+//
+// The formatter walks the parse tree and reprints its tokens:
+// code blocks are indented with one tab per level, binary
+// operators, assignments and comparisons are surrounded by
+// single spaces, lists are separated by ", ", annotations are
+// written "a: int" and routines and structs are separated by
+// one blank line. Comments are kept where they were; single
+// blank lines between statements are kept too. A statement that
+// comments break over several lines is continued one tab further
+// in. Formatting formatted source leaves it unchanged.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/hfern/min/parser"
+)
+
+var (
+	errEmpty  = errors.New("format: empty program")
+	errBroken = errors.New("format: the formatted program does not parse")
+)
+
+// Source formats src in the canonical style. src must be a
+// syntactically valid program. Rather than return code that does
+// not parse, Source fails.
+func Source(src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, errEmpty
+	}
+	tree := &parser.VMTree{Buffer: string(src), KeepTrivia: true}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		return nil, err
+	}
+	root := tree.ParseTree()
+
+	p := &printer{lineStart: true}
+	for _, tok := range tokens(root) {
+		p.token(tok)
+	}
+	p.comments(root.TrailingTrivia())
+	out := p.bytes()
+
+	check := &parser.VMTree{Buffer: string(out)}
+	check.Init()
+	if err := check.Parse(); err != nil {
+		return nil, errBroken
+	}
+	return out, nil
+}
+
+type class int
+
+const (
+	classWord class = iota
+	classKeyword
+	classOperator
+	classUnary
+	classComma
+	classSemicolon
+	classOpenParen
+	classCloseParen
+	classOpenBrace
+	classCloseBrace
+	classOpenAngle
+	classCloseAngle
+	classColon
+)
+
+type token struct {
+	text  string
+	class class
+
+	// the trivia between the previous token and this one
+	trivia []parser.Trivia
+}
+
+// atomic rules are printed as they are written rather than
+// token by token.
+var atomic = map[parser.Rule]bool{
+	parser.Rulenumber:    true,
+	parser.Rulevariable:  true,
+	parser.Rulestring:    true,
+	parser.Rulefloat:     true,
+	parser.Ruletypename:  true,
+	parser.Rulefieldname: true,
+	parser.Rulemodule:    true,
+}
+
+var keywords = map[parser.Rule]bool{
+	parser.Rulekwreserve:  true,
+	parser.Rulekwreturn:   true,
+	parser.Rulekwroutine:  true,
+	parser.Rulekwjump:     true,
+	parser.Rulekwlabel:    true,
+	parser.Rulekwif:       true,
+	parser.Rulekwelse:     true,
+	parser.Rulekwwhile:    true,
+	parser.Rulekwfor:      true,
+	parser.Rulekwbreak:    true,
+	parser.Rulekwcontinue: true,
+	parser.Rulekwconst:    true,
+	parser.Rulekwglobal:   true,
+	parser.Rulekwstruct:   true,
+	parser.Rulekwimport:   true,
+	parser.Rulekwas:       true,
+	parser.Rulekwextern:   true,
+}
+
+var operators = map[string]bool{
+	"=": true, "+": true, "-": true, "*": true, "/": true,
+	"<": true, ">": true, "==": true, "<=": true, ">=": true, "!=": true,
+	"&&": true, "||": true, "%": true, "&": true, "|": true, "^": true,
+	"<<": true, ">>": true,
+}
+
+var punctuation = map[string]class{
+	",": classComma,
+	";": classSemicolon,
+	"(": classOpenParen,
+	")": classCloseParen,
+	"{": classOpenBrace,
+	"}": classCloseBrace,
+	":": classColon,
+}
+
+// tokens flattens the tree into the tokens of the program in
+// source order.
+func tokens(root *parser.Node) []token {
+	toks := make([]token, 0, 64)
+	var trailing []parser.Trivia
+	root.Walk(func(nd *parser.Node) parser.SearchCode {
+		if !atomic[nd.Tok.Rule] && len(nd.Children) > 0 {
+			return parser.SEARCH_CONTINUE
+		}
+		trivia := append(trailing[:len(trailing):len(trailing)], nd.LeadingTrivia()...)
+		toks = append(toks, token{
+			text:   nd.Source(),
+			class:  classify(nd),
+			trivia: trivia,
+		})
+		trailing = nd.TrailingTrivia()
+		return parser.SEARCH_SKIP
+	}, nil)
+	return toks
+}
+
+func classify(nd *parser.Node) class {
+	if keywords[nd.Tok.Rule] {
+		return classKeyword
+	}
+	if atomic[nd.Tok.Rule] {
+		return classWord
+	}
+	text := nd.Source()
+	if nd.Parent() != nil && (nd.Parent().Tok.Rule == parser.Ruleunary || nd.Parent().Tok.Rule == parser.Rulereference) {
+		return classUnary
+	}
+	if nd.Parent() != nil && nd.Parent().Tok.Rule == parser.Ruleparamaterdecl {
+		switch text {
+		case "<":
+			return classOpenAngle
+		case ">":
+			return classCloseAngle
+		}
+	}
+	if operators[text] {
+		return classOperator
+	}
+	if c, ok := punctuation[text]; ok {
+		return c
+	}
+	return classWord
+}
+
+// printer writes tokens and the comments between them.
+type printer struct {
+	out bytes.Buffer
+
+	indent int
+	parens int // open parentheses; ';' inside them ends no statement
+
+	last      class
+	lastText  string
+	space     bool // a space is due before the next token
+	newlines  int  // line breaks due before the next token
+	lineStart bool // nothing has been written on the current line
+	continued bool // a comment broke the current statement
+	commented bool // a comment had a line of its own since the last token
+}
+
+func (p *printer) token(tok token) {
+	if tok.class == classKeyword && (tok.text == "routine" || tok.text == "struct") && p.out.Len() > 0 && p.lastText != "extern" {
+		// routines and structs are set apart from declarations
+		// too, but extern routines are declarations; the blank
+		// line goes before any comments on them
+		p.newlines = 2
+	}
+	p.comments(tok.trivia)
+
+	switch tok.class {
+	case classCloseBrace:
+		p.indent--
+		p.newlines = atLeastOne(p.newlines)
+	case classComma, classSemicolon, classCloseParen, classCloseAngle:
+		p.space = false
+	case classColon:
+		// a: int, but routine f<> : int
+		p.space = p.last == classCloseAngle
+	case classOpenBrace, classOperator:
+		p.space = true
+	case classKeyword:
+		if tok.text == "else" && p.last == classCloseBrace && !hasLineComment(tok.trivia) {
+			p.newlines, p.space = 0, true
+		}
+		if tok.text == "as" {
+			// import "lib/math.min" as m;
+			p.space = true
+		}
+	}
+	if tok.class == classCloseBrace || p.last == classOpenBrace && !p.commented {
+		// no blank lines at either end of a block, though one may
+		// follow the comment it starts with
+		if p.newlines > 1 {
+			p.newlines = 1
+		}
+	}
+
+	p.write(tok.text)
+	p.last, p.lastText, p.commented = tok.class, tok.text, false
+
+	p.space = false
+	switch tok.class {
+	case classOpenBrace:
+		p.indent++
+		p.newlines, p.continued = 1, false
+	case classCloseBrace:
+		p.newlines, p.continued = 1, false
+		if p.indent == 0 {
+			// routines are separated by a blank line
+			p.newlines = 2
+		}
+	case classSemicolon:
+		if p.parens == 0 {
+			p.newlines, p.continued = 1, false
+		} else {
+			p.space = true
+		}
+	case classOpenParen:
+		p.parens++
+	case classCloseParen:
+		p.parens--
+	case classComma, classOperator, classKeyword, classColon:
+		p.space = true
+	}
+}
+
+// write writes text after any line breaks or space due.
+func (p *printer) write(text string) {
+	if p.out.Len() == 0 {
+		p.newlines = 0
+	}
+	if p.newlines > 0 {
+		p.out.WriteString(strings.Repeat("\n", p.newlines))
+		p.newlines, p.lineStart = 0, true
+	}
+	if p.lineStart {
+		p.out.WriteString(strings.Repeat("\t", p.indent))
+		if p.continued {
+			p.out.WriteByte('\t')
+		}
+	} else if p.space {
+		p.out.WriteByte(' ')
+	}
+	p.out.WriteString(text)
+	p.space, p.lineStart = false, false
+}
+
+// comments writes the comments in trivia and keeps single
+// blank lines found at places where a line break is due anyway.
+func (p *printer) comments(trivia []parser.Trivia) {
+	before := ""
+	for i, t := range trivia {
+		if t.Kind == parser.TriviaWhitespace {
+			before += t.Text
+			continue
+		}
+
+		ownLine := strings.Contains(before, "\n") || p.out.Len() == 0
+		breaks := t.Kind == parser.TriviaLineComment || endsLine(trivia[i+1:])
+		newlines := p.newlines
+		if newlines == 0 && p.out.Len() > 0 && (ownLine || breaks) {
+			// no line break is due in the middle of a statement
+			p.continued = true
+		}
+		if ownLine {
+			p.blankLine(before)
+			p.newlines = atLeastOne(p.newlines)
+			newlines = 0
+			p.commented = true
+		} else {
+			// a trailing comment stays on the line it followed
+			p.newlines, p.space = 0, true
+		}
+		p.write(t.Text)
+		before = ""
+
+		if breaks {
+			// the next token starts a new line
+			p.newlines, p.space = atLeastOne(newlines), false
+		} else {
+			p.newlines, p.space = newlines, true
+		}
+	}
+	p.blankLine(before)
+}
+
+// hasLineComment reports whether trivia holds a // comment,
+// which the next token cannot follow on the same line.
+func hasLineComment(trivia []parser.Trivia) bool {
+	for _, t := range trivia {
+		if t.Kind == parser.TriviaLineComment {
+			return true
+		}
+	}
+	return false
+}
+
+// endsLine reports whether trivia starts with a line break.
+func endsLine(trivia []parser.Trivia) bool {
+	return len(trivia) > 0 && trivia[0].Kind == parser.TriviaWhitespace &&
+		strings.HasSuffix(trivia[0].Text, "\n")
+}
+
+// blankLine keeps one blank line if gap holds one and a line
+// break is due before the next token anyway.
+func (p *printer) blankLine(gap string) {
+	if p.newlines > 0 && (p.last != classOpenBrace || p.commented) && strings.Count(gap, "\n") > 1 {
+		p.newlines = 2
+	}
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func (p *printer) bytes() []byte {
+	out := bytes.TrimRight(p.out.Bytes(), " \t\n")
+	return append(out, '\n')
+}
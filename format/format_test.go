@@ -0,0 +1,122 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/hfern/min/parser"
+)
+
+var formatTests = []struct {
+	name string
+	in   string
+	out  string
+}{
+	{
+		"spacing",
+		"routine f<a,b>{res c,d;c=a+b*2;return c;}",
+		"routine f<a, b> {\n\tres c, d;\n\tc = a + b * 2;\n\treturn c;\n}\n",
+	},
+	{
+		"blank lines between routines",
+		"routine f<> { return 1; }\nroutine g<> { return 2; }",
+		"routine f<> {\n\treturn 1;\n}\n\nroutine g<> {\n\treturn 2;\n}\n",
+	},
+	{
+		"annotations",
+		"routine f<a:int,b : float> : int { return a; }",
+		"routine f<a: int, b: float> : int {\n\treturn a;\n}\n",
+	},
+	{
+		"comments",
+		"// f\nroutine f<> {\n/* one */ return 1; // done\n}",
+		"// f\nroutine f<> {\n\t/* one */ return 1; // done\n}\n",
+	},
+	{
+		"blank lines between statements",
+		"routine f<> {\n\n\ta = 1;\n\n\n\tb = 2;\n\n}",
+		"routine f<> {\n\ta = 1;\n\n\tb = 2;\n}\n",
+	},
+	{
+		"else",
+		"routine f<a> { if (a) { a = 1; }\nelse { a = 2; } return a; }",
+		"routine f<a> {\n\tif (a) {\n\t\ta = 1;\n\t} else {\n\t\ta = 2;\n\t}\n\treturn a;\n}\n",
+	},
+	{
+		"line comment before else",
+		"routine f<a> { if (a) { a = 1; } // c\nelse { a = 2; } return a; }",
+		"routine f<a> {\n\tif (a) {\n\t\ta = 1;\n\t} // c\n\telse {\n\t\ta = 2;\n\t}\n\treturn a;\n}\n",
+	},
+	{
+		"block comment before else",
+		"routine f<a> { if (a) { a = 1; } /* c */ else { a = 2; } return a; }",
+		"routine f<a> {\n\tif (a) {\n\t\ta = 1;\n\t} /* c */ else {\n\t\ta = 2;\n\t}\n\treturn a;\n}\n",
+	},
+	{
+		"line comment in arguments",
+		"routine f<a> { a = g(a, // x\n2); return a; }",
+		"routine f<a> {\n\ta = g(a, // x\n\t\t2);\n\treturn a;\n}\n",
+	},
+	{
+		"comment on its own line in an expression",
+		"routine f<a> { a = a +\n// x\n1; return a; }",
+		"routine f<a> {\n\ta = a +\n\t\t// x\n\t\t1;\n\treturn a;\n}\n",
+	},
+	{
+		"blank line after a comment that starts a block",
+		"routine f<a> {\n\n\t// about f\n\n\ta = 1; // one\n\n\treturn a;\n}",
+		"routine f<a> {\n\t// about f\n\n\ta = 1; // one\n\n\treturn a;\n}\n",
+	},
+	{
+		"no blank line after a comment on the brace",
+		"routine f<a> { // about f\n\n\treturn a;\n}",
+		"routine f<a> { // about f\n\treturn a;\n}\n",
+	},
+	{
+		"calls",
+		"routine f<a> { print( a );breakfast(a) ; return a; }",
+		"routine f<a> {\n\tprint(a);\n\tbreakfast(a);\n\treturn a;\n}\n",
+	},
+	{
+		"externs and imports",
+		"import \"lib/m.min\"  as  m;\nextern routine now<>;\nroutine f<> { return m.g(now()); }",
+		"import \"lib/m.min\" as m;\nextern routine now<>;\n\nroutine f<> {\n\treturn m.g(now());\n}\n",
+	},
+}
+
+func TestSource(t *testing.T) {
+	for _, test := range formatTests {
+		out, err := Source([]byte(test.in))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if string(out) != test.out {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, out, test.out)
+		}
+		if !parses(string(out)) {
+			t.Errorf("%s: the output does not parse:\n%s", test.name, out)
+		}
+		again, err := Source(out)
+		if err != nil {
+			t.Errorf("%s: formatting the output: %v", test.name, err)
+			continue
+		}
+		if string(again) != string(out) {
+			t.Errorf("%s: formatting twice gives\n%s\ninstead of\n%s", test.name, again, out)
+		}
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	for _, src := range []string{"", "routine f<> {"} {
+		if _, err := Source([]byte(src)); err == nil {
+			t.Errorf("formatted %q", src)
+		}
+	}
+}
+
+func parses(src string) bool {
+	tree := &parser.VMTree{Buffer: src}
+	tree.Init()
+	return tree.Parse() == nil
+}
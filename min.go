@@ -0,0 +1,149 @@
+// Package min compiles min programs and calls their routines from
+// Go:
+//
+//	prog, err := min.Compile(`routine add<a, b> { return a + b; }`)
+//	if err != nil {
+//		...
+//	}
+//	sum, err := prog.Call("add", 2, 3)
+//
+// Every call runs on a machine of its own, whose globals start out
+// as 0, so a Program that is set up can be called from several
+// goroutines at once. Calls to untrusted code should be bounded
+// with a context and the limits of Program.
+package min
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/hfern/min/compiler"
+	"github.com/hfern/min/parser"
+	"github.com/hfern/min/vm"
+)
+
+var errEmpty = errors.New("min: empty program")
+
+// Program is a compiled min program.
+type Program struct {
+	// The limits of each call, which fails with a *vm.Trap once it
+	// reaches one; see vm.Machine. A limit that is 0 is no limit.
+	MaxInstructions int64
+	MaxStack        int
+	MaxMemory       int64
+	// Stdin and Stdout are the input and output of calls; see
+	// vm.Machine.
+	Stdin  io.Reader
+	Stdout io.Writer
+
+	image *compiler.Image
+	hosts map[string]host
+}
+
+type host struct {
+	arity int
+	fn    vm.HostFunc
+}
+
+// Compile compiles the program in src. It does not need a main
+// routine. It cannot import modules; see CompileFS.
+func Compile(src string) (*Program, error) {
+	return CompileFS(src, nil)
+}
+
+// CompileFS is Compile for a program that imports its modules from
+// fsys: import "lib/m.min"; reads lib/m.min of fsys, which may be
+// os.DirFS(dir). Imports are an error if fsys is nil.
+func CompileFS(src string, fsys fs.FS) (*Program, error) {
+	if src == "" {
+		return nil, errEmpty
+	}
+	tree := &parser.VMTree{Buffer: src}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		return nil, err
+	}
+	tree.ParseTree()
+	cmp := compiler.NewCompiler()
+	cmp.SetTree(tree)
+	cmp.SetSource(src)
+	if fsys != nil {
+		cmp.SetFS(fsys)
+	} else {
+		cmp.DisableImports()
+	}
+	image, err := cmp.CompileImage()
+	if err != nil {
+		return nil, err
+	}
+	return &Program{image: image}, nil
+}
+
+// RegisterHost makes fn the extern routine name of the program for
+// the calls that follow; see vm.Machine.RegisterHost.
+func (p *Program) RegisterHost(name string, arity int, fn vm.HostFunc) {
+	if p.hosts == nil {
+		p.hosts = make(map[string]host)
+	}
+	p.hosts[name] = host{arity, fn}
+}
+
+// Call calls the routine called name with args and returns its
+// result. The routine must take as many ints as there are args
+// and return one int. name is module.routine for the routines of
+// the modules that the program imports, by the name of their file.
+//
+// A routine that calls exit(status) stops there, and Call returns
+// status as its result; it is not an error.
+//
+// If the program traps, the error is a *vm.Trap.
+func (p *Program) Call(name string, args ...int64) (int64, error) {
+	return p.CallContext(context.Background(), name, args...)
+}
+
+// CallContext is Call, but stops the call once ctx is done.
+func (p *Program) CallContext(ctx context.Context, name string, args ...int64) (int64, error) {
+	symbol := name
+	if !strings.Contains(name, ".") {
+		symbol = p.image.Module + "." + name
+	}
+	routine, ok := p.image.Symbols[symbol]
+	if !ok {
+		return 0, fmt.Errorf("min: no routine %q", name)
+	}
+	ints := make([]string, len(args))
+	for i := range ints {
+		ints[i] = "int"
+	}
+	if signature := "(" + strings.Join(ints, ", ") + ") int"; routine.Signature != signature {
+		return 0, fmt.Errorf("min: routine %q is %q, not %q", name, routine.Signature, signature)
+	}
+
+	machine := vm.NewMachine(p.image.Code)
+	machine.MaxInstructions = p.MaxInstructions
+	machine.MaxStack = p.MaxStack
+	machine.MaxMemory = p.MaxMemory
+	if p.Stdin != nil {
+		machine.Stdin = p.Stdin
+	}
+	if p.Stdout != nil {
+		machine.Stdout = p.Stdout
+	}
+	for name, h := range p.hosts {
+		machine.RegisterHost(name, h.arity, h.fn)
+	}
+
+	// load the data and strings first
+	if code := machine.RunContext(ctx); code != vm.ERRDONE {
+		return 0, machine.Trap(code)
+	}
+	result, code := machine.Call(ctx, routine.Offset, p.image.Return, args...)
+	if code != vm.ERRDONE {
+		return 0, machine.Trap(code)
+	}
+	return result, nil
+}
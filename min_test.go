@@ -0,0 +1,249 @@
+package min
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hfern/min/vm"
+)
+
+const limited = `
+routine spin<> {
+	label top;
+	jump top;
+	return 0;
+}
+
+routine down<> {
+	return down() + 1;
+}
+
+routine grow<> {
+	res s;
+	s = "grow";
+	while (1 == 1) {
+		s = concat(s, s);
+	}
+	return length(s);
+}
+
+routine quit<n> {
+	print(n);
+	exit(n + 1);
+	return 0;
+}
+
+routine sum<n> {
+	res total;
+	total = 0;
+	while (n > 0) {
+		total = total + n;
+		n = n - 1;
+	}
+	return total;
+}
+`
+
+func compileLimited(t *testing.T) *Program {
+	t.Helper()
+	prog, err := Compile(limited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prog
+}
+
+// The same loop stops at the same instruction every time.
+func TestInfiniteLoopRunsOutOfFuel(t *testing.T) {
+	prog := compileLimited(t)
+	prog.MaxInstructions = 10000
+
+	var traps []*vm.Trap
+	for i := 0; i < 2; i++ {
+		_, err := prog.Call("spin")
+		if !errors.Is(err, vm.ErrInstructionLimit) {
+			t.Fatalf("spin: got %v, want %v", err, vm.ErrInstructionLimit)
+		}
+		var trap *vm.Trap
+		if !errors.As(err, &trap) {
+			t.Fatalf("spin: %v is not a *vm.Trap", err)
+		}
+		traps = append(traps, trap)
+	}
+	if *traps[0] != *traps[1] {
+		t.Errorf("spin stopped at %d, then at %d", traps[0].PC, traps[1].PC)
+	}
+}
+
+func TestInfiniteLoopMissesDeadline(t *testing.T) {
+	prog := compileLimited(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := prog.CallContext(ctx, "spin")
+	if !errors.Is(err, vm.ErrDeadline) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("spin: got %v, want %v", err, vm.ErrDeadline)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	limits := []error{vm.ErrInstructionLimit, vm.ErrStackLimit, vm.ErrMemoryLimit, vm.ErrCanceled, vm.ErrDeadline}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		routine string
+		ctx     context.Context
+		set     func(p *Program)
+		want    error
+	}{
+		{"spin", nil, func(p *Program) { p.MaxInstructions = 1000 }, vm.ErrInstructionLimit},
+		{"down", nil, func(p *Program) { p.MaxStack = 1000 }, vm.ErrStackLimit},
+		{"grow", nil, func(p *Program) { p.MaxMemory = 1 << 20 }, vm.ErrMemoryLimit},
+		{"spin", canceled, func(p *Program) {}, vm.ErrCanceled},
+	}
+	for _, test := range tests {
+		prog := compileLimited(t)
+		test.set(prog)
+		ctx := test.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		_, err := prog.CallContext(ctx, test.routine)
+		for _, limit := range limits {
+			if errors.Is(err, limit) != (limit == test.want) {
+				t.Errorf("%s: got %v, want %v", test.routine, err, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestWithinLimits(t *testing.T) {
+	prog := compileLimited(t)
+	prog.MaxInstructions = 100000
+	prog.MaxStack = 100
+	prog.MaxMemory = 1 << 10
+
+	got, err := prog.Call("sum", 100)
+	if err != nil || got != 5050 {
+		t.Errorf("sum(100) = %d, %v; want 5050", got, err)
+	}
+}
+
+// Call returns the status that a routine exits with.
+func TestCallExit(t *testing.T) {
+	prog := compileLimited(t)
+	var out bytes.Buffer
+	prog.Stdout = &out
+	result, err := prog.Call("quit", 41)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != 42 || out.String() != "41" {
+		t.Errorf("quit(41) gives %d and prints %q, want 42 and \"41\"", result, out.String())
+	}
+}
+
+func TestCompileEmpty(t *testing.T) {
+	if _, err := Compile(""); err == nil {
+		t.Error("compiling an empty program succeeds")
+	}
+}
+
+// Compile does not read files, whatever the program imports.
+func TestCompileWithoutImports(t *testing.T) {
+	_, err := Compile(`import "/etc/hostname"; routine f<> { return 0; }`)
+	if err == nil || !strings.Contains(err.Error(), "imports are disabled") {
+		t.Errorf("got %v, want imports to be disabled", err)
+	}
+}
+
+func TestCompileFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib/sq.min":  {Data: []byte(`import "two.min"; routine sq<x> { return x * x * two.one(); }`)},
+		"lib/two.min": {Data: []byte(`routine one<> { return 1; }`)},
+		"empty.min":   {Data: []byte{}},
+	}
+	prog, err := CompileFS(`import "lib/sq.min"; routine f<x> { return sq.sq(x); }`, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := prog.Call("f", 7); err != nil || result != 49 {
+		t.Errorf("f(7) gives %d, %v, want 49", result, err)
+	}
+
+	for _, src := range []string{
+		`import "/etc/hostname"; routine f<> { return 0; }`,
+		`import "../lib/sq.min"; routine f<> { return 0; }`,
+		`import "empty.min"; routine f<> { return 0; }`,
+	} {
+		if _, err := CompileFS(src, fsys); err == nil {
+			t.Errorf("%q compiles", src)
+		}
+	}
+}
+
+// A routine with more arguments than there are registers does
+// not compile.
+func TestCompileTooManyArguments(t *testing.T) {
+	names := make([]string, 18)
+	for i := range names {
+		names[i] = string(rune('a' + i))
+	}
+	src := "routine f<" + strings.Join(names, ", ") + "> { return a; }"
+	_, err := Compile(src)
+	if err == nil || !strings.Contains(err.Error(), "Cannot reserve an unused register") {
+		t.Errorf("got %v, want an error for the registers of the arguments", err)
+	}
+}
+
+func TestHosts(t *testing.T) {
+	prog, err := Compile(`extern routine scale<x, y>;
+routine f<x> { return scale(x, 3) + 1; }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := errors.New("failed")
+	prog.RegisterHost("scale", 2, func(args []int64) (int64, error) {
+		if args[0] < 0 {
+			return 0, failed
+		}
+		return args[0] * args[1], nil
+	})
+	if result, err := prog.Call("f", 5); err != nil || result != 16 {
+		t.Errorf("f(5) gives %d, %v, want 16", result, err)
+	}
+
+	_, err = prog.Call("f", -1)
+	var trap *vm.Trap
+	if !errors.As(err, &trap) || trap.Code != vm.ERRHOST || !errors.Is(err, failed) {
+		t.Errorf("f(-1) gives %v, want the error of scale", err)
+	}
+
+	prog.RegisterHost("scale", 1, func(args []int64) (int64, error) { return args[0], nil })
+	if _, err := prog.Call("f", 5); !errors.As(err, &trap) || trap.Code != vm.ERRARITY {
+		t.Errorf("calling scale with 2 arguments for 1 gives %v, want ERRARITY", err)
+	}
+}
+
+func TestHostArguments(t *testing.T) {
+	_, err := Compile(`extern routine scale<x, y>; routine f<x> { return scale(x); }`)
+	if err == nil || !strings.Contains(err.Error(), "scale") {
+		t.Errorf("got %v, want an error for the number of arguments of scale", err)
+	}
+}
+
+// A Program that was not compiled can still register hosts.
+func TestRegisterHostOnZeroProgram(t *testing.T) {
+	var prog Program
+	prog.RegisterHost("now", 0, func([]int64) (int64, error) { return 0, nil })
+	if len(prog.hosts) != 1 {
+		t.Errorf("registered %d hosts", len(prog.hosts))
+	}
+}
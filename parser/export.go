@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ExportNode is the serialisable form of a Node, for tools
+// outside of Go that want to read the tree.
+type ExportNode struct {
+	Rule     string        `json:"rule"`
+	Begin    int           `json:"begin"`
+	End      int           `json:"end"`
+	Line     int           `json:"line"`
+	Column   int           `json:"column"`
+	Text     string        `json:"text"`
+	Children []*ExportNode `json:"children"`
+}
+
+// Export converts the tree rooted at root, which was parsed from
+// source, into its serialisable form. Lines and columns count
+// from 1; columns count bytes.
+func Export(root *Node, source string) *ExportNode {
+	lines := []int{0}
+	for i := 0; i < len(source); i++ {
+		if source[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return export(root, lines)
+}
+
+func export(nd *Node, lines []int) *ExportNode {
+	begin := nd.Tok.Begin()
+	line := sort.SearchInts(lines, begin+1)
+	e := &ExportNode{
+		Rule:     Rul3s[nd.Tok.Rule],
+		Begin:    begin,
+		End:      nd.Tok.End(),
+		Line:     line,
+		Column:   begin - lines[line-1] + 1,
+		Text:     nd.Source(),
+		Children: make([]*ExportNode, len(nd.Children)),
+	}
+	for i, child := range nd.Children {
+		e.Children[i] = export(child, lines)
+	}
+	return e
+}
+
+// JSON returns the tree as indented JSON.
+func (e *ExportNode) JSON() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(e); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Sexp returns the tree as an S-expression, one node per line:
+//
+//	(rule :begin 0 :end 4 :line 1 :column 1 :text "text"
+//	  (child ...))
+func (e *ExportNode) Sexp() []byte {
+	var b bytes.Buffer
+	e.sexp(&b, 0)
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func (e *ExportNode) sexp(b *bytes.Buffer, depth int) {
+	fmt.Fprintf(b, "%s(%s :begin %d :end %d :line %d :column %d :text %s",
+		get_n_spaces(2*depth), e.Rule, e.Begin, e.End, e.Line, e.Column, strconv.Quote(e.Text))
+	for _, child := range e.Children {
+		b.WriteByte('\n')
+		child.sexp(b, depth+1)
+	}
+	b.WriteByte(')')
+}
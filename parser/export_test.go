@@ -0,0 +1,118 @@
+package parser
+
+import "testing"
+
+// find returns the first node in pre-order whose text is text.
+func (e *ExportNode) find(text string) *ExportNode {
+	if e.Text == text {
+		return e
+	}
+	for _, child := range e.Children {
+		if found := child.find(text); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Columns count bytes, and a carriage return belongs to the line
+// it ends.
+func TestExportPositions(t *testing.T) {
+	tests := []struct {
+		src          string
+		text         string
+		line, column int
+	}{
+		{"const N = 2;", "N", 1, 7},
+		{"const N = 2;\nconst M = 3;\n", "M", 2, 7},
+		{"// é\nconst N = 2;\n", "N", 2, 7},
+		{"// é\r\nconst N = 2;\r\n", "N", 2, 7},
+		{`routine main<> { prints("é"); return 0; }`, "return", 1, 32},
+		{"routine main<> {\r\n\treturn 0;\r\n}\r\n", "return", 2, 2},
+		{"routine main<> {\r\n\treturn 0;\r\n}\r\n", "}", 3, 1},
+		{"\r\n\r\nconst N = 2;", "const", 3, 1},
+	}
+	for _, test := range tests {
+		exported := Export(parse(t, test.src, false), test.src)
+		found := exported.find(test.text)
+		if found == nil {
+			t.Errorf("%q exports no node for %q", test.src, test.text)
+			continue
+		}
+		if found.Line != test.line || found.Column != test.column {
+			t.Errorf("%q in %q is at %d:%d, want %d:%d",
+				test.text, test.src, found.Line, found.Column, test.line, test.column)
+		}
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	src := "routine main<> { return 0; }"
+	root := parse(t, src, false)
+	out, err := Export(root.GetNodeByRule(Ruleparamaterdecl), src).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "rule": "paramaterdecl",
+  "begin": 12,
+  "end": 14,
+  "line": 1,
+  "column": 13,
+  "text": "<>",
+  "children": [
+    {
+      "rule": "Pre_",
+      "begin": 12,
+      "end": 13,
+      "line": 1,
+      "column": 13,
+      "text": "<",
+      "children": []
+    },
+    {
+      "rule": "_Suf",
+      "begin": 13,
+      "end": 14,
+      "line": 1,
+      "column": 14,
+      "text": ">",
+      "children": []
+    }
+  ]
+}
+`
+	if string(out) != want {
+		t.Errorf("exported\n%s\ninstead of\n%s", out, want)
+	}
+}
+
+func TestExportSexp(t *testing.T) {
+	tests := []struct {
+		src  string
+		rule Rule
+		want string
+	}{
+		{
+			`routine main<> { prints("é"); return 0; }`, Rulefuncidentifier,
+			`(funcidentifier :begin 17 :end 23 :line 1 :column 18 :text "prints"
+  (variable :begin 17 :end 23 :line 1 :column 18 :text "prints"))
+`,
+		},
+		{
+			"routine main<> {\r\n\tprints(\"é\\t\");\r\n\treturn 0;\r\n}", Rulestring,
+			`(string :begin 26 :end 32 :line 2 :column 9 :text "\"é\\t\""
+  (Pre_ :begin 26 :end 29 :line 2 :column 9 :text "\"é")
+  (escape :begin 29 :end 31 :line 2 :column 12 :text "\\t")
+  (_Suf :begin 31 :end 32 :line 2 :column 14 :text "\""))
+`,
+		},
+	}
+	for _, test := range tests {
+		root := parse(t, test.src, false)
+		out := Export(root.GetNodeByRule(test.rule), test.src).Sexp()
+		if string(out) != test.want {
+			t.Errorf("%q exported\n%s\ninstead of\n%s", test.src, out, test.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"sort"
+)
+
+// ruleIndex lists the nodes of a tree by rule, each list in
+// pre-order. It is built once per parse so that GetNodesByRule
+// and GetNodeByRule do not have to walk the tree.
+//
+// Every node in the tree numbers itself in pre-order and
+// remembers the largest number within its subtree, so the
+// descendants of any node of a given rule are one contiguous
+// run of that rule's list.
+type ruleIndex struct {
+	root  *Node
+	rules [len(Rul3s)]NodeArray
+	stale bool
+}
+
+func newRuleIndex(root *Node) *ruleIndex {
+	idx := &ruleIndex{root: root}
+	idx.build()
+	return idx
+}
+
+func (idx *ruleIndex) build() {
+	// Searches hand out slices of the old lists, so they
+	// are replaced rather than reused.
+	idx.rules = [len(Rul3s)]NodeArray{}
+	order := 0
+	idx.root.Walk(func(nd *Node) SearchCode {
+		nd.index = idx
+		nd.order = order
+		order++
+		idx.rules[nd.Tok.Rule] = append(idx.rules[nd.Tok.Rule], nd)
+		return SEARCH_CONTINUE
+	}, func(nd *Node) SearchCode {
+		nd.last = order - 1
+		return SEARCH_CONTINUE
+	})
+	idx.stale = false
+}
+
+// invalidate marks the index out of date after the tree was
+// edited. It is rebuilt by the next search.
+func (idx *ruleIndex) invalidate() {
+	idx.stale = true
+}
+
+// search returns the descendants of root that match r. The
+// result shares memory with the index and must not be
+// appended to in place; its capacity is clipped to make that
+// safe.
+func (idx *ruleIndex) search(root *Node, r Rule, onlyone bool) NodeArray {
+	if idx.stale {
+		idx.build()
+	}
+	nodes := idx.rules[r]
+	lo := sort.Search(len(nodes), func(i int) bool {
+		return nodes[i].order > root.order
+	})
+	hi := sort.Search(len(nodes), func(i int) bool {
+		return nodes[i].order > root.last
+	})
+	if onlyone && hi > lo {
+		hi = lo + 1
+	}
+	return nodes[lo:hi:hi]
+}
+
+// searchOutside is search leaving out the nodes below a node of
+// rule outside. The outermost of those nodes cover disjoint runs
+// of the pre-order, so both lists are read once.
+func (idx *ruleIndex) searchOutside(root *Node, r, outside Rule) NodeArray {
+	nodes := idx.search(root, r, false)
+	fences := idx.search(root, outside, false)
+	if len(fences) == 0 {
+		return nodes
+	}
+	found := make(NodeArray, 0, len(nodes))
+	var fence *Node
+	for _, nd := range nodes {
+		for len(fences) > 0 && fences[0].order < nd.order {
+			if fence == nil || fences[0].order > fence.last {
+				fence = fences[0]
+			}
+			fences = fences[1:]
+		}
+		if fence == nil || nd.order > fence.last {
+			found = append(found, nd)
+		}
+	}
+	return found
+}
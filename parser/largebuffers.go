@@ -0,0 +1,47 @@
+//go:build ignore
+// +build ignore
+
+// largebuffers patches the Init of the parser generated by peg,
+// which always starts with a token16 tree whose int16 positions
+// wrap past 32767 bytes, to start with a token32 tree for buffers
+// that long. Run it after peg; see go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+const file = "vm.peg.go"
+
+var (
+	generated = []byte("\tvar tree TokenTree = &tokens16{tree: make([]token16, math.MaxInt16)}\n")
+	patch     = []byte("\tif len(p.Buffer) > math.MaxInt16 {\n" +
+		"\t\t// token16 cannot hold positions this far into the buffer\n" +
+		"\t\ttree = &tokens32{tree: make([]token32, math.MaxInt16)}\n" +
+		"\t}\n")
+)
+
+func main() {
+	source, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "largebuffers:", err)
+		os.Exit(1)
+	}
+	at := bytes.Index(source, generated)
+	if at < 0 {
+		fmt.Fprintln(os.Stderr, "largebuffers: the tree of Init is not in", file)
+		os.Exit(1)
+	}
+	at += len(generated)
+	if bytes.HasPrefix(source[at:], patch) {
+		return
+	}
+	patched := append(append(append([]byte(nil), source[:at]...), patch...), source[at:]...)
+	if err := ioutil.WriteFile(file, patched, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "largebuffers:", err)
+		os.Exit(1)
+	}
+}
@@ -10,34 +10,12 @@ import (
 var pause_time time.Duration
 
 var flag_vvv *bool = flag.Bool("prs-vvv", false, "Very, Very Verbose parser logging.")
-var flag_hits *bool = flag.Bool("prs-hits", false, "Log parser branches.")
 var flag_logrecursion *bool = flag.Bool("prs-rec", false, "Log parser recursion.")
 var flag_tokenmap *bool = flag.Bool("prs-tkmp", false, "Whitespace token map.")
 
-func logPoolAccess(pool *tokenpool, n int, ok bool) {
-	if *flag_vvv {
-		if ok {
-			log.Println("[CMP]TokPool: Access to ", n, "OK.")
-		} else {
-			log.Println("[CMP]TokPool: Access to ", n, "failed.")
-		}
-	}
-}
-
-func logParseRecursion(tok *State16) {
+func logParseRecursion(tok token32) {
 	if *flag_logrecursion || *flag_vvv {
-		log.Printf("Descent: %s (%d): %d", Rul3s[tok.Rule], tok.Rule, tok.next)
-	}
-}
-
-func logHitChild(pool *tokenpool, parent, child int16) {
-	if *flag_hits || *flag_vvv {
-		log.Printf("Child Hit: %d NEXT ( %d<%d )", pool.index, parent, child)
-	}
-}
-func logHitElse(pool *tokenpool) {
-	if *flag_hits || *flag_vvv {
-		log.Println("Else Hit:", pool.index)
+		log.Printf("Token: %s (%d): %d", Rul3s[tok.Rule], tok.Rule, tok.next)
 	}
 }
 
@@ -47,8 +25,15 @@ func doRecursionPause() {
 	}
 }
 
-func logRecursionHead(n int, d *Node) {
-	if *flag_tokenmap {
-		fmt.Printf("%s%d %s\n", get_n_spaces(n), n, Rul3s[d.Tok.Rule])
+// logTokenMap prints the rule of every node in a freshly
+// built tree, indented by its depth.
+func logTokenMap(root *Node) {
+	if !*flag_tokenmap {
+		return
 	}
+	root.Walk(func(nd *Node) SearchCode {
+		n := int(nd.Tok.next) + 1
+		fmt.Printf("%s%d %s\n", get_n_spaces(n), n, Rul3s[nd.Tok.Rule])
+		return SEARCH_CONTINUE
+	}, nil)
 }
@@ -13,13 +13,22 @@ const (
 	SEARCH_CONTINUE SearchCode = 1 << iota
 	SEARCH_END
 	SEARCH_ADD
+	SEARCH_SKIP
 )
 
 type Node struct {
-	Tok      State16
+	Tok      State32
 	Children []*Node
 	parent   *Node
 	source   string
+
+	// Whitespace and comments around a leaf; see Trivia.
+	Leading, Trailing []Trivia
+
+	// position of the node in its tree's rule index; see
+	// ruleIndex.
+	index       *ruleIndex
+	order, last int
 }
 
 type NodeArray []*Node
@@ -48,7 +57,6 @@ func (root *Node) recursivePrintChildren(identlevel int) {
 func (root *Node) PrintTree() {
 	fmt.Printf("[%s] => \n", Rul3s[root.Tok.Rule])
 	root.recursivePrintChildren(1)
-	fmt.Println(len(root.Children[1].Children))
 }
 
 func (root *Node) EachNode(fn SearchFunction) {
@@ -81,44 +89,22 @@ func (root *Node) _executeEach(fn SearchFunction) SearchCode {
 	return SEARCH_CONTINUE
 }
 
-// TODO make this use channels for appending to 
-// the results array. Removes block that would allow
-// _executeEach to run on multiple goroutines
 func (root *Node) _performTreeSearch(r Rule, onlyone bool) NodeArray {
-	results := make(NodeArray, 0, 5)
-
-	results_channel := make(chan *Node)
-	done_channel := make(chan bool)
-
-	// Syncs access to results. This allows making a 
-	// multithreaded EachNode.
-	go func() {
-		for {
-			select {
-			case nd := <-results_channel:
-				results = append(results, nd)
-				break
-			case <-done_channel:
-				return
-				break
-			}
-		}
-		return
-	}()
+	if root.index != nil {
+		return root.index.search(root, r, onlyone)
+	}
 
+	// Trees that were not built by ParseTree have no index.
+	results := make(NodeArray, 0, 5)
 	root.EachNode(func(n *Node) SearchCode {
 		if n.Tok.Rule == r {
-			results_channel <- n
+			results = append(results, n)
 			if onlyone {
 				return SEARCH_END
 			}
 		}
 		return SEARCH_CONTINUE
 	})
-
-	done_channel <- true // signal end of search
-	close(done_channel)
-	close(results_channel)
 	return results
 }
 
@@ -126,6 +112,28 @@ func (root *Node) GetNodesByRule(r Rule) NodeArray {
 	return root._performTreeSearch(r, false)
 }
 
+// GetNodesByRuleOutside is GetNodesByRule leaving out the nodes
+// that are below a node of rule outside.
+func (root *Node) GetNodesByRuleOutside(r, outside Rule) NodeArray {
+	if root.index != nil {
+		return root.index.searchOutside(root, r, outside)
+	}
+	results := make(NodeArray, 0, 5)
+	root.Walk(func(n *Node) SearchCode {
+		if n == root {
+			return SEARCH_CONTINUE
+		}
+		if n.Tok.Rule == r {
+			results = append(results, n)
+		}
+		if n.Tok.Rule == outside {
+			return SEARCH_SKIP
+		}
+		return SEARCH_CONTINUE
+	}, nil)
+	return results
+}
+
 func (root *Node) GetNodeByRule(r Rule) *Node {
 	matching_nodes := root._performTreeSearch(r, true)
 	if len(matching_nodes) == 0 {
@@ -1,42 +1,154 @@
 package parser
 
-import ()
+// vm.peg.go is generated by peg, then patched by largebuffers.go
+// so that Init records the positions of buffers longer than
+// token16 can hold as token32s.
+//go:generate peg vm.peg
+//go:generate go run largebuffers.go
 
-func (p *VMTree) ParseTree() *Node {
-	tree := p.TokenTree.(*tokens16)
+import (
+	"strings"
+)
 
-	// unpack from channel to sliding frame
-	tchan, _ := tree.PreOrder()
-	pool := tokenpool{}
-	pool.Enumerate(tchan, true) // skip spaces
+// ParseTree builds the abstract syntax tree from the tokens of
+// the last successful Parse. The tree is rooted at the program
+// node and has whitespace and comments removed, unless
+// KeepTrivia is set, in which case they are attached to the
+// leaves as trivia.
+func (p *VMTree) ParseTree() *Node {
+	p.ASTTree = *buildTree(p.tokens(), &p.Buffer)
+	root := &p.ASTTree
+	for _, child := range root.Children {
+		child.parent = root
+	}
+	if p.KeepTrivia {
+		attachTrivia(root, strings.TrimSuffix(p.Buffer, string(END_SYMBOL)))
+	}
+	logTokenMap(root)
+	newRuleIndex(root)
+	return root
+}
 
-	root := newNode()
-	root.Tok.next = -1 // base tokens start at 0
-	_recursiveBranch(&root, &pool, &p.Buffer, 0)
+// tokens returns the parsed tokens as token32s whichever
+// representation the parser ended up using.
+func (p *VMTree) tokens() []token32 {
+	switch tree := p.TokenTree.(type) {
+	case *tokens32:
+		return tree.tree
+	case *tokens16:
+		tokens := make([]token32, len(tree.tree))
+		for i := range tree.tree {
+			tokens[i] = tree.tree[i].GetToken32()
+		}
+		return tokens
+	}
+	return nil
+}
 
-	p.ASTTree = root
-	return &p.ASTTree
+// pending is a token that has been read but not yet adopted
+// by its parent. node is nil for whitespace, which is dropped
+// from the tree once its parent has measured the gaps between
+// its children.
+type pending struct {
+	tok  token32
+	node *Node
 }
 
-func _recursiveBranch(root *Node, pool *tokenpool, sourcecode *string, level int) {
-	level++
-	logRecursionHead(level, root)
-	for tok := pool.Next(); tok != nil; tok = pool.Next() {
+// buildTree assembles tokens into a tree and returns its root.
+// peg stores tokens in post-order with the depth of each token
+// in its next field, so every token adopts the deeper tokens
+// pending on the stack as its children.
+//
+// Text between a node's children that no token covers, such as
+// punctuation, becomes a Pre_, _In_ or _Suf leaf.
+func buildTree(tokens []token32, sourcecode *string) *Node {
+	slab := nodeSlab{}
+	stack := make([]pending, 0, 32)
+
+	for _, tok := range tokens {
 		logParseRecursion(tok)
 		doRecursionPause()
 
-		if tok.next > root.Tok.next {
-			logHitChild(pool, root.Tok.next, tok.next)
-			child := newNodeT(*tok)
-			child.bindSource(sourcecode)
-			root.addChild(&child)
-			_recursiveBranch(&child, pool, sourcecode, level)
-		} else {
-			//logHitElse(pool)
-			// Oops. A token that we shouldn't have
-			// consumed
-			pool.Back()
-			return
+		first := len(stack)
+		for first > 0 && stack[first-1].tok.next > tok.next {
+			first--
+		}
+		adopted := stack[first:]
+		stack = stack[:first]
+
+		if is_whitespace(tok.Rule) {
+			// drop everything inside the whitespace
+			stack = append(stack, pending{tok: tok})
+			continue
 		}
+
+		nd := slab.node(tok, sourcecode)
+		if len(adopted) > 0 {
+			nd.Children = slab.children(2*len(adopted) + 1)
+			position := tok.begin
+			gap := RulePre_
+			for _, child := range adopted {
+				if position < child.tok.begin {
+					nd.addChild(slab.gap(gap, position, child.tok.begin, tok.next+1, sourcecode))
+				}
+				if child.node != nil {
+					nd.addChild(child.node)
+				}
+				position, gap = child.tok.end, Rule_In_
+			}
+			if position < tok.end {
+				nd.addChild(slab.gap(Rule_Suf, position, tok.end, tok.next+1, sourcecode))
+			}
+		}
+		nd.Tok.leaf = len(nd.Children) == 0
+
+		stack = append(stack, pending{tok: tok, node: nd})
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].node != nil {
+			return stack[i].node
+		}
+	}
+	root := newNode()
+	return &root
+}
+
+// nodeSlab hands out nodes and children arrays from shared
+// chunks so that building a tree costs a handful of
+// allocations rather than a few per node.
+type nodeSlab struct {
+	nodes    []Node
+	pointers []*Node
+}
+
+const slabSize = 1024
+
+func (s *nodeSlab) node(tok token32, sourcecode *string) *Node {
+	if len(s.nodes) == cap(s.nodes) {
+		s.nodes = make([]Node, 0, slabSize)
+	}
+	s.nodes = append(s.nodes, Node{Tok: State32{token32: tok}})
+	nd := &s.nodes[len(s.nodes)-1]
+	nd.bindSource(sourcecode)
+	return nd
+}
+
+func (s *nodeSlab) gap(r Rule, begin, end, depth int32, sourcecode *string) *Node {
+	return s.node(token32{Rule: r, begin: begin, end: end, next: depth}, sourcecode)
+}
+
+// children returns an empty array with room for n children.
+// Its capacity is clipped so that appending past n reallocates
+// instead of overwriting a neighbour's children.
+func (s *nodeSlab) children(n int) []*Node {
+	if n > slabSize {
+		return make([]*Node, 0, n)
+	}
+	if cap(s.pointers)-len(s.pointers) < n {
+		s.pointers = make([]*Node, 0, slabSize)
 	}
+	start := len(s.pointers)
+	s.pointers = s.pointers[:start+n]
+	return s.pointers[start : start : start+n]
 }
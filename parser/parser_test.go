@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, src string, trivia bool) *Node {
+	t.Helper()
+	tree := &VMTree{Buffer: src, KeepTrivia: trivia}
+	tree.Init()
+	if err := tree.Parse(); err != nil {
+		t.Fatalf("%q does not parse: %v", src, err)
+	}
+	return tree.ParseTree()
+}
+
+// Past 32767 bytes the tokens must be token32s, which Init only
+// picks once largebuffers.go has patched it; see parser.go.
+func TestLargeBuffer(t *testing.T) {
+	// a long comment, so that the positions outgrow token16 long
+	// before the number of tokens does
+	src := "// " + strings.Repeat("x", 2*math.MaxInt16) + "\n"
+	for i := 0; i < 10; i++ {
+		src += fmt.Sprintf("routine r%d<a> { return a + %d; }\n", i, i)
+	}
+	root := parse(t, src, false)
+	routines := root.GetNodesByRule(Ruleroutine)
+	last := routines[len(routines)-1]
+	if begin := strings.LastIndex(src, "routine"); last.Tok.Begin() != begin {
+		t.Fatalf("last routine begins at %d, want %d", last.Tok.Begin(), begin)
+	}
+	if !strings.HasPrefix(last.Source(), "routine r") {
+		t.Errorf("last routine is %q", last.Source())
+	}
+}
+
+func TestNodesOutside(t *testing.T) {
+	src := "routine main<a> { res b; routine f<c> { res d; routine g<> { res e; } } res h; return 0; }"
+	root := parse(t, src, false)
+	block := root.GetNodeByRule(Ruleroutine).Child(Rulecodeblock)
+	got := []string{}
+	for _, nd := range block.GetNodesByRuleOutside(Rulereserved, Ruleroutine) {
+		got = append(got, nd.Source())
+	}
+	if want := []string{"b", "h"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("reserved outside the nested routines are %q, want %q", got, want)
+	}
+	if routines := block.GetNodesByRuleOutside(Ruleroutine, Ruleroutine); len(routines) != 1 {
+		t.Errorf("%d routines outside the nested routines, want only f", len(routines))
+	}
+}
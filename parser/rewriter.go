@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errNodeAttached = errors.New("parser: node is already attached to a tree")
+	errNodeDetached = errors.New("parser: node is not attached to a tree")
+)
+
+// Rewriter edits a parse tree in place. After every edit the
+// parent links, token spans and bound source text of all nodes
+// in the tree agree with the rewritten source, which is
+// available from Source.
+//
+// Nodes handed to a Rewriter for insertion must be detached:
+// built with NewNode or ParseFragment, or previously removed
+// from a tree with Delete or Replace. Their spans are relative
+// to their own text and are rebased when they are spliced in.
+//
+// Trivia that an edit leaves in the source stays attached to
+// the tree: a replacement takes over the trivia around the node
+// it replaces, a node inserted before or after a sibling takes
+// over the trivia on that side of it, and the trivia around a
+// deleted node is kept by the leaf before it.
+type Rewriter struct {
+	root   *Node
+	source string
+}
+
+// NewRewriter returns a Rewriter for the tree rooted at root,
+// which was parsed from source.
+func NewRewriter(root *Node, source string) *Rewriter {
+	return &Rewriter{root: root, source: source}
+}
+
+// Source returns the rewritten source code.
+func (rw *Rewriter) Source() string {
+	return rw.source
+}
+
+// Replace swaps old for n. old is detached and returned, less
+// its leading and trailing trivia, so that it may be inserted
+// elsewhere.
+func (rw *Rewriter) Replace(old, n *Node) (*Node, error) {
+	parent := old.parent
+	if parent == nil {
+		return nil, errNodeDetached
+	}
+	if n.parent != nil {
+		return nil, errNodeAttached
+	}
+	index := old.ChildIndex()
+	parent.Children[index] = n
+	n.parent = parent
+	detach(old)
+	moveLeading(old, n)
+	moveTrailing(old, n)
+
+	rw.splice(parent, old.Tok.Begin(), old.Tok.End(), n)
+	return old, nil
+}
+
+// InsertBefore inserts n as the older sibling of sibling.
+func (rw *Rewriter) InsertBefore(sibling, n *Node) error {
+	return rw.insert(sibling, n, 0)
+}
+
+// InsertAfter inserts n as the younger sibling of sibling.
+func (rw *Rewriter) InsertAfter(sibling, n *Node) error {
+	return rw.insert(sibling, n, 1)
+}
+
+func (rw *Rewriter) insert(sibling, n *Node, offset int) error {
+	parent := sibling.parent
+	if parent == nil {
+		return errNodeDetached
+	}
+	if n.parent != nil {
+		return errNodeAttached
+	}
+	position := sibling.Tok.Begin()
+	if offset == 1 {
+		position = sibling.Tok.End()
+	}
+	index := sibling.ChildIndex() + offset
+
+	parent.Children = append(parent.Children, nil)
+	copy(parent.Children[index+1:], parent.Children[index:])
+	parent.Children[index] = n
+	n.parent = parent
+	if offset == 0 {
+		moveLeading(sibling, n)
+	} else {
+		moveTrailing(sibling, n)
+	}
+
+	rw.splice(parent, position, position, n)
+	return nil
+}
+
+// Delete removes n, and the source text it spans, from the
+// tree. The detached node is returned so that it may be
+// inserted elsewhere.
+func (rw *Rewriter) Delete(n *Node) (*Node, error) {
+	parent := n.parent
+	if parent == nil {
+		return nil, errNodeDetached
+	}
+	rw.keepTrivia(n)
+	index := n.ChildIndex()
+	parent.Children = append(parent.Children[:index], parent.Children[index+1:]...)
+	detach(n)
+
+	rw.splice(parent, n.Tok.Begin(), n.Tok.End(), nil)
+	return n, nil
+}
+
+// splice replaces source[begin:end] with the text of inserted
+// (or nothing when inserted is nil) and brings every span in
+// the tree up to date. parent is the node the edit was made in.
+func (rw *Rewriter) splice(parent *Node, begin, end int, inserted *Node) {
+	text := ""
+	if inserted != nil {
+		text = inserted.Source()
+	}
+	delta := len(text) - (end - begin)
+	rw.source = rw.source[:begin] + text + rw.source[end:]
+
+	ancestors := make(map[*Node]bool)
+	for p := parent; p != nil; p = p.parent {
+		ancestors[p] = true
+	}
+
+	if inserted != nil {
+		origin := inserted.Tok.Begin()
+		inserted.Walk(func(nd *Node) SearchCode {
+			nd.setSpan(nd.Tok.Begin()-origin+begin, nd.Tok.End()-origin+begin)
+			nd.Tok.next = nd.parent.Tok.next + 1
+			return SEARCH_CONTINUE
+		}, nil)
+	}
+
+	rw.root.Walk(func(nd *Node) SearchCode {
+		switch {
+		case nd == inserted:
+			// already rebased
+			return SEARCH_SKIP
+		case ancestors[nd]:
+			nd.setSpan(nd.Tok.Begin(), nd.Tok.End()+delta)
+		case nd.Tok.Begin() >= end:
+			nd.setSpan(nd.Tok.Begin()+delta, nd.Tok.End()+delta)
+		}
+		return SEARCH_CONTINUE
+	}, func(nd *Node) SearchCode {
+		nd.bindSource(&rw.source)
+		return SEARCH_CONTINUE
+	})
+
+	if rw.root.index != nil {
+		rw.root.index.invalidate()
+	}
+}
+
+// detach cuts n loose from the tree it was removed from.
+func detach(n *Node) {
+	n.parent = nil
+	n.Walk(func(nd *Node) SearchCode {
+		nd.index = nil
+		return SEARCH_CONTINUE
+	}, nil)
+}
+
+// moveLeading moves the leading trivia of from to the front of
+// the leading trivia of to.
+func moveLeading(from, to *Node) {
+	src, dst := from.firstLeaf(), to.firstLeaf()
+	if len(src.Leading) == 0 {
+		return
+	}
+	dst.Leading = append(src.Leading[:len(src.Leading):len(src.Leading)], dst.Leading...)
+	src.Leading = nil
+}
+
+// moveTrailing moves the trailing trivia of from to the end of
+// the trailing trivia of to.
+func moveTrailing(from, to *Node) {
+	src, dst := from.lastLeaf(), to.lastLeaf()
+	if len(src.Trailing) == 0 {
+		return
+	}
+	dst.Trailing = append(dst.Trailing[:len(dst.Trailing):len(dst.Trailing)], src.Trailing...)
+	src.Trailing = nil
+}
+
+// keepTrivia hands the trivia around n, which is about to be
+// deleted, to the leaf before it, or the leaf after it if n is
+// the first in the tree.
+func (rw *Rewriter) keepTrivia(n *Node) {
+	first, last := n.firstLeaf(), n.lastLeaf()
+	if len(first.Leading) == 0 && len(last.Trailing) == 0 {
+		return
+	}
+	var before, after *Node
+	passed := false
+	rw.root.Walk(func(nd *Node) SearchCode {
+		switch {
+		case nd == n:
+			passed = true
+			return SEARCH_SKIP
+		case len(nd.Children) > 0:
+			return SEARCH_CONTINUE
+		case !passed:
+			before = nd
+			return SEARCH_SKIP
+		}
+		after = nd
+		return SEARCH_END
+	}, nil)
+
+	trivia := append(first.Leading[:len(first.Leading):len(first.Leading)], last.Trailing...)
+	first.Leading, last.Trailing = nil, nil
+	switch {
+	case before != nil:
+		before.Trailing = append(before.Trailing[:len(before.Trailing):len(before.Trailing)], trivia...)
+	case after != nil:
+		after.Leading = append(trivia, after.Leading...)
+	}
+}
+
+// NewNode returns a detached leaf node for rule r whose source
+// is text.
+func NewNode(r Rule, text string) *Node {
+	n := newNode()
+	n.Tok.Rule = r
+	n.setSpan(0, len(text))
+	n.source = text
+	return &n
+}
+
+// ParseFragment parses text as a single instance of rule r and
+// returns the resulting detached subtree. The whole of text
+// must be matched by the rule. Whitespace and comments within
+// the fragment are kept as trivia.
+func ParseFragment(r Rule, text string) (*Node, error) {
+	if text == "" {
+		return nil, fmt.Errorf("parser: empty %s fragment", Rul3s[r])
+	}
+	tree := &VMTree{Buffer: text}
+	tree.Init()
+	if err := tree.Parse(int(r)); err != nil {
+		return nil, err
+	}
+
+	fragment := buildTree(tree.tokens(), &tree.Buffer)
+	if fragment.Tok.Begin() != 0 || fragment.Tok.End() != len(text) {
+		return nil, fmt.Errorf("parser: %q is not a single %s", text, Rul3s[r])
+	}
+	attachTrivia(fragment, text)
+	return fragment, nil
+}
+
+func (root *Node) setSpan(begin, end int) {
+	root.Tok.begin, root.Tok.end = int32(begin), int32(end)
+}
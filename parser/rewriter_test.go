@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+// checkTree fails unless every node of the tree under root points
+// at its parent and spans its children and its own text in source,
+// and the tree prints as source.
+func checkTree(t *testing.T, root *Node, source string) {
+	t.Helper()
+	root.Walk(func(nd *Node) SearchCode {
+		begin, end := nd.Tok.Begin(), nd.Tok.End()
+		if begin < 0 || begin > end || end > len(source) {
+			t.Fatalf("%s spans %d:%d of %d bytes", Rul3s[nd.Tok.Rule], begin, end, len(source))
+		}
+		if nd.Source() != source[begin:end] {
+			t.Errorf("%s is %q, not %q", Rul3s[nd.Tok.Rule], nd.Source(), source[begin:end])
+		}
+		position := begin
+		for _, child := range nd.Children {
+			if child.Parent() != nd {
+				t.Errorf("%s is not the parent of its child %s", Rul3s[nd.Tok.Rule], Rul3s[child.Tok.Rule])
+			}
+			if child.Tok.Begin() < position || child.Tok.End() > end {
+				t.Errorf("%s at %d:%d is out of place in %s at %d:%d", Rul3s[child.Tok.Rule],
+					child.Tok.Begin(), child.Tok.End(), Rul3s[nd.Tok.Rule], begin, end)
+			}
+			position = child.Tok.End()
+		}
+		return SEARCH_CONTINUE
+	}, nil)
+
+	var out bytes.Buffer
+	if err := root.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != source {
+		t.Errorf("the tree prints as\n%q\ninstead of\n%q", out.String(), source)
+	}
+}
+
+// checkReparse fails unless source parses into the rules that the
+// rewritten tree under root has.
+func checkReparse(t *testing.T, root *Node, source string) {
+	t.Helper()
+	fresh := parse(t, source, true)
+	var got, want []Rule
+	root.EachNode(func(nd *Node) SearchCode {
+		got = append(got, nd.Tok.Rule)
+		return SEARCH_CONTINUE
+	})
+	fresh.EachNode(func(nd *Node) SearchCode {
+		want = append(want, nd.Tok.Rule)
+		return SEARCH_CONTINUE
+	})
+	if len(got) != len(want) {
+		t.Fatalf("the rewritten tree has %d nodes, %q parses into %d", len(got), source, len(want))
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("node %d is %s, not %s", i, Rul3s[got[i]], Rul3s[want[i]])
+		}
+	}
+}
+
+const rewritten = "routine main<> {\n\tres a; // a\n\ta = 1;\n\treturn a + 1;\n}\n"
+
+func TestRewriterReplace(t *testing.T) {
+	root := parse(t, rewritten, true)
+	rw := NewRewriter(root, rewritten)
+	old := root.GetNodeByRule(Rulereturning).Child(Ruleexpr)
+	n, err := ParseFragment(Ruleexpr, "2 * a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, err := rw.Replace(old, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "routine main<> {\n\tres a; // a\n\ta = 1;\n\treturn 2 * a;\n}\n"
+	if rw.Source() != want {
+		t.Fatalf("got %q, want %q", rw.Source(), want)
+	}
+	if removed.Parent() != nil || removed.Source() != "a + 1" {
+		t.Errorf("removed %q, which is still attached", removed.Source())
+	}
+	if n.Parent() == nil || n.Source() != "2 * a" {
+		t.Errorf("inserted %q", n.Source())
+	}
+	checkTree(t, root, rw.Source())
+	checkReparse(t, root, rw.Source())
+
+	if _, err := rw.Replace(removed, NewNode(Rulenumber, "1")); err != errNodeDetached {
+		t.Errorf("replaced a detached node: %v", err)
+	}
+}
+
+func TestRewriterInsertBefore(t *testing.T) {
+	root := parse(t, rewritten, true)
+	rw := NewRewriter(root, rewritten)
+	sibling := root.GetNodesByRule(Rulecodestatement)[1]
+	n, err := ParseFragment(Rulecodestatement, "a = a * 2;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rw.InsertBefore(sibling, n); err != nil {
+		t.Fatal(err)
+	}
+	// n takes over the line break and indent before sibling
+	want := "routine main<> {\n\tres a; // a\n\ta = a * 2;a = 1;\n\treturn a + 1;\n}\n"
+	if rw.Source() != want {
+		t.Fatalf("got %q, want %q", rw.Source(), want)
+	}
+	if n.After() != sibling {
+		t.Errorf("%q does not come before %q", n.Source(), sibling.Source())
+	}
+	checkTree(t, root, rw.Source())
+	checkReparse(t, root, rw.Source())
+
+	if err := rw.InsertBefore(sibling, n); err != errNodeAttached {
+		t.Errorf("inserted an attached node: %v", err)
+	}
+}
+
+func TestRewriterDelete(t *testing.T) {
+	root := parse(t, rewritten, true)
+	rw := NewRewriter(root, rewritten)
+	statement := root.GetNodesByRule(Rulecodestatement)[1]
+	removed, err := rw.Delete(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the trivia around the statement stays
+	want := "routine main<> {\n\tres a; // a\n\t\n\treturn a + 1;\n}\n"
+	if rw.Source() != want {
+		t.Fatalf("got %q, want %q", rw.Source(), want)
+	}
+	if removed.Parent() != nil || removed.Source() != "a = 1;" {
+		t.Errorf("removed %q, which is still attached", removed.Source())
+	}
+	if len(root.GetNodesByRule(Rulecodestatement)) != 2 {
+		t.Errorf("the index still has the deleted statement")
+	}
+	checkTree(t, root, rw.Source())
+	checkReparse(t, root, rw.Source())
+}
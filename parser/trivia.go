@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"io"
+	"strings"
+)
+
+type TriviaKind int
+
+const (
+	TriviaWhitespace TriviaKind = iota
+	TriviaLineComment
+	TriviaBlockComment
+)
+
+// Trivia is a piece of source text with no meaning to the
+// program: a run of whitespace or a comment.
+//
+// When a tree is parsed with KeepTrivia set, the trivia between
+// two leaves is split between them. The earlier leaf trails the
+// trivia up to and including the end of its line, the later
+// leaf leads with the rest. Trivia before the first leaf leads
+// it and trivia after the last leaf trails it, so Print
+// reproduces the parsed source byte for byte.
+//
+// Line comments do not include their line break and whitespace
+// runs end at line breaks.
+type Trivia struct {
+	Kind TriviaKind
+	Text string
+}
+
+// LeadingTrivia returns the trivia before root, which leads the
+// first leaf in its subtree.
+func (root *Node) LeadingTrivia() []Trivia {
+	return root.firstLeaf().Leading
+}
+
+// TrailingTrivia returns the trivia after root, which trails
+// the last leaf in its subtree.
+func (root *Node) TrailingTrivia() []Trivia {
+	return root.lastLeaf().Trailing
+}
+
+// Print writes the source of the tree rooted at root to w,
+// including the leading and trailing trivia of every leaf.
+func (root *Node) Print(w io.Writer) error {
+	var err error
+	root.Walk(func(nd *Node) SearchCode {
+		if len(nd.Children) > 0 {
+			return SEARCH_CONTINUE
+		}
+		for _, t := range nd.Leading {
+			if _, err = io.WriteString(w, t.Text); err != nil {
+				return SEARCH_END
+			}
+		}
+		if _, err = io.WriteString(w, nd.Source()); err != nil {
+			return SEARCH_END
+		}
+		for _, t := range nd.Trailing {
+			if _, err = io.WriteString(w, t.Text); err != nil {
+				return SEARCH_END
+			}
+		}
+		return SEARCH_SKIP
+	}, nil)
+	return err
+}
+
+func (root *Node) firstLeaf() *Node {
+	for len(root.Children) > 0 {
+		root = root.Children[0]
+	}
+	return root
+}
+
+func (root *Node) lastLeaf() *Node {
+	for len(root.Children) > 0 {
+		root = root.Children[len(root.Children)-1]
+	}
+	return root
+}
+
+// attachTrivia splits the text of source that no leaf of the
+// tree rooted at root covers into trivia and attaches it to
+// the leaves.
+func attachTrivia(root *Node, source string) {
+	var last *Node
+	position := 0
+	root.Walk(func(nd *Node) SearchCode {
+		if len(nd.Children) > 0 {
+			return SEARCH_CONTINUE
+		}
+		trivia := splitTrivia(source[position:nd.Tok.Begin()])
+		if last != nil {
+			n := endOfLine(trivia)
+			last.Trailing, trivia = trivia[:n:n], trivia[n:]
+		}
+		nd.Leading = trivia
+		last, position = nd, nd.Tok.End()
+		return SEARCH_SKIP
+	}, nil)
+	if last != nil {
+		last.Trailing = splitTrivia(source[position:])
+	}
+}
+
+// splitTrivia splits text, which holds only whitespace and
+// comments, into trivia.
+func splitTrivia(text string) []Trivia {
+	var trivia []Trivia
+	for len(text) > 0 {
+		t := Trivia{Kind: TriviaWhitespace}
+		n := 0
+		switch {
+		case strings.HasPrefix(text, "//"):
+			t.Kind = TriviaLineComment
+			n = strings.IndexAny(text, "\r\n")
+		case strings.HasPrefix(text, "/*"):
+			t.Kind = TriviaBlockComment
+			if n = strings.Index(text[2:], "*/"); n >= 0 {
+				n += 4
+			}
+		default:
+			for n < len(text) && text[n] != '\n' && !isComment(text[n:]) {
+				n++
+			}
+			if n < len(text) && text[n] == '\n' {
+				n++
+			}
+		}
+		if n < 0 {
+			n = len(text)
+		}
+		t.Text, text = text[:n], text[n:]
+		trivia = append(trivia, t)
+	}
+	return trivia
+}
+
+func isComment(text string) bool {
+	return strings.HasPrefix(text, "//") || strings.HasPrefix(text, "/*")
+}
+
+// endOfLine returns how many pieces of trivia there are up to
+// and including the first line break, or all of them if there
+// is none.
+func endOfLine(trivia []Trivia) int {
+	for i, t := range trivia {
+		if t.Kind == TriviaWhitespace && strings.HasSuffix(t.Text, "\n") {
+			return i + 1
+		}
+	}
+	return len(trivia)
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+var roundTrips = []string{
+	"routine main<> { return 0; }",
+	"routine main<> { return 0; }\n",
+	"// leading\n\n/* block */ routine main<> {\n\t// own line\n\tres a; // trailing\n\ta = 1 + /* inner */ 2;\n\treturn a;\n}\n\n// last\n",
+	"/*\n * spans\n * lines\n */\nroutine f<a, b> : int { return a + b; }   \n\n\n",
+	"import \"lib/m.min\" as m; // m\n\nconst N = 2; /* N */\nglobal A, B;\n\nroutine main<> {\n\tif (A == 1) { B = 2; } // then\n\telse { B = 3; }\n\treturn m.f(N, // n\n\t\tB);\n}\n",
+	"\t \n\nroutine main<> {\r\n\treturn 0;\r\n}\r\n",
+}
+
+func TestPrintRoundTrips(t *testing.T) {
+	for _, src := range roundTrips {
+		root := parse(t, src, true)
+		var out bytes.Buffer
+		if err := root.Print(&out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != src {
+			t.Errorf("printed\n%q\ninstead of\n%q", out.String(), src)
+		}
+	}
+}
+
+func TestTriviaKinds(t *testing.T) {
+	root := parse(t, "routine main<> { return 0; } // a\n/* b */\n", true)
+	var kinds []TriviaKind
+	for _, trivia := range root.TrailingTrivia() {
+		kinds = append(kinds, trivia.Kind)
+	}
+	want := []TriviaKind{TriviaWhitespace, TriviaLineComment, TriviaWhitespace, TriviaBlockComment, TriviaWhitespace}
+	if len(kinds) != len(want) {
+		t.Fatalf("got trivia %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("got trivia %v, want %v", kinds, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+// Visitor is implemented by types that want to be notified as
+// Visit traverses a tree. Enter is called before a node's
+// children are visited and Leave after all of them have been.
+type Visitor interface {
+	Enter(*Node) SearchCode
+	Leave(*Node) SearchCode
+}
+
+// Visit walks the tree rooted at root, calling v.Enter and
+// v.Leave on every node. See Walk.
+func (root *Node) Visit(v Visitor) SearchCode {
+	return root.Walk(v.Enter, v.Leave)
+}
+
+// Walk traverses the tree rooted at root depth first. pre is
+// called on a node before any of its children and post after
+// all of them. Either function may be nil. Unlike EachNode,
+// root itself is visited.
+//
+// Returning SEARCH_SKIP from pre prevents Walk from descending
+// into the node's children; post is still called for the node.
+// Returning SEARCH_END from either function stops the walk and
+// is passed back to the caller.
+//
+// pre and post may replace or delete the node they are given
+// (and its descendants) through a Rewriter. Editing siblings of
+// the node being visited during the walk is not supported.
+func (root *Node) Walk(pre, post SearchFunction) SearchCode {
+	if pre != nil {
+		code := pre(root)
+		if code&SEARCH_END == SEARCH_END {
+			return code
+		}
+		if code&SEARCH_SKIP == SEARCH_SKIP {
+			return root._leave(post)
+		}
+	}
+
+	// Children is re-read on every iteration so that callbacks
+	// may replace or delete the child being visited.
+	for i := 0; i < len(root.Children); i++ {
+		count := len(root.Children)
+		if code := root.Children[i].Walk(pre, post); code&SEARCH_END == SEARCH_END {
+			return code
+		}
+		if len(root.Children) < count {
+			// the child was deleted; its younger sibling
+			// now lives at index i.
+			i--
+		}
+	}
+	return root._leave(post)
+}
+
+func (root *Node) _leave(post SearchFunction) SearchCode {
+	if post == nil {
+		return SEARCH_CONTINUE
+	}
+	if code := post(root); code&SEARCH_END == SEARCH_END {
+		return code
+	}
+	return SEARCH_CONTINUE
+}
@@ -16,45 +16,96 @@ type Rule uint8
 const (
 	RuleUnknown Rule = iota
 	Ruleprogram
+	Ruleimport
 	Ruleroutine
+	Ruleextern
+	Ruleconstant
+	Ruleglobal
+	Rulestruct
+	Rulefield
 	Ruleoperation
 	Ruleopaction
 	Rulereservation
+	Rulereserved
 	Rulereturning
 	Ruleassignment
+	Ruledestructuring
+	Ruletarget
 	Rulelabeling
 	Rulejumping
+	Rulebreaking
+	Rulecontinuing
 	Rulevalue
-	Ruleexpr
+	Ruleelement
+	Rulemember
 	Rulefunccall
+	Rulereference
 	Rulecodestatement
 	Rulecodeblock
 	Rulelogicblock
 	Ruleifblock
 	Ruleelseblock
+	Ruleloop
+	Rulewhileloop
+	Ruleforloop
+	Ruleforinit
+	Ruleforstep
 	Rulevariable
 	RulefuncIdDecl
 	Rulefuncidentifier
+	Rulemodule
 	Ruleparamaterdecl
 	Rulecallparams
 	Ruleparameters
+	Ruleparameter
+	Ruleannotation
+	Ruleresults
+	Ruletypename
+	Rulefieldname
 	Rulecomma
 	Rulekwreserve
+	Rulekwconst
+	Rulekwglobal
+	Rulekwstruct
+	Rulekwimport
+	Rulekwas
+	Rulekwextern
 	Rulekwreturn
 	Rulekwroutine
 	Rulekwjump
 	Rulekwlabel
 	Rulekwif
 	Rulekwelse
+	Rulekwwhile
+	Rulekwfor
+	Rulekwbreak
+	Rulekwcontinue
 	Ruletokadd
 	Ruletoksub
 	Ruletokmul
 	Ruletokdiv
+	Ruletokmod
+	Ruletokbitand
+	Ruletokbitor
+	Ruletokxor
+	Ruletokshl
+	Ruletokshr
+	Ruletokbitnot
+	Ruletokand
+	Ruletokor
+	Ruletoknot
 	Ruleendl
-	Rulerawmath
-	Rulemath
+	Ruleexpr
+	Ruleconjunction
+	Rulecomparison
+	Rulesum
+	Ruleterm
+	Ruleunary
+	Ruleprimary
 	Rulepopen
 	Rulepclose
+	Rulebopen
+	Rulebclose
 	Ruletoklt
 	Ruletokgt
 	Ruletokeq
@@ -62,12 +113,21 @@ const (
 	Ruletokge
 	Ruletokne
 	Rulecomparisontoken
-	Rulecomparison
+	Rulecondition
 	Rulecomparison_paren
 	Rulepositivenum
+	Ruledecimalnum
+	Rulehexnum
+	Rulebinarynum
 	Rulenegativenum
 	Rulenumber
 	Ruledigit
+	Rulehexdigit
+	Rulefloat
+	Ruledigits
+	Ruleexponent
+	Rulestring
+	Ruleescape
 	Rulecommentblock
 	Rulecommentdoubleslash
 	Rulecomment
@@ -84,45 +144,96 @@ const (
 var Rul3s = [...]string{
 	"Unknown",
 	"program",
+	"import",
 	"routine",
+	"extern",
+	"constant",
+	"global",
+	"struct",
+	"field",
 	"operation",
 	"opaction",
 	"reservation",
+	"reserved",
 	"returning",
 	"assignment",
+	"destructuring",
+	"target",
 	"labeling",
 	"jumping",
+	"breaking",
+	"continuing",
 	"value",
-	"expr",
+	"element",
+	"member",
 	"funccall",
+	"reference",
 	"codestatement",
 	"codeblock",
 	"logicblock",
 	"ifblock",
 	"elseblock",
+	"loop",
+	"whileloop",
+	"forloop",
+	"forinit",
+	"forstep",
 	"variable",
 	"funcIdDecl",
 	"funcidentifier",
+	"module",
 	"paramaterdecl",
 	"callparams",
 	"parameters",
+	"parameter",
+	"annotation",
+	"results",
+	"typename",
+	"fieldname",
 	"comma",
 	"kwreserve",
+	"kwconst",
+	"kwglobal",
+	"kwstruct",
+	"kwimport",
+	"kwas",
+	"kwextern",
 	"kwreturn",
 	"kwroutine",
 	"kwjump",
 	"kwlabel",
 	"kwif",
 	"kwelse",
+	"kwwhile",
+	"kwfor",
+	"kwbreak",
+	"kwcontinue",
 	"tokadd",
 	"toksub",
 	"tokmul",
 	"tokdiv",
+	"tokmod",
+	"tokbitand",
+	"tokbitor",
+	"tokxor",
+	"tokshl",
+	"tokshr",
+	"tokbitnot",
+	"tokand",
+	"tokor",
+	"toknot",
 	"endl",
-	"rawmath",
-	"math",
+	"expr",
+	"conjunction",
+	"comparison",
+	"sum",
+	"term",
+	"unary",
+	"primary",
 	"popen",
 	"pclose",
+	"bopen",
+	"bclose",
 	"toklt",
 	"tokgt",
 	"tokeq",
@@ -130,12 +241,21 @@ var Rul3s = [...]string{
 	"tokge",
 	"tokne",
 	"comparisontoken",
-	"comparison",
+	"condition",
 	"comparison_paren",
 	"positivenum",
+	"decimalnum",
+	"hexnum",
+	"binarynum",
 	"negativenum",
 	"number",
 	"digit",
+	"hexdigit",
+	"float",
+	"digits",
+	"exponent",
+	"string",
+	"escape",
 	"commentblock",
 	"commentdoubleslash",
 	"comment",
@@ -647,8 +767,12 @@ type VMTree struct {
 	//Expression
 	ASTTree Node
 
+	// KeepTrivia makes ParseTree attach whitespace and
+	// comments to the leaves of the tree; see Trivia.
+	KeepTrivia bool
+
 	Buffer string
-	rules  [61]func() bool
+	rules  [121]func() bool
 	Parse  func(rule ...int) error
 	Reset  func()
 	TokenTree
@@ -723,6 +847,10 @@ func (p *VMTree) Init() {
 	}
 
 	var tree TokenTree = &tokens16{tree: make([]token16, math.MaxInt16)}
+	if len(p.Buffer) > math.MaxInt16 {
+		// token16 cannot hold positions this far into the buffer
+		tree = &tokens32{tree: make([]token32, math.MaxInt16)}
+	}
 	position, depth, tokenIndex, buffer, rules := 0, 0, 0, p.Buffer, p.rules
 
 	p.Parse = func(rule ...int) error {
@@ -777,7 +905,7 @@ func (p *VMTree) Init() {
 
 	rules = [...]func() bool{
 		nil,
-		/* 0 program <- <(optspace (routine optspace)+ optspace)> */
+		/* 0 program <- <(optspace (import optspace)* ((routine / extern / constant / global / struct) optspace)+ optspace !.)> */
 		func() bool {
 			position0, tokenIndex0, depth0 := position, tokenIndex, depth
 			{
@@ -786,16 +914,10 @@ func (p *VMTree) Init() {
 				if !rules[Ruleoptspace]() {
 					goto l0
 				}
-				if !rules[Ruleroutine]() {
-					goto l0
-				}
-				if !rules[Ruleoptspace]() {
-					goto l0
-				}
 			l2:
 				{
 					position3, tokenIndex3, depth3 := position, tokenIndex, depth
-					if !rules[Ruleroutine]() {
+					if !rules[Ruleimport]() {
 						goto l3
 					}
 					if !rules[Ruleoptspace]() {
@@ -805,1795 +927,4291 @@ func (p *VMTree) Init() {
 				l3:
 					position, tokenIndex, depth = position3, tokenIndex3, depth3
 				}
-				if !rules[Ruleoptspace]() {
-					goto l0
-				}
-				depth--
-				add(Ruleprogram, position1)
-			}
-			return true
-		l0:
-			position, tokenIndex, depth = position0, tokenIndex0, depth0
-			return false
-		},
-		/* 1 routine <- <(kwroutine minspace funcIdDecl optspace paramaterdecl codeblock)> */
-		func() bool {
-			position4, tokenIndex4, depth4 := position, tokenIndex, depth
-			{
-				position5 := position
-				depth++
-				if !rules[Rulekwroutine]() {
-					goto l4
-				}
-				if !rules[Ruleminspace]() {
-					goto l4
-				}
-				if !rules[RulefuncIdDecl]() {
-					goto l4
-				}
-				if !rules[Ruleoptspace]() {
-					goto l4
-				}
-				if !rules[Ruleparamaterdecl]() {
-					goto l4
-				}
-				if !rules[Rulecodeblock]() {
-					goto l4
-				}
-				depth--
-				add(Ruleroutine, position5)
-			}
-			return true
-		l4:
-			position, tokenIndex, depth = position4, tokenIndex4, depth4
-			return false
-		},
-		/* 2 operation <- <(opaction optspace endl)> */
-		func() bool {
-			position6, tokenIndex6, depth6 := position, tokenIndex, depth
-			{
-				position7 := position
-				depth++
-				if !rules[Ruleopaction]() {
+				{
+					position6, tokenIndex6, depth6 := position, tokenIndex, depth
+					if !rules[Ruleroutine]() {
+						goto l7
+					}
 					goto l6
-				}
-				if !rules[Ruleoptspace]() {
+				l7:
+					position, tokenIndex, depth = position6, tokenIndex6, depth6
+					if !rules[Ruleextern]() {
+						goto l8
+					}
 					goto l6
-				}
-				if !rules[Ruleendl]() {
+				l8:
+					position, tokenIndex, depth = position6, tokenIndex6, depth6
+					if !rules[Ruleconstant]() {
+						goto l9
+					}
+					goto l6
+				l9:
+					position, tokenIndex, depth = position6, tokenIndex6, depth6
+					if !rules[Ruleglobal]() {
+						goto l10
+					}
 					goto l6
+				l10:
+					position, tokenIndex, depth = position6, tokenIndex6, depth6
+					if !rules[Rulestruct]() {
+						goto l0
+					}
 				}
-				depth--
-				add(Ruleoperation, position7)
-			}
-			return true
-		l6:
-			position, tokenIndex, depth = position6, tokenIndex6, depth6
-			return false
-		},
-		/* 3 opaction <- <(reservation / returning / assignment / labeling / jumping)> */
-		func() bool {
-			position8, tokenIndex8, depth8 := position, tokenIndex, depth
-			{
-				position9 := position
-				depth++
+			l6:
+				if !rules[Ruleoptspace]() {
+					goto l0
+				}
+			l4:
 				{
-					position10, tokenIndex10, depth10 := position, tokenIndex, depth
-					if !rules[Rulereservation]() {
+					position5, tokenIndex5, depth5 := position, tokenIndex, depth
+					{
+						position11, tokenIndex11, depth11 := position, tokenIndex, depth
+						if !rules[Ruleroutine]() {
+							goto l12
+						}
+						goto l11
+					l12:
+						position, tokenIndex, depth = position11, tokenIndex11, depth11
+						if !rules[Ruleextern]() {
+							goto l13
+						}
+						goto l11
+					l13:
+						position, tokenIndex, depth = position11, tokenIndex11, depth11
+						if !rules[Ruleconstant]() {
+							goto l14
+						}
 						goto l11
+					l14:
+						position, tokenIndex, depth = position11, tokenIndex11, depth11
+						if !rules[Ruleglobal]() {
+							goto l15
+						}
+						goto l11
+					l15:
+						position, tokenIndex, depth = position11, tokenIndex11, depth11
+						if !rules[Rulestruct]() {
+							goto l5
+						}
 					}
-					goto l10
 				l11:
-					position, tokenIndex, depth = position10, tokenIndex10, depth10
-					if !rules[Rulereturning]() {
-						goto l12
-					}
-					goto l10
-				l12:
-					position, tokenIndex, depth = position10, tokenIndex10, depth10
-					if !rules[Ruleassignment]() {
-						goto l13
-					}
-					goto l10
-				l13:
-					position, tokenIndex, depth = position10, tokenIndex10, depth10
-					if !rules[Rulelabeling]() {
-						goto l14
+					if !rules[Ruleoptspace]() {
+						goto l5
 					}
-					goto l10
-				l14:
-					position, tokenIndex, depth = position10, tokenIndex10, depth10
-					if !rules[Rulejumping]() {
-						goto l8
+					goto l4
+				l5:
+					position, tokenIndex, depth = position5, tokenIndex5, depth5
+				}
+				if !rules[Ruleoptspace]() {
+					goto l0
+				}
+				{
+					position16, tokenIndex16, depth16 := position, tokenIndex, depth
+					if !matchDot() {
+						goto l16
 					}
+					goto l0
+				l16:
+					position, tokenIndex, depth = position16, tokenIndex16, depth16
 				}
-			l10:
 				depth--
-				add(Ruleopaction, position9)
+				add(Ruleprogram, position1)
 			}
 			return true
-		l8:
-			position, tokenIndex, depth = position8, tokenIndex8, depth8
+		l0:
+			position, tokenIndex, depth = position0, tokenIndex0, depth0
 			return false
 		},
-		/* 4 reservation <- <(kwreserve minspace variable (comma variable)*)> */
+		/* 1 import <- <(kwimport minspace string (minspace kwas minspace module)? endl)> */
 		func() bool {
-			position15, tokenIndex15, depth15 := position, tokenIndex, depth
+			position17, tokenIndex17, depth17 := position, tokenIndex, depth
 			{
-				position16 := position
+				position18 := position
 				depth++
-				if !rules[Rulekwreserve]() {
-					goto l15
+				if !rules[Rulekwimport]() {
+					goto l17
 				}
 				if !rules[Ruleminspace]() {
-					goto l15
+					goto l17
 				}
-				if !rules[Rulevariable]() {
-					goto l15
+				if !rules[Rulestring]() {
+					goto l17
 				}
-			l17:
 				{
-					position18, tokenIndex18, depth18 := position, tokenIndex, depth
-					if !rules[Rulecomma]() {
-						goto l18
+					position19, tokenIndex19, depth19 := position, tokenIndex, depth
+					if !rules[Ruleminspace]() {
+						goto l19
 					}
-					if !rules[Rulevariable]() {
-						goto l18
+					if !rules[Rulekwas]() {
+						goto l19
 					}
-					goto l17
-				l18:
-					position, tokenIndex, depth = position18, tokenIndex18, depth18
-				}
-				depth--
-				add(Rulereservation, position16)
-			}
-			return true
-		l15:
-			position, tokenIndex, depth = position15, tokenIndex15, depth15
-			return false
-		},
-		/* 5 returning <- <(kwreturn minspace expr)> */
-		func() bool {
-			position19, tokenIndex19, depth19 := position, tokenIndex, depth
-			{
-				position20 := position
-				depth++
-				if !rules[Rulekwreturn]() {
-					goto l19
-				}
-				if !rules[Ruleminspace]() {
-					goto l19
+					if !rules[Ruleminspace]() {
+						goto l19
+					}
+					if !rules[Rulemodule]() {
+						goto l19
+					}
+					goto l20
+				l19:
+					position, tokenIndex, depth = position19, tokenIndex19, depth19
 				}
-				if !rules[Ruleexpr]() {
-					goto l19
+			l20:
+				if !rules[Ruleendl]() {
+					goto l17
 				}
 				depth--
-				add(Rulereturning, position20)
+				add(Ruleimport, position18)
 			}
 			return true
-		l19:
-			position, tokenIndex, depth = position19, tokenIndex19, depth19
+		l17:
+			position, tokenIndex, depth = position17, tokenIndex17, depth17
 			return false
 		},
-		/* 6 assignment <- <(variable optspace '=' optspace expr optspace)> */
+		/* 2 routine <- <(kwroutine minspace funcIdDecl optspace paramaterdecl (optspace results)? codeblock)> */
 		func() bool {
 			position21, tokenIndex21, depth21 := position, tokenIndex, depth
 			{
 				position22 := position
 				depth++
-				if !rules[Rulevariable]() {
+				if !rules[Rulekwroutine]() {
 					goto l21
 				}
-				if !rules[Ruleoptspace]() {
+				if !rules[Ruleminspace]() {
 					goto l21
 				}
-				if buffer[position] != '=' {
+				if !rules[RulefuncIdDecl]() {
 					goto l21
 				}
-				position++
 				if !rules[Ruleoptspace]() {
 					goto l21
 				}
-				if !rules[Ruleexpr]() {
+				if !rules[Ruleparamaterdecl]() {
 					goto l21
 				}
-				if !rules[Ruleoptspace]() {
+				{
+					position23, tokenIndex23, depth23 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l23
+					}
+					if !rules[Ruleresults]() {
+						goto l23
+					}
+					goto l24
+				l23:
+					position, tokenIndex, depth = position23, tokenIndex23, depth23
+				}
+			l24:
+				if !rules[Rulecodeblock]() {
 					goto l21
 				}
 				depth--
-				add(Ruleassignment, position22)
+				add(Ruleroutine, position22)
 			}
 			return true
 		l21:
 			position, tokenIndex, depth = position21, tokenIndex21, depth21
 			return false
 		},
-		/* 7 labeling <- <(kwlabel minspace variable optspace)> */
+		/* 3 extern <- <(kwextern minspace kwroutine minspace funcIdDecl optspace paramaterdecl endl)> */
 		func() bool {
-			position23, tokenIndex23, depth23 := position, tokenIndex, depth
+			position25, tokenIndex25, depth25 := position, tokenIndex, depth
 			{
-				position24 := position
+				position26 := position
 				depth++
-				if !rules[Rulekwlabel]() {
-					goto l23
+				if !rules[Rulekwextern]() {
+					goto l25
 				}
 				if !rules[Ruleminspace]() {
-					goto l23
-				}
-				if !rules[Rulevariable]() {
-					goto l23
-				}
-				if !rules[Ruleoptspace]() {
-					goto l23
+					goto l25
 				}
-				depth--
-				add(Rulelabeling, position24)
-			}
-			return true
-		l23:
-			position, tokenIndex, depth = position23, tokenIndex23, depth23
-			return false
-		},
-		/* 8 jumping <- <(kwjump minspace variable optspace)> */
-		func() bool {
-			position25, tokenIndex25, depth25 := position, tokenIndex, depth
-			{
-				position26 := position
-				depth++
-				if !rules[Rulekwjump]() {
+				if !rules[Rulekwroutine]() {
 					goto l25
 				}
 				if !rules[Ruleminspace]() {
 					goto l25
 				}
-				if !rules[Rulevariable]() {
+				if !rules[RulefuncIdDecl]() {
 					goto l25
 				}
 				if !rules[Ruleoptspace]() {
 					goto l25
 				}
+				if !rules[Ruleparamaterdecl]() {
+					goto l25
+				}
+				if !rules[Ruleendl]() {
+					goto l25
+				}
 				depth--
-				add(Rulejumping, position26)
+				add(Ruleextern, position26)
 			}
 			return true
 		l25:
 			position, tokenIndex, depth = position25, tokenIndex25, depth25
 			return false
 		},
-		/* 9 value <- <(funccall / number / variable)> */
+		/* 4 constant <- <(kwconst minspace variable optspace '=' optspace expr endl)> */
 		func() bool {
 			position27, tokenIndex27, depth27 := position, tokenIndex, depth
 			{
 				position28 := position
 				depth++
-				{
-					position29, tokenIndex29, depth29 := position, tokenIndex, depth
-					if !rules[Rulefunccall]() {
-						goto l30
-					}
-					goto l29
-				l30:
-					position, tokenIndex, depth = position29, tokenIndex29, depth29
-					if !rules[Rulenumber]() {
-						goto l31
-					}
-					goto l29
-				l31:
-					position, tokenIndex, depth = position29, tokenIndex29, depth29
-					if !rules[Rulevariable]() {
-						goto l27
-					}
+				if !rules[Rulekwconst]() {
+					goto l27
+				}
+				if !rules[Ruleminspace]() {
+					goto l27
+				}
+				if !rules[Rulevariable]() {
+					goto l27
+				}
+				if !rules[Ruleoptspace]() {
+					goto l27
+				}
+				if buffer[position] != '=' {
+					goto l27
+				}
+				position++
+				if !rules[Ruleoptspace]() {
+					goto l27
+				}
+				if !rules[Ruleexpr]() {
+					goto l27
+				}
+				if !rules[Ruleendl]() {
+					goto l27
 				}
-			l29:
 				depth--
-				add(Rulevalue, position28)
+				add(Ruleconstant, position28)
 			}
 			return true
 		l27:
 			position, tokenIndex, depth = position27, tokenIndex27, depth27
 			return false
 		},
-		/* 10 expr <- <(value / math)> */
+		/* 5 global <- <(kwglobal minspace variable (comma variable)* endl)> */
 		func() bool {
-			position32, tokenIndex32, depth32 := position, tokenIndex, depth
+			position29, tokenIndex29, depth29 := position, tokenIndex, depth
 			{
-				position33 := position
+				position30 := position
 				depth++
+				if !rules[Rulekwglobal]() {
+					goto l29
+				}
+				if !rules[Ruleminspace]() {
+					goto l29
+				}
+				if !rules[Rulevariable]() {
+					goto l29
+				}
+			l31:
 				{
-					position34, tokenIndex34, depth34 := position, tokenIndex, depth
-					if !rules[Rulevalue]() {
-						goto l35
+					position32, tokenIndex32, depth32 := position, tokenIndex, depth
+					if !rules[Rulecomma]() {
+						goto l32
 					}
-					goto l34
-				l35:
-					position, tokenIndex, depth = position34, tokenIndex34, depth34
-					if !rules[Rulemath]() {
+					if !rules[Rulevariable]() {
 						goto l32
 					}
+					goto l31
+				l32:
+					position, tokenIndex, depth = position32, tokenIndex32, depth32
+				}
+				if !rules[Ruleendl]() {
+					goto l29
 				}
-			l34:
 				depth--
-				add(Ruleexpr, position33)
+				add(Ruleglobal, position30)
 			}
 			return true
-		l32:
-			position, tokenIndex, depth = position32, tokenIndex32, depth32
+		l29:
+			position, tokenIndex, depth = position29, tokenIndex29, depth29
 			return false
 		},
-		/* 11 funccall <- <(funcidentifier '(' optspace callparams? ')')> */
+		/* 6 struct <- <(kwstruct minspace variable optspace '{' optspace field (comma field)* optspace '}')> */
 		func() bool {
-			position36, tokenIndex36, depth36 := position, tokenIndex, depth
+			position33, tokenIndex33, depth33 := position, tokenIndex, depth
 			{
-				position37 := position
+				position34 := position
 				depth++
-				if !rules[Rulefuncidentifier]() {
-					goto l36
+				if !rules[Rulekwstruct]() {
+					goto l33
 				}
-				if buffer[position] != '(' {
-					goto l36
+				if !rules[Ruleminspace]() {
+					goto l33
+				}
+				if !rules[Rulevariable]() {
+					goto l33
+				}
+				if !rules[Ruleoptspace]() {
+					goto l33
+				}
+				if buffer[position] != '{' {
+					goto l33
 				}
 				position++
 				if !rules[Ruleoptspace]() {
-					goto l36
+					goto l33
 				}
+				if !rules[Rulefield]() {
+					goto l33
+				}
+			l35:
 				{
-					position38, tokenIndex38, depth38 := position, tokenIndex, depth
-					if !rules[Rulecallparams]() {
-						goto l38
+					position36, tokenIndex36, depth36 := position, tokenIndex, depth
+					if !rules[Rulecomma]() {
+						goto l36
+					}
+					if !rules[Rulefield]() {
+						goto l36
 					}
-					goto l39
-				l38:
-					position, tokenIndex, depth = position38, tokenIndex38, depth38
+					goto l35
+				l36:
+					position, tokenIndex, depth = position36, tokenIndex36, depth36
 				}
-			l39:
-				if buffer[position] != ')' {
-					goto l36
+				if !rules[Ruleoptspace]() {
+					goto l33
+				}
+				if buffer[position] != '}' {
+					goto l33
 				}
 				position++
 				depth--
-				add(Rulefunccall, position37)
+				add(Rulestruct, position34)
 			}
 			return true
-		l36:
-			position, tokenIndex, depth = position36, tokenIndex36, depth36
+		l33:
+			position, tokenIndex, depth = position33, tokenIndex33, depth33
 			return false
 		},
-		/* 12 codestatement <- <(logicblock / operation)> */
+		/* 7 field <- <(fieldname (optspace annotation)?)> */
 		func() bool {
-			position40, tokenIndex40, depth40 := position, tokenIndex, depth
+			position37, tokenIndex37, depth37 := position, tokenIndex, depth
 			{
-				position41 := position
+				position38 := position
 				depth++
+				if !rules[Rulefieldname]() {
+					goto l37
+				}
 				{
-					position42, tokenIndex42, depth42 := position, tokenIndex, depth
-					if !rules[Rulelogicblock]() {
-						goto l43
+					position39, tokenIndex39, depth39 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l39
 					}
-					goto l42
-				l43:
-					position, tokenIndex, depth = position42, tokenIndex42, depth42
-					if !rules[Ruleoperation]() {
-						goto l40
+					if !rules[Ruleannotation]() {
+						goto l39
 					}
+					goto l40
+				l39:
+					position, tokenIndex, depth = position39, tokenIndex39, depth39
 				}
-			l42:
+			l40:
 				depth--
-				add(Rulecodestatement, position41)
+				add(Rulefield, position38)
 			}
 			return true
-		l40:
-			position, tokenIndex, depth = position40, tokenIndex40, depth40
+		l37:
+			position, tokenIndex, depth = position37, tokenIndex37, depth37
 			return false
 		},
-		/* 13 codeblock <- <(optspace '{' (optspace codestatement)* optspace '}' optspace)> */
+		/* 8 operation <- <(opaction optspace endl)> */
 		func() bool {
-			position44, tokenIndex44, depth44 := position, tokenIndex, depth
+			position41, tokenIndex41, depth41 := position, tokenIndex, depth
 			{
-				position45 := position
+				position42 := position
 				depth++
+				if !rules[Ruleopaction]() {
+					goto l41
+				}
 				if !rules[Ruleoptspace]() {
-					goto l44
+					goto l41
 				}
-				if buffer[position] != '{' {
-					goto l44
+				if !rules[Ruleendl]() {
+					goto l41
 				}
-				position++
-			l46:
+				depth--
+				add(Ruleoperation, position42)
+			}
+			return true
+		l41:
+			position, tokenIndex, depth = position41, tokenIndex41, depth41
+			return false
+		},
+		/* 9 opaction <- <(reservation / returning / destructuring / assignment / labeling / jumping / funccall / breaking / continuing)> */
+		func() bool {
+			position43, tokenIndex43, depth43 := position, tokenIndex, depth
+			{
+				position44 := position
+				depth++
 				{
-					position47, tokenIndex47, depth47 := position, tokenIndex, depth
-					if !rules[Ruleoptspace]() {
-						goto l47
+					position45, tokenIndex45, depth45 := position, tokenIndex, depth
+					if !rules[Rulereservation]() {
+						goto l46
 					}
-					if !rules[Rulecodestatement]() {
+					goto l45
+				l46:
+					position, tokenIndex, depth = position45, tokenIndex45, depth45
+					if !rules[Rulereturning]() {
 						goto l47
 					}
-					goto l46
+					goto l45
 				l47:
-					position, tokenIndex, depth = position47, tokenIndex47, depth47
+					position, tokenIndex, depth = position45, tokenIndex45, depth45
+					if !rules[Ruledestructuring]() {
+						goto l48
+					}
+					goto l45
+				l48:
+					position, tokenIndex, depth = position45, tokenIndex45, depth45
+					if !rules[Ruleassignment]() {
+						goto l49
+					}
+					goto l45
+				l49:
+					position, tokenIndex, depth = position45, tokenIndex45, depth45
+					if !rules[Rulelabeling]() {
+						goto l50
+					}
+					goto l45
+				l50:
+					position, tokenIndex, depth = position45, tokenIndex45, depth45
+					if !rules[Rulejumping]() {
+						goto l51
+					}
+					goto l45
+				l51:
+					position, tokenIndex, depth = position45, tokenIndex45, depth45
+					if !rules[Rulefunccall]() {
+						goto l52
+					}
+					goto l45
+				l52:
+					position, tokenIndex, depth = position45, tokenIndex45, depth45
+					if !rules[Rulebreaking]() {
+						goto l53
+					}
+					goto l45
+				l53:
+					position, tokenIndex, depth = position45, tokenIndex45, depth45
+					if !rules[Rulecontinuing]() {
+						goto l43
+					}
+				}
+			l45:
+				depth--
+				add(Ruleopaction, position44)
+			}
+			return true
+		l43:
+			position, tokenIndex, depth = position43, tokenIndex43, depth43
+			return false
+		},
+		/* 10 reservation <- <(kwreserve minspace reserved (comma reserved)*)> */
+		func() bool {
+			position54, tokenIndex54, depth54 := position, tokenIndex, depth
+			{
+				position55 := position
+				depth++
+				if !rules[Rulekwreserve]() {
+					goto l54
+				}
+				if !rules[Ruleminspace]() {
+					goto l54
+				}
+				if !rules[Rulereserved]() {
+					goto l54
+				}
+			l56:
+				{
+					position57, tokenIndex57, depth57 := position, tokenIndex, depth
+					if !rules[Rulecomma]() {
+						goto l57
+					}
+					if !rules[Rulereserved]() {
+						goto l57
+					}
+					goto l56
+				l57:
+					position, tokenIndex, depth = position57, tokenIndex57, depth57
+				}
+				depth--
+				add(Rulereservation, position55)
+			}
+			return true
+		l54:
+			position, tokenIndex, depth = position54, tokenIndex54, depth54
+			return false
+		},
+		/* 11 reserved <- <(variable (optspace bopen expr bclose)? (optspace annotation)?)> */
+		func() bool {
+			position58, tokenIndex58, depth58 := position, tokenIndex, depth
+			{
+				position59 := position
+				depth++
+				if !rules[Rulevariable]() {
+					goto l58
+				}
+				{
+					position60, tokenIndex60, depth60 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l60
+					}
+					if !rules[Rulebopen]() {
+						goto l60
+					}
+					if !rules[Ruleexpr]() {
+						goto l60
+					}
+					if !rules[Rulebclose]() {
+						goto l60
+					}
+					goto l61
+				l60:
+					position, tokenIndex, depth = position60, tokenIndex60, depth60
+				}
+			l61:
+				{
+					position62, tokenIndex62, depth62 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l62
+					}
+					if !rules[Ruleannotation]() {
+						goto l62
+					}
+					goto l63
+				l62:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
+				}
+			l63:
+				depth--
+				add(Rulereserved, position59)
+			}
+			return true
+		l58:
+			position, tokenIndex, depth = position58, tokenIndex58, depth58
+			return false
+		},
+		/* 12 returning <- <(kwreturn minspace expr (comma expr)*)> */
+		func() bool {
+			position64, tokenIndex64, depth64 := position, tokenIndex, depth
+			{
+				position65 := position
+				depth++
+				if !rules[Rulekwreturn]() {
+					goto l64
+				}
+				if !rules[Ruleminspace]() {
+					goto l64
+				}
+				if !rules[Ruleexpr]() {
+					goto l64
+				}
+			l66:
+				{
+					position67, tokenIndex67, depth67 := position, tokenIndex, depth
+					if !rules[Rulecomma]() {
+						goto l67
+					}
+					if !rules[Ruleexpr]() {
+						goto l67
+					}
+					goto l66
+				l67:
+					position, tokenIndex, depth = position67, tokenIndex67, depth67
+				}
+				depth--
+				add(Rulereturning, position65)
+			}
+			return true
+		l64:
+			position, tokenIndex, depth = position64, tokenIndex64, depth64
+			return false
+		},
+		/* 13 assignment <- <((member / element / variable) optspace '=' optspace expr optspace)> */
+		func() bool {
+			position68, tokenIndex68, depth68 := position, tokenIndex, depth
+			{
+				position69 := position
+				depth++
+				{
+					position70, tokenIndex70, depth70 := position, tokenIndex, depth
+					if !rules[Rulemember]() {
+						goto l71
+					}
+					goto l70
+				l71:
+					position, tokenIndex, depth = position70, tokenIndex70, depth70
+					if !rules[Ruleelement]() {
+						goto l72
+					}
+					goto l70
+				l72:
+					position, tokenIndex, depth = position70, tokenIndex70, depth70
+					if !rules[Rulevariable]() {
+						goto l68
+					}
 				}
+			l70:
 				if !rules[Ruleoptspace]() {
-					goto l44
+					goto l68
 				}
-				if buffer[position] != '}' {
-					goto l44
+				if buffer[position] != '=' {
+					goto l68
 				}
 				position++
 				if !rules[Ruleoptspace]() {
-					goto l44
+					goto l68
+				}
+				if !rules[Ruleexpr]() {
+					goto l68
+				}
+				if !rules[Ruleoptspace]() {
+					goto l68
 				}
 				depth--
-				add(Rulecodeblock, position45)
+				add(Ruleassignment, position69)
 			}
 			return true
-		l44:
-			position, tokenIndex, depth = position44, tokenIndex44, depth44
+		l68:
+			position, tokenIndex, depth = position68, tokenIndex68, depth68
 			return false
 		},
-		/* 14 logicblock <- <(ifblock (optspace elseblock)?)> */
+		/* 14 destructuring <- <(target (comma target)+ optspace '=' optspace expr optspace)> */
 		func() bool {
-			position48, tokenIndex48, depth48 := position, tokenIndex, depth
+			position73, tokenIndex73, depth73 := position, tokenIndex, depth
 			{
-				position49 := position
+				position74 := position
 				depth++
-				if !rules[Ruleifblock]() {
-					goto l48
+				if !rules[Ruletarget]() {
+					goto l73
+				}
+				if !rules[Rulecomma]() {
+					goto l73
+				}
+				if !rules[Ruletarget]() {
+					goto l73
 				}
+			l75:
 				{
-					position50, tokenIndex50, depth50 := position, tokenIndex, depth
-					if !rules[Ruleoptspace]() {
-						goto l50
+					position76, tokenIndex76, depth76 := position, tokenIndex, depth
+					if !rules[Rulecomma]() {
+						goto l76
 					}
-					if !rules[Ruleelseblock]() {
-						goto l50
+					if !rules[Ruletarget]() {
+						goto l76
 					}
-					goto l51
-				l50:
-					position, tokenIndex, depth = position50, tokenIndex50, depth50
+					goto l75
+				l76:
+					position, tokenIndex, depth = position76, tokenIndex76, depth76
+				}
+				if !rules[Ruleoptspace]() {
+					goto l73
+				}
+				if buffer[position] != '=' {
+					goto l73
+				}
+				position++
+				if !rules[Ruleoptspace]() {
+					goto l73
+				}
+				if !rules[Ruleexpr]() {
+					goto l73
+				}
+				if !rules[Ruleoptspace]() {
+					goto l73
 				}
-			l51:
 				depth--
-				add(Rulelogicblock, position49)
+				add(Ruledestructuring, position74)
 			}
 			return true
-		l48:
-			position, tokenIndex, depth = position48, tokenIndex48, depth48
+		l73:
+			position, tokenIndex, depth = position73, tokenIndex73, depth73
 			return false
 		},
-		/* 15 ifblock <- <(kwif minspace comparison_paren codeblock)> */
+		/* 15 target <- <(member / element / variable)> */
 		func() bool {
-			position52, tokenIndex52, depth52 := position, tokenIndex, depth
+			position77, tokenIndex77, depth77 := position, tokenIndex, depth
 			{
-				position53 := position
+				position78 := position
 				depth++
-				if !rules[Rulekwif]() {
-					goto l52
+				{
+					position79, tokenIndex79, depth79 := position, tokenIndex, depth
+					if !rules[Rulemember]() {
+						goto l80
+					}
+					goto l79
+				l80:
+					position, tokenIndex, depth = position79, tokenIndex79, depth79
+					if !rules[Ruleelement]() {
+						goto l81
+					}
+					goto l79
+				l81:
+					position, tokenIndex, depth = position79, tokenIndex79, depth79
+					if !rules[Rulevariable]() {
+						goto l77
+					}
+				}
+			l79:
+				depth--
+				add(Ruletarget, position78)
+			}
+			return true
+		l77:
+			position, tokenIndex, depth = position77, tokenIndex77, depth77
+			return false
+		},
+		/* 16 labeling <- <(kwlabel minspace variable optspace)> */
+		func() bool {
+			position82, tokenIndex82, depth82 := position, tokenIndex, depth
+			{
+				position83 := position
+				depth++
+				if !rules[Rulekwlabel]() {
+					goto l82
 				}
 				if !rules[Ruleminspace]() {
-					goto l52
+					goto l82
 				}
-				if !rules[Rulecomparison_paren]() {
-					goto l52
+				if !rules[Rulevariable]() {
+					goto l82
 				}
-				if !rules[Rulecodeblock]() {
-					goto l52
+				if !rules[Ruleoptspace]() {
+					goto l82
 				}
 				depth--
-				add(Ruleifblock, position53)
+				add(Rulelabeling, position83)
 			}
 			return true
-		l52:
-			position, tokenIndex, depth = position52, tokenIndex52, depth52
+		l82:
+			position, tokenIndex, depth = position82, tokenIndex82, depth82
 			return false
 		},
-		/* 16 elseblock <- <(kwelse optspace codeblock)> */
+		/* 17 jumping <- <(kwjump minspace variable optspace)> */
 		func() bool {
-			position54, tokenIndex54, depth54 := position, tokenIndex, depth
+			position84, tokenIndex84, depth84 := position, tokenIndex, depth
 			{
-				position55 := position
+				position85 := position
 				depth++
-				if !rules[Rulekwelse]() {
-					goto l54
+				if !rules[Rulekwjump]() {
+					goto l84
+				}
+				if !rules[Ruleminspace]() {
+					goto l84
+				}
+				if !rules[Rulevariable]() {
+					goto l84
 				}
 				if !rules[Ruleoptspace]() {
-					goto l54
+					goto l84
+				}
+				depth--
+				add(Rulejumping, position85)
+			}
+			return true
+		l84:
+			position, tokenIndex, depth = position84, tokenIndex84, depth84
+			return false
+		},
+		/* 18 breaking <- <kwbreak> */
+		func() bool {
+			position86, tokenIndex86, depth86 := position, tokenIndex, depth
+			{
+				position87 := position
+				depth++
+				if !rules[Rulekwbreak]() {
+					goto l86
+				}
+				depth--
+				add(Rulebreaking, position87)
+			}
+			return true
+		l86:
+			position, tokenIndex, depth = position86, tokenIndex86, depth86
+			return false
+		},
+		/* 19 continuing <- <kwcontinue> */
+		func() bool {
+			position88, tokenIndex88, depth88 := position, tokenIndex, depth
+			{
+				position89 := position
+				depth++
+				if !rules[Rulekwcontinue]() {
+					goto l88
+				}
+				depth--
+				add(Rulecontinuing, position89)
+			}
+			return true
+		l88:
+			position, tokenIndex, depth = position88, tokenIndex88, depth88
+			return false
+		},
+		/* 20 value <- <(funccall / float / number / string / reference / member / element / variable)> */
+		func() bool {
+			position90, tokenIndex90, depth90 := position, tokenIndex, depth
+			{
+				position91 := position
+				depth++
+				{
+					position92, tokenIndex92, depth92 := position, tokenIndex, depth
+					if !rules[Rulefunccall]() {
+						goto l93
+					}
+					goto l92
+				l93:
+					position, tokenIndex, depth = position92, tokenIndex92, depth92
+					if !rules[Rulefloat]() {
+						goto l94
+					}
+					goto l92
+				l94:
+					position, tokenIndex, depth = position92, tokenIndex92, depth92
+					if !rules[Rulenumber]() {
+						goto l95
+					}
+					goto l92
+				l95:
+					position, tokenIndex, depth = position92, tokenIndex92, depth92
+					if !rules[Rulestring]() {
+						goto l96
+					}
+					goto l92
+				l96:
+					position, tokenIndex, depth = position92, tokenIndex92, depth92
+					if !rules[Rulereference]() {
+						goto l97
+					}
+					goto l92
+				l97:
+					position, tokenIndex, depth = position92, tokenIndex92, depth92
+					if !rules[Rulemember]() {
+						goto l98
+					}
+					goto l92
+				l98:
+					position, tokenIndex, depth = position92, tokenIndex92, depth92
+					if !rules[Ruleelement]() {
+						goto l99
+					}
+					goto l92
+				l99:
+					position, tokenIndex, depth = position92, tokenIndex92, depth92
+					if !rules[Rulevariable]() {
+						goto l90
+					}
+				}
+			l92:
+				depth--
+				add(Rulevalue, position91)
+			}
+			return true
+		l90:
+			position, tokenIndex, depth = position90, tokenIndex90, depth90
+			return false
+		},
+		/* 21 element <- <(variable optspace bopen expr bclose)> */
+		func() bool {
+			position100, tokenIndex100, depth100 := position, tokenIndex, depth
+			{
+				position101 := position
+				depth++
+				if !rules[Rulevariable]() {
+					goto l100
+				}
+				if !rules[Ruleoptspace]() {
+					goto l100
+				}
+				if !rules[Rulebopen]() {
+					goto l100
+				}
+				if !rules[Ruleexpr]() {
+					goto l100
+				}
+				if !rules[Rulebclose]() {
+					goto l100
+				}
+				depth--
+				add(Ruleelement, position101)
+			}
+			return true
+		l100:
+			position, tokenIndex, depth = position100, tokenIndex100, depth100
+			return false
+		},
+		/* 22 member <- <(variable '.' fieldname)> */
+		func() bool {
+			position102, tokenIndex102, depth102 := position, tokenIndex, depth
+			{
+				position103 := position
+				depth++
+				if !rules[Rulevariable]() {
+					goto l102
+				}
+				if buffer[position] != '.' {
+					goto l102
+				}
+				position++
+				if !rules[Rulefieldname]() {
+					goto l102
+				}
+				depth--
+				add(Rulemember, position103)
+			}
+			return true
+		l102:
+			position, tokenIndex, depth = position102, tokenIndex102, depth102
+			return false
+		},
+		/* 23 funccall <- <(funcidentifier '(' optspace callparams? ')')> */
+		func() bool {
+			position104, tokenIndex104, depth104 := position, tokenIndex, depth
+			{
+				position105 := position
+				depth++
+				if !rules[Rulefuncidentifier]() {
+					goto l104
+				}
+				if buffer[position] != '(' {
+					goto l104
+				}
+				position++
+				if !rules[Ruleoptspace]() {
+					goto l104
+				}
+				{
+					position106, tokenIndex106, depth106 := position, tokenIndex, depth
+					if !rules[Rulecallparams]() {
+						goto l106
+					}
+					goto l107
+				l106:
+					position, tokenIndex, depth = position106, tokenIndex106, depth106
+				}
+			l107:
+				if buffer[position] != ')' {
+					goto l104
+				}
+				position++
+				depth--
+				add(Rulefunccall, position105)
+			}
+			return true
+		l104:
+			position, tokenIndex, depth = position104, tokenIndex104, depth104
+			return false
+		},
+		/* 24 reference <- <('&' funcidentifier)> */
+		func() bool {
+			position108, tokenIndex108, depth108 := position, tokenIndex, depth
+			{
+				position109 := position
+				depth++
+				if buffer[position] != '&' {
+					goto l108
+				}
+				position++
+				if !rules[Rulefuncidentifier]() {
+					goto l108
+				}
+				depth--
+				add(Rulereference, position109)
+			}
+			return true
+		l108:
+			position, tokenIndex, depth = position108, tokenIndex108, depth108
+			return false
+		},
+		/* 25 codestatement <- <(logicblock / loop / routine / operation)> */
+		func() bool {
+			position110, tokenIndex110, depth110 := position, tokenIndex, depth
+			{
+				position111 := position
+				depth++
+				{
+					position112, tokenIndex112, depth112 := position, tokenIndex, depth
+					if !rules[Rulelogicblock]() {
+						goto l113
+					}
+					goto l112
+				l113:
+					position, tokenIndex, depth = position112, tokenIndex112, depth112
+					if !rules[Ruleloop]() {
+						goto l114
+					}
+					goto l112
+				l114:
+					position, tokenIndex, depth = position112, tokenIndex112, depth112
+					if !rules[Ruleroutine]() {
+						goto l115
+					}
+					goto l112
+				l115:
+					position, tokenIndex, depth = position112, tokenIndex112, depth112
+					if !rules[Ruleoperation]() {
+						goto l110
+					}
+				}
+			l112:
+				depth--
+				add(Rulecodestatement, position111)
+			}
+			return true
+		l110:
+			position, tokenIndex, depth = position110, tokenIndex110, depth110
+			return false
+		},
+		/* 26 codeblock <- <(optspace '{' (optspace codestatement)* optspace '}' optspace)> */
+		func() bool {
+			position116, tokenIndex116, depth116 := position, tokenIndex, depth
+			{
+				position117 := position
+				depth++
+				if !rules[Ruleoptspace]() {
+					goto l116
+				}
+				if buffer[position] != '{' {
+					goto l116
+				}
+				position++
+			l118:
+				{
+					position119, tokenIndex119, depth119 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l119
+					}
+					if !rules[Rulecodestatement]() {
+						goto l119
+					}
+					goto l118
+				l119:
+					position, tokenIndex, depth = position119, tokenIndex119, depth119
+				}
+				if !rules[Ruleoptspace]() {
+					goto l116
+				}
+				if buffer[position] != '}' {
+					goto l116
+				}
+				position++
+				if !rules[Ruleoptspace]() {
+					goto l116
+				}
+				depth--
+				add(Rulecodeblock, position117)
+			}
+			return true
+		l116:
+			position, tokenIndex, depth = position116, tokenIndex116, depth116
+			return false
+		},
+		/* 27 logicblock <- <(ifblock (optspace elseblock)?)> */
+		func() bool {
+			position120, tokenIndex120, depth120 := position, tokenIndex, depth
+			{
+				position121 := position
+				depth++
+				if !rules[Ruleifblock]() {
+					goto l120
+				}
+				{
+					position122, tokenIndex122, depth122 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l122
+					}
+					if !rules[Ruleelseblock]() {
+						goto l122
+					}
+					goto l123
+				l122:
+					position, tokenIndex, depth = position122, tokenIndex122, depth122
+				}
+			l123:
+				depth--
+				add(Rulelogicblock, position121)
+			}
+			return true
+		l120:
+			position, tokenIndex, depth = position120, tokenIndex120, depth120
+			return false
+		},
+		/* 28 ifblock <- <(kwif optspace comparison_paren codeblock)> */
+		func() bool {
+			position124, tokenIndex124, depth124 := position, tokenIndex, depth
+			{
+				position125 := position
+				depth++
+				if !rules[Rulekwif]() {
+					goto l124
+				}
+				if !rules[Ruleoptspace]() {
+					goto l124
+				}
+				if !rules[Rulecomparison_paren]() {
+					goto l124
+				}
+				if !rules[Rulecodeblock]() {
+					goto l124
+				}
+				depth--
+				add(Ruleifblock, position125)
+			}
+			return true
+		l124:
+			position, tokenIndex, depth = position124, tokenIndex124, depth124
+			return false
+		},
+		/* 29 elseblock <- <(kwelse optspace codeblock)> */
+		func() bool {
+			position126, tokenIndex126, depth126 := position, tokenIndex, depth
+			{
+				position127 := position
+				depth++
+				if !rules[Rulekwelse]() {
+					goto l126
+				}
+				if !rules[Ruleoptspace]() {
+					goto l126
+				}
+				if !rules[Rulecodeblock]() {
+					goto l126
+				}
+				depth--
+				add(Ruleelseblock, position127)
+			}
+			return true
+		l126:
+			position, tokenIndex, depth = position126, tokenIndex126, depth126
+			return false
+		},
+		/* 30 loop <- <(whileloop / forloop)> */
+		func() bool {
+			position128, tokenIndex128, depth128 := position, tokenIndex, depth
+			{
+				position129 := position
+				depth++
+				{
+					position130, tokenIndex130, depth130 := position, tokenIndex, depth
+					if !rules[Rulewhileloop]() {
+						goto l131
+					}
+					goto l130
+				l131:
+					position, tokenIndex, depth = position130, tokenIndex130, depth130
+					if !rules[Ruleforloop]() {
+						goto l128
+					}
+				}
+			l130:
+				depth--
+				add(Ruleloop, position129)
+			}
+			return true
+		l128:
+			position, tokenIndex, depth = position128, tokenIndex128, depth128
+			return false
+		},
+		/* 31 whileloop <- <(kwwhile optspace comparison_paren codeblock)> */
+		func() bool {
+			position132, tokenIndex132, depth132 := position, tokenIndex, depth
+			{
+				position133 := position
+				depth++
+				if !rules[Rulekwwhile]() {
+					goto l132
+				}
+				if !rules[Ruleoptspace]() {
+					goto l132
+				}
+				if !rules[Rulecomparison_paren]() {
+					goto l132
+				}
+				if !rules[Rulecodeblock]() {
+					goto l132
+				}
+				depth--
+				add(Rulewhileloop, position133)
+			}
+			return true
+		l132:
+			position, tokenIndex, depth = position132, tokenIndex132, depth132
+			return false
+		},
+		/* 32 forloop <- <(kwfor optspace popen forinit? endl optspace condition? endl optspace forstep? pclose codeblock)> */
+		func() bool {
+			position134, tokenIndex134, depth134 := position, tokenIndex, depth
+			{
+				position135 := position
+				depth++
+				if !rules[Rulekwfor]() {
+					goto l134
+				}
+				if !rules[Ruleoptspace]() {
+					goto l134
+				}
+				if !rules[Rulepopen]() {
+					goto l134
+				}
+				{
+					position136, tokenIndex136, depth136 := position, tokenIndex, depth
+					if !rules[Ruleforinit]() {
+						goto l136
+					}
+					goto l137
+				l136:
+					position, tokenIndex, depth = position136, tokenIndex136, depth136
+				}
+			l137:
+				if !rules[Ruleendl]() {
+					goto l134
+				}
+				if !rules[Ruleoptspace]() {
+					goto l134
+				}
+				{
+					position138, tokenIndex138, depth138 := position, tokenIndex, depth
+					if !rules[Rulecondition]() {
+						goto l138
+					}
+					goto l139
+				l138:
+					position, tokenIndex, depth = position138, tokenIndex138, depth138
+				}
+			l139:
+				if !rules[Ruleendl]() {
+					goto l134
+				}
+				if !rules[Ruleoptspace]() {
+					goto l134
+				}
+				{
+					position140, tokenIndex140, depth140 := position, tokenIndex, depth
+					if !rules[Ruleforstep]() {
+						goto l140
+					}
+					goto l141
+				l140:
+					position, tokenIndex, depth = position140, tokenIndex140, depth140
+				}
+			l141:
+				if !rules[Rulepclose]() {
+					goto l134
+				}
+				if !rules[Rulecodeblock]() {
+					goto l134
+				}
+				depth--
+				add(Ruleforloop, position135)
+			}
+			return true
+		l134:
+			position, tokenIndex, depth = position134, tokenIndex134, depth134
+			return false
+		},
+		/* 33 forinit <- <assignment> */
+		func() bool {
+			position142, tokenIndex142, depth142 := position, tokenIndex, depth
+			{
+				position143 := position
+				depth++
+				if !rules[Ruleassignment]() {
+					goto l142
+				}
+				depth--
+				add(Ruleforinit, position143)
+			}
+			return true
+		l142:
+			position, tokenIndex, depth = position142, tokenIndex142, depth142
+			return false
+		},
+		/* 34 forstep <- <assignment> */
+		func() bool {
+			position144, tokenIndex144, depth144 := position, tokenIndex, depth
+			{
+				position145 := position
+				depth++
+				if !rules[Ruleassignment]() {
+					goto l144
+				}
+				depth--
+				add(Ruleforstep, position145)
+			}
+			return true
+		l144:
+			position, tokenIndex, depth = position144, tokenIndex144, depth144
+			return false
+		},
+		/* 35 variable <- <(([a-z] / [A-Z])+ ([a-z] / [A-Z] / [0-9])*)> */
+		func() bool {
+			position146, tokenIndex146, depth146 := position, tokenIndex, depth
+			{
+				position147 := position
+				depth++
+				{
+					position150, tokenIndex150, depth150 := position, tokenIndex, depth
+					if c := buffer[position]; c < 'a' || c > 'z' {
+						goto l151
+					}
+					position++
+					goto l150
+				l151:
+					position, tokenIndex, depth = position150, tokenIndex150, depth150
+					if c := buffer[position]; c < 'A' || c > 'Z' {
+						goto l146
+					}
+					position++
+				}
+			l150:
+			l148:
+				{
+					position149, tokenIndex149, depth149 := position, tokenIndex, depth
+					{
+						position152, tokenIndex152, depth152 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l153
+						}
+						position++
+						goto l152
+					l153:
+						position, tokenIndex, depth = position152, tokenIndex152, depth152
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l149
+						}
+						position++
+					}
+				l152:
+					goto l148
+				l149:
+					position, tokenIndex, depth = position149, tokenIndex149, depth149
+				}
+			l154:
+				{
+					position155, tokenIndex155, depth155 := position, tokenIndex, depth
+					{
+						position156, tokenIndex156, depth156 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l157
+						}
+						position++
+						goto l156
+					l157:
+						position, tokenIndex, depth = position156, tokenIndex156, depth156
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l158
+						}
+						position++
+						goto l156
+					l158:
+						position, tokenIndex, depth = position156, tokenIndex156, depth156
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l155
+						}
+						position++
+					}
+				l156:
+					goto l154
+				l155:
+					position, tokenIndex, depth = position155, tokenIndex155, depth155
+				}
+				depth--
+				add(Rulevariable, position147)
+			}
+			return true
+		l146:
+			position, tokenIndex, depth = position146, tokenIndex146, depth146
+			return false
+		},
+		/* 36 funcIdDecl <- <variable> */
+		func() bool {
+			position159, tokenIndex159, depth159 := position, tokenIndex, depth
+			{
+				position160 := position
+				depth++
+				if !rules[Rulevariable]() {
+					goto l159
+				}
+				depth--
+				add(RulefuncIdDecl, position160)
+			}
+			return true
+		l159:
+			position, tokenIndex, depth = position159, tokenIndex159, depth159
+			return false
+		},
+		/* 37 funcidentifier <- <((module '.')? variable)> */
+		func() bool {
+			position161, tokenIndex161, depth161 := position, tokenIndex, depth
+			{
+				position162 := position
+				depth++
+				{
+					position163, tokenIndex163, depth163 := position, tokenIndex, depth
+					if !rules[Rulemodule]() {
+						goto l163
+					}
+					if buffer[position] != '.' {
+						goto l163
+					}
+					position++
+					goto l164
+				l163:
+					position, tokenIndex, depth = position163, tokenIndex163, depth163
+				}
+			l164:
+				if !rules[Rulevariable]() {
+					goto l161
+				}
+				depth--
+				add(Rulefuncidentifier, position162)
+			}
+			return true
+		l161:
+			position, tokenIndex, depth = position161, tokenIndex161, depth161
+			return false
+		},
+		/* 38 module <- <(([a-z] / [A-Z])+ ([a-z] / [A-Z] / [0-9])*)> */
+		func() bool {
+			position165, tokenIndex165, depth165 := position, tokenIndex, depth
+			{
+				position166 := position
+				depth++
+				{
+					position169, tokenIndex169, depth169 := position, tokenIndex, depth
+					if c := buffer[position]; c < 'a' || c > 'z' {
+						goto l170
+					}
+					position++
+					goto l169
+				l170:
+					position, tokenIndex, depth = position169, tokenIndex169, depth169
+					if c := buffer[position]; c < 'A' || c > 'Z' {
+						goto l165
+					}
+					position++
+				}
+			l169:
+			l167:
+				{
+					position168, tokenIndex168, depth168 := position, tokenIndex, depth
+					{
+						position171, tokenIndex171, depth171 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l172
+						}
+						position++
+						goto l171
+					l172:
+						position, tokenIndex, depth = position171, tokenIndex171, depth171
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l168
+						}
+						position++
+					}
+				l171:
+					goto l167
+				l168:
+					position, tokenIndex, depth = position168, tokenIndex168, depth168
+				}
+			l173:
+				{
+					position174, tokenIndex174, depth174 := position, tokenIndex, depth
+					{
+						position175, tokenIndex175, depth175 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l176
+						}
+						position++
+						goto l175
+					l176:
+						position, tokenIndex, depth = position175, tokenIndex175, depth175
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l177
+						}
+						position++
+						goto l175
+					l177:
+						position, tokenIndex, depth = position175, tokenIndex175, depth175
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l174
+						}
+						position++
+					}
+				l175:
+					goto l173
+				l174:
+					position, tokenIndex, depth = position174, tokenIndex174, depth174
+				}
+				depth--
+				add(Rulemodule, position166)
+			}
+			return true
+		l165:
+			position, tokenIndex, depth = position165, tokenIndex165, depth165
+			return false
+		},
+		/* 39 paramaterdecl <- <('<' optspace parameters? '>')> */
+		func() bool {
+			position178, tokenIndex178, depth178 := position, tokenIndex, depth
+			{
+				position179 := position
+				depth++
+				if buffer[position] != '<' {
+					goto l178
+				}
+				position++
+				if !rules[Ruleoptspace]() {
+					goto l178
+				}
+				{
+					position180, tokenIndex180, depth180 := position, tokenIndex, depth
+					if !rules[Ruleparameters]() {
+						goto l180
+					}
+					goto l181
+				l180:
+					position, tokenIndex, depth = position180, tokenIndex180, depth180
+				}
+			l181:
+				if buffer[position] != '>' {
+					goto l178
+				}
+				position++
+				depth--
+				add(Ruleparamaterdecl, position179)
+			}
+			return true
+		l178:
+			position, tokenIndex, depth = position178, tokenIndex178, depth178
+			return false
+		},
+		/* 40 callparams <- <(expr (comma expr)* optspace)> */
+		func() bool {
+			position182, tokenIndex182, depth182 := position, tokenIndex, depth
+			{
+				position183 := position
+				depth++
+				if !rules[Ruleexpr]() {
+					goto l182
+				}
+			l184:
+				{
+					position185, tokenIndex185, depth185 := position, tokenIndex, depth
+					if !rules[Rulecomma]() {
+						goto l185
+					}
+					if !rules[Ruleexpr]() {
+						goto l185
+					}
+					goto l184
+				l185:
+					position, tokenIndex, depth = position185, tokenIndex185, depth185
+				}
+				if !rules[Ruleoptspace]() {
+					goto l182
+				}
+				depth--
+				add(Rulecallparams, position183)
+			}
+			return true
+		l182:
+			position, tokenIndex, depth = position182, tokenIndex182, depth182
+			return false
+		},
+		/* 41 parameters <- <(parameter (comma parameter)* optspace)> */
+		func() bool {
+			position186, tokenIndex186, depth186 := position, tokenIndex, depth
+			{
+				position187 := position
+				depth++
+				if !rules[Ruleparameter]() {
+					goto l186
+				}
+			l188:
+				{
+					position189, tokenIndex189, depth189 := position, tokenIndex, depth
+					if !rules[Rulecomma]() {
+						goto l189
+					}
+					if !rules[Ruleparameter]() {
+						goto l189
+					}
+					goto l188
+				l189:
+					position, tokenIndex, depth = position189, tokenIndex189, depth189
+				}
+				if !rules[Ruleoptspace]() {
+					goto l186
+				}
+				depth--
+				add(Ruleparameters, position187)
+			}
+			return true
+		l186:
+			position, tokenIndex, depth = position186, tokenIndex186, depth186
+			return false
+		},
+		/* 42 parameter <- <(variable (optspace annotation)?)> */
+		func() bool {
+			position190, tokenIndex190, depth190 := position, tokenIndex, depth
+			{
+				position191 := position
+				depth++
+				if !rules[Rulevariable]() {
+					goto l190
+				}
+				{
+					position192, tokenIndex192, depth192 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l192
+					}
+					if !rules[Ruleannotation]() {
+						goto l192
+					}
+					goto l193
+				l192:
+					position, tokenIndex, depth = position192, tokenIndex192, depth192
+				}
+			l193:
+				depth--
+				add(Ruleparameter, position191)
+			}
+			return true
+		l190:
+			position, tokenIndex, depth = position190, tokenIndex190, depth190
+			return false
+		},
+		/* 43 annotation <- <(':' optspace typename)> */
+		func() bool {
+			position194, tokenIndex194, depth194 := position, tokenIndex, depth
+			{
+				position195 := position
+				depth++
+				if buffer[position] != ':' {
+					goto l194
+				}
+				position++
+				if !rules[Ruleoptspace]() {
+					goto l194
+				}
+				if !rules[Ruletypename]() {
+					goto l194
+				}
+				depth--
+				add(Ruleannotation, position195)
+			}
+			return true
+		l194:
+			position, tokenIndex, depth = position194, tokenIndex194, depth194
+			return false
+		},
+		/* 44 results <- <(':' optspace typename (comma typename)*)> */
+		func() bool {
+			position196, tokenIndex196, depth196 := position, tokenIndex, depth
+			{
+				position197 := position
+				depth++
+				if buffer[position] != ':' {
+					goto l196
+				}
+				position++
+				if !rules[Ruleoptspace]() {
+					goto l196
+				}
+				if !rules[Ruletypename]() {
+					goto l196
+				}
+			l198:
+				{
+					position199, tokenIndex199, depth199 := position, tokenIndex, depth
+					if !rules[Rulecomma]() {
+						goto l199
+					}
+					if !rules[Ruletypename]() {
+						goto l199
+					}
+					goto l198
+				l199:
+					position, tokenIndex, depth = position199, tokenIndex199, depth199
+				}
+				depth--
+				add(Ruleresults, position197)
+			}
+			return true
+		l196:
+			position, tokenIndex, depth = position196, tokenIndex196, depth196
+			return false
+		},
+		/* 45 typename <- <(([a-z] / [A-Z])+ ([a-z] / [A-Z] / [0-9])*)> */
+		func() bool {
+			position200, tokenIndex200, depth200 := position, tokenIndex, depth
+			{
+				position201 := position
+				depth++
+				{
+					position204, tokenIndex204, depth204 := position, tokenIndex, depth
+					if c := buffer[position]; c < 'a' || c > 'z' {
+						goto l205
+					}
+					position++
+					goto l204
+				l205:
+					position, tokenIndex, depth = position204, tokenIndex204, depth204
+					if c := buffer[position]; c < 'A' || c > 'Z' {
+						goto l200
+					}
+					position++
+				}
+			l204:
+			l202:
+				{
+					position203, tokenIndex203, depth203 := position, tokenIndex, depth
+					{
+						position206, tokenIndex206, depth206 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l207
+						}
+						position++
+						goto l206
+					l207:
+						position, tokenIndex, depth = position206, tokenIndex206, depth206
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l203
+						}
+						position++
+					}
+				l206:
+					goto l202
+				l203:
+					position, tokenIndex, depth = position203, tokenIndex203, depth203
+				}
+			l208:
+				{
+					position209, tokenIndex209, depth209 := position, tokenIndex, depth
+					{
+						position210, tokenIndex210, depth210 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l211
+						}
+						position++
+						goto l210
+					l211:
+						position, tokenIndex, depth = position210, tokenIndex210, depth210
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l212
+						}
+						position++
+						goto l210
+					l212:
+						position, tokenIndex, depth = position210, tokenIndex210, depth210
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l209
+						}
+						position++
+					}
+				l210:
+					goto l208
+				l209:
+					position, tokenIndex, depth = position209, tokenIndex209, depth209
+				}
+				depth--
+				add(Ruletypename, position201)
+			}
+			return true
+		l200:
+			position, tokenIndex, depth = position200, tokenIndex200, depth200
+			return false
+		},
+		/* 46 fieldname <- <(([a-z] / [A-Z])+ ([a-z] / [A-Z] / [0-9])*)> */
+		func() bool {
+			position213, tokenIndex213, depth213 := position, tokenIndex, depth
+			{
+				position214 := position
+				depth++
+				{
+					position217, tokenIndex217, depth217 := position, tokenIndex, depth
+					if c := buffer[position]; c < 'a' || c > 'z' {
+						goto l218
+					}
+					position++
+					goto l217
+				l218:
+					position, tokenIndex, depth = position217, tokenIndex217, depth217
+					if c := buffer[position]; c < 'A' || c > 'Z' {
+						goto l213
+					}
+					position++
+				}
+			l217:
+			l215:
+				{
+					position216, tokenIndex216, depth216 := position, tokenIndex, depth
+					{
+						position219, tokenIndex219, depth219 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l220
+						}
+						position++
+						goto l219
+					l220:
+						position, tokenIndex, depth = position219, tokenIndex219, depth219
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l216
+						}
+						position++
+					}
+				l219:
+					goto l215
+				l216:
+					position, tokenIndex, depth = position216, tokenIndex216, depth216
+				}
+			l221:
+				{
+					position222, tokenIndex222, depth222 := position, tokenIndex, depth
+					{
+						position223, tokenIndex223, depth223 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l224
+						}
+						position++
+						goto l223
+					l224:
+						position, tokenIndex, depth = position223, tokenIndex223, depth223
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l225
+						}
+						position++
+						goto l223
+					l225:
+						position, tokenIndex, depth = position223, tokenIndex223, depth223
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l222
+						}
+						position++
+					}
+				l223:
+					goto l221
+				l222:
+					position, tokenIndex, depth = position222, tokenIndex222, depth222
+				}
+				depth--
+				add(Rulefieldname, position214)
+			}
+			return true
+		l213:
+			position, tokenIndex, depth = position213, tokenIndex213, depth213
+			return false
+		},
+		/* 47 comma <- <(optspace ',' optspace)> */
+		func() bool {
+			position226, tokenIndex226, depth226 := position, tokenIndex, depth
+			{
+				position227 := position
+				depth++
+				if !rules[Ruleoptspace]() {
+					goto l226
+				}
+				if buffer[position] != ',' {
+					goto l226
+				}
+				position++
+				if !rules[Ruleoptspace]() {
+					goto l226
+				}
+				depth--
+				add(Rulecomma, position227)
+			}
+			return true
+		l226:
+			position, tokenIndex, depth = position226, tokenIndex226, depth226
+			return false
+		},
+		/* 48 kwreserve <- <('r' 'e' 's')> */
+		func() bool {
+			position228, tokenIndex228, depth228 := position, tokenIndex, depth
+			{
+				position229 := position
+				depth++
+				if buffer[position] != 'r' {
+					goto l228
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l228
+				}
+				position++
+				if buffer[position] != 's' {
+					goto l228
+				}
+				position++
+				depth--
+				add(Rulekwreserve, position229)
+			}
+			return true
+		l228:
+			position, tokenIndex, depth = position228, tokenIndex228, depth228
+			return false
+		},
+		/* 49 kwconst <- <('c' 'o' 'n' 's' 't')> */
+		func() bool {
+			position230, tokenIndex230, depth230 := position, tokenIndex, depth
+			{
+				position231 := position
+				depth++
+				if buffer[position] != 'c' {
+					goto l230
+				}
+				position++
+				if buffer[position] != 'o' {
+					goto l230
+				}
+				position++
+				if buffer[position] != 'n' {
+					goto l230
+				}
+				position++
+				if buffer[position] != 's' {
+					goto l230
+				}
+				position++
+				if buffer[position] != 't' {
+					goto l230
+				}
+				position++
+				depth--
+				add(Rulekwconst, position231)
+			}
+			return true
+		l230:
+			position, tokenIndex, depth = position230, tokenIndex230, depth230
+			return false
+		},
+		/* 50 kwglobal <- <('g' 'l' 'o' 'b' 'a' 'l')> */
+		func() bool {
+			position232, tokenIndex232, depth232 := position, tokenIndex, depth
+			{
+				position233 := position
+				depth++
+				if buffer[position] != 'g' {
+					goto l232
+				}
+				position++
+				if buffer[position] != 'l' {
+					goto l232
+				}
+				position++
+				if buffer[position] != 'o' {
+					goto l232
+				}
+				position++
+				if buffer[position] != 'b' {
+					goto l232
+				}
+				position++
+				if buffer[position] != 'a' {
+					goto l232
+				}
+				position++
+				if buffer[position] != 'l' {
+					goto l232
+				}
+				position++
+				depth--
+				add(Rulekwglobal, position233)
+			}
+			return true
+		l232:
+			position, tokenIndex, depth = position232, tokenIndex232, depth232
+			return false
+		},
+		/* 51 kwstruct <- <('s' 't' 'r' 'u' 'c' 't')> */
+		func() bool {
+			position234, tokenIndex234, depth234 := position, tokenIndex, depth
+			{
+				position235 := position
+				depth++
+				if buffer[position] != 's' {
+					goto l234
+				}
+				position++
+				if buffer[position] != 't' {
+					goto l234
+				}
+				position++
+				if buffer[position] != 'r' {
+					goto l234
+				}
+				position++
+				if buffer[position] != 'u' {
+					goto l234
+				}
+				position++
+				if buffer[position] != 'c' {
+					goto l234
+				}
+				position++
+				if buffer[position] != 't' {
+					goto l234
+				}
+				position++
+				depth--
+				add(Rulekwstruct, position235)
+			}
+			return true
+		l234:
+			position, tokenIndex, depth = position234, tokenIndex234, depth234
+			return false
+		},
+		/* 52 kwimport <- <('i' 'm' 'p' 'o' 'r' 't')> */
+		func() bool {
+			position236, tokenIndex236, depth236 := position, tokenIndex, depth
+			{
+				position237 := position
+				depth++
+				if buffer[position] != 'i' {
+					goto l236
+				}
+				position++
+				if buffer[position] != 'm' {
+					goto l236
+				}
+				position++
+				if buffer[position] != 'p' {
+					goto l236
+				}
+				position++
+				if buffer[position] != 'o' {
+					goto l236
+				}
+				position++
+				if buffer[position] != 'r' {
+					goto l236
+				}
+				position++
+				if buffer[position] != 't' {
+					goto l236
+				}
+				position++
+				depth--
+				add(Rulekwimport, position237)
+			}
+			return true
+		l236:
+			position, tokenIndex, depth = position236, tokenIndex236, depth236
+			return false
+		},
+		/* 53 kwas <- <('a' 's')> */
+		func() bool {
+			position238, tokenIndex238, depth238 := position, tokenIndex, depth
+			{
+				position239 := position
+				depth++
+				if buffer[position] != 'a' {
+					goto l238
+				}
+				position++
+				if buffer[position] != 's' {
+					goto l238
+				}
+				position++
+				depth--
+				add(Rulekwas, position239)
+			}
+			return true
+		l238:
+			position, tokenIndex, depth = position238, tokenIndex238, depth238
+			return false
+		},
+		/* 54 kwextern <- <('e' 'x' 't' 'e' 'r' 'n')> */
+		func() bool {
+			position240, tokenIndex240, depth240 := position, tokenIndex, depth
+			{
+				position241 := position
+				depth++
+				if buffer[position] != 'e' {
+					goto l240
+				}
+				position++
+				if buffer[position] != 'x' {
+					goto l240
+				}
+				position++
+				if buffer[position] != 't' {
+					goto l240
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l240
+				}
+				position++
+				if buffer[position] != 'r' {
+					goto l240
+				}
+				position++
+				if buffer[position] != 'n' {
+					goto l240
+				}
+				position++
+				depth--
+				add(Rulekwextern, position241)
+			}
+			return true
+		l240:
+			position, tokenIndex, depth = position240, tokenIndex240, depth240
+			return false
+		},
+		/* 55 kwreturn <- <('r' 'e' 't' 'u' 'r' 'n')> */
+		func() bool {
+			position242, tokenIndex242, depth242 := position, tokenIndex, depth
+			{
+				position243 := position
+				depth++
+				if buffer[position] != 'r' {
+					goto l242
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l242
+				}
+				position++
+				if buffer[position] != 't' {
+					goto l242
+				}
+				position++
+				if buffer[position] != 'u' {
+					goto l242
+				}
+				position++
+				if buffer[position] != 'r' {
+					goto l242
+				}
+				position++
+				if buffer[position] != 'n' {
+					goto l242
+				}
+				position++
+				depth--
+				add(Rulekwreturn, position243)
+			}
+			return true
+		l242:
+			position, tokenIndex, depth = position242, tokenIndex242, depth242
+			return false
+		},
+		/* 56 kwroutine <- <('r' 'o' 'u' 't' 'i' 'n' 'e')> */
+		func() bool {
+			position244, tokenIndex244, depth244 := position, tokenIndex, depth
+			{
+				position245 := position
+				depth++
+				if buffer[position] != 'r' {
+					goto l244
+				}
+				position++
+				if buffer[position] != 'o' {
+					goto l244
+				}
+				position++
+				if buffer[position] != 'u' {
+					goto l244
+				}
+				position++
+				if buffer[position] != 't' {
+					goto l244
+				}
+				position++
+				if buffer[position] != 'i' {
+					goto l244
+				}
+				position++
+				if buffer[position] != 'n' {
+					goto l244
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l244
+				}
+				position++
+				depth--
+				add(Rulekwroutine, position245)
+			}
+			return true
+		l244:
+			position, tokenIndex, depth = position244, tokenIndex244, depth244
+			return false
+		},
+		/* 57 kwjump <- <('j' 'u' 'm' 'p')> */
+		func() bool {
+			position246, tokenIndex246, depth246 := position, tokenIndex, depth
+			{
+				position247 := position
+				depth++
+				if buffer[position] != 'j' {
+					goto l246
+				}
+				position++
+				if buffer[position] != 'u' {
+					goto l246
+				}
+				position++
+				if buffer[position] != 'm' {
+					goto l246
+				}
+				position++
+				if buffer[position] != 'p' {
+					goto l246
+				}
+				position++
+				depth--
+				add(Rulekwjump, position247)
+			}
+			return true
+		l246:
+			position, tokenIndex, depth = position246, tokenIndex246, depth246
+			return false
+		},
+		/* 58 kwlabel <- <('l' 'a' 'b' 'e' 'l')> */
+		func() bool {
+			position248, tokenIndex248, depth248 := position, tokenIndex, depth
+			{
+				position249 := position
+				depth++
+				if buffer[position] != 'l' {
+					goto l248
+				}
+				position++
+				if buffer[position] != 'a' {
+					goto l248
+				}
+				position++
+				if buffer[position] != 'b' {
+					goto l248
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l248
+				}
+				position++
+				if buffer[position] != 'l' {
+					goto l248
+				}
+				position++
+				depth--
+				add(Rulekwlabel, position249)
+			}
+			return true
+		l248:
+			position, tokenIndex, depth = position248, tokenIndex248, depth248
+			return false
+		},
+		/* 59 kwif <- <('i' 'f')> */
+		func() bool {
+			position250, tokenIndex250, depth250 := position, tokenIndex, depth
+			{
+				position251 := position
+				depth++
+				if buffer[position] != 'i' {
+					goto l250
+				}
+				position++
+				if buffer[position] != 'f' {
+					goto l250
+				}
+				position++
+				depth--
+				add(Rulekwif, position251)
+			}
+			return true
+		l250:
+			position, tokenIndex, depth = position250, tokenIndex250, depth250
+			return false
+		},
+		/* 60 kwelse <- <('e' 'l' 's' 'e')> */
+		func() bool {
+			position252, tokenIndex252, depth252 := position, tokenIndex, depth
+			{
+				position253 := position
+				depth++
+				if buffer[position] != 'e' {
+					goto l252
+				}
+				position++
+				if buffer[position] != 'l' {
+					goto l252
+				}
+				position++
+				if buffer[position] != 's' {
+					goto l252
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l252
+				}
+				position++
+				depth--
+				add(Rulekwelse, position253)
+			}
+			return true
+		l252:
+			position, tokenIndex, depth = position252, tokenIndex252, depth252
+			return false
+		},
+		/* 61 kwwhile <- <('w' 'h' 'i' 'l' 'e')> */
+		func() bool {
+			position254, tokenIndex254, depth254 := position, tokenIndex, depth
+			{
+				position255 := position
+				depth++
+				if buffer[position] != 'w' {
+					goto l254
+				}
+				position++
+				if buffer[position] != 'h' {
+					goto l254
+				}
+				position++
+				if buffer[position] != 'i' {
+					goto l254
+				}
+				position++
+				if buffer[position] != 'l' {
+					goto l254
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l254
+				}
+				position++
+				depth--
+				add(Rulekwwhile, position255)
+			}
+			return true
+		l254:
+			position, tokenIndex, depth = position254, tokenIndex254, depth254
+			return false
+		},
+		/* 62 kwfor <- <('f' 'o' 'r')> */
+		func() bool {
+			position256, tokenIndex256, depth256 := position, tokenIndex, depth
+			{
+				position257 := position
+				depth++
+				if buffer[position] != 'f' {
+					goto l256
+				}
+				position++
+				if buffer[position] != 'o' {
+					goto l256
+				}
+				position++
+				if buffer[position] != 'r' {
+					goto l256
+				}
+				position++
+				depth--
+				add(Rulekwfor, position257)
+			}
+			return true
+		l256:
+			position, tokenIndex, depth = position256, tokenIndex256, depth256
+			return false
+		},
+		/* 63 kwbreak <- <('b' 'r' 'e' 'a' 'k')> */
+		func() bool {
+			position258, tokenIndex258, depth258 := position, tokenIndex, depth
+			{
+				position259 := position
+				depth++
+				if buffer[position] != 'b' {
+					goto l258
+				}
+				position++
+				if buffer[position] != 'r' {
+					goto l258
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l258
+				}
+				position++
+				if buffer[position] != 'a' {
+					goto l258
+				}
+				position++
+				if buffer[position] != 'k' {
+					goto l258
+				}
+				position++
+				depth--
+				add(Rulekwbreak, position259)
+			}
+			return true
+		l258:
+			position, tokenIndex, depth = position258, tokenIndex258, depth258
+			return false
+		},
+		/* 64 kwcontinue <- <('c' 'o' 'n' 't' 'i' 'n' 'u' 'e')> */
+		func() bool {
+			position260, tokenIndex260, depth260 := position, tokenIndex, depth
+			{
+				position261 := position
+				depth++
+				if buffer[position] != 'c' {
+					goto l260
+				}
+				position++
+				if buffer[position] != 'o' {
+					goto l260
+				}
+				position++
+				if buffer[position] != 'n' {
+					goto l260
+				}
+				position++
+				if buffer[position] != 't' {
+					goto l260
+				}
+				position++
+				if buffer[position] != 'i' {
+					goto l260
+				}
+				position++
+				if buffer[position] != 'n' {
+					goto l260
+				}
+				position++
+				if buffer[position] != 'u' {
+					goto l260
+				}
+				position++
+				if buffer[position] != 'e' {
+					goto l260
+				}
+				position++
+				depth--
+				add(Rulekwcontinue, position261)
+			}
+			return true
+		l260:
+			position, tokenIndex, depth = position260, tokenIndex260, depth260
+			return false
+		},
+		/* 65 tokadd <- <'+'> */
+		func() bool {
+			position262, tokenIndex262, depth262 := position, tokenIndex, depth
+			{
+				position263 := position
+				depth++
+				if buffer[position] != '+' {
+					goto l262
+				}
+				position++
+				depth--
+				add(Ruletokadd, position263)
+			}
+			return true
+		l262:
+			position, tokenIndex, depth = position262, tokenIndex262, depth262
+			return false
+		},
+		/* 66 toksub <- <'-'> */
+		func() bool {
+			position264, tokenIndex264, depth264 := position, tokenIndex, depth
+			{
+				position265 := position
+				depth++
+				if buffer[position] != '-' {
+					goto l264
+				}
+				position++
+				depth--
+				add(Ruletoksub, position265)
+			}
+			return true
+		l264:
+			position, tokenIndex, depth = position264, tokenIndex264, depth264
+			return false
+		},
+		/* 67 tokmul <- <'*'> */
+		func() bool {
+			position266, tokenIndex266, depth266 := position, tokenIndex, depth
+			{
+				position267 := position
+				depth++
+				if buffer[position] != '*' {
+					goto l266
+				}
+				position++
+				depth--
+				add(Ruletokmul, position267)
+			}
+			return true
+		l266:
+			position, tokenIndex, depth = position266, tokenIndex266, depth266
+			return false
+		},
+		/* 68 tokdiv <- <'/'> */
+		func() bool {
+			position268, tokenIndex268, depth268 := position, tokenIndex, depth
+			{
+				position269 := position
+				depth++
+				if buffer[position] != '/' {
+					goto l268
+				}
+				position++
+				depth--
+				add(Ruletokdiv, position269)
+			}
+			return true
+		l268:
+			position, tokenIndex, depth = position268, tokenIndex268, depth268
+			return false
+		},
+		/* 69 tokmod <- <'%'> */
+		func() bool {
+			position270, tokenIndex270, depth270 := position, tokenIndex, depth
+			{
+				position271 := position
+				depth++
+				if buffer[position] != '%' {
+					goto l270
+				}
+				position++
+				depth--
+				add(Ruletokmod, position271)
+			}
+			return true
+		l270:
+			position, tokenIndex, depth = position270, tokenIndex270, depth270
+			return false
+		},
+		/* 70 tokbitand <- <('&' !'&')> */
+		func() bool {
+			position272, tokenIndex272, depth272 := position, tokenIndex, depth
+			{
+				position273 := position
+				depth++
+				if buffer[position] != '&' {
+					goto l272
+				}
+				position++
+				{
+					position274, tokenIndex274, depth274 := position, tokenIndex, depth
+					if buffer[position] != '&' {
+						goto l274
+					}
+					position++
+					goto l272
+				l274:
+					position, tokenIndex, depth = position274, tokenIndex274, depth274
+				}
+				depth--
+				add(Ruletokbitand, position273)
+			}
+			return true
+		l272:
+			position, tokenIndex, depth = position272, tokenIndex272, depth272
+			return false
+		},
+		/* 71 tokbitor <- <('|' !'|')> */
+		func() bool {
+			position275, tokenIndex275, depth275 := position, tokenIndex, depth
+			{
+				position276 := position
+				depth++
+				if buffer[position] != '|' {
+					goto l275
+				}
+				position++
+				{
+					position277, tokenIndex277, depth277 := position, tokenIndex, depth
+					if buffer[position] != '|' {
+						goto l277
+					}
+					position++
+					goto l275
+				l277:
+					position, tokenIndex, depth = position277, tokenIndex277, depth277
+				}
+				depth--
+				add(Ruletokbitor, position276)
+			}
+			return true
+		l275:
+			position, tokenIndex, depth = position275, tokenIndex275, depth275
+			return false
+		},
+		/* 72 tokxor <- <'^'> */
+		func() bool {
+			position278, tokenIndex278, depth278 := position, tokenIndex, depth
+			{
+				position279 := position
+				depth++
+				if buffer[position] != '^' {
+					goto l278
+				}
+				position++
+				depth--
+				add(Ruletokxor, position279)
+			}
+			return true
+		l278:
+			position, tokenIndex, depth = position278, tokenIndex278, depth278
+			return false
+		},
+		/* 73 tokshl <- <('<' '<')> */
+		func() bool {
+			position280, tokenIndex280, depth280 := position, tokenIndex, depth
+			{
+				position281 := position
+				depth++
+				if buffer[position] != '<' {
+					goto l280
+				}
+				position++
+				if buffer[position] != '<' {
+					goto l280
+				}
+				position++
+				depth--
+				add(Ruletokshl, position281)
+			}
+			return true
+		l280:
+			position, tokenIndex, depth = position280, tokenIndex280, depth280
+			return false
+		},
+		/* 74 tokshr <- <('>' '>')> */
+		func() bool {
+			position282, tokenIndex282, depth282 := position, tokenIndex, depth
+			{
+				position283 := position
+				depth++
+				if buffer[position] != '>' {
+					goto l282
+				}
+				position++
+				if buffer[position] != '>' {
+					goto l282
+				}
+				position++
+				depth--
+				add(Ruletokshr, position283)
+			}
+			return true
+		l282:
+			position, tokenIndex, depth = position282, tokenIndex282, depth282
+			return false
+		},
+		/* 75 tokbitnot <- <'~'> */
+		func() bool {
+			position284, tokenIndex284, depth284 := position, tokenIndex, depth
+			{
+				position285 := position
+				depth++
+				if buffer[position] != '~' {
+					goto l284
+				}
+				position++
+				depth--
+				add(Ruletokbitnot, position285)
+			}
+			return true
+		l284:
+			position, tokenIndex, depth = position284, tokenIndex284, depth284
+			return false
+		},
+		/* 76 tokand <- <('&' '&')> */
+		func() bool {
+			position286, tokenIndex286, depth286 := position, tokenIndex, depth
+			{
+				position287 := position
+				depth++
+				if buffer[position] != '&' {
+					goto l286
+				}
+				position++
+				if buffer[position] != '&' {
+					goto l286
+				}
+				position++
+				depth--
+				add(Ruletokand, position287)
+			}
+			return true
+		l286:
+			position, tokenIndex, depth = position286, tokenIndex286, depth286
+			return false
+		},
+		/* 77 tokor <- <('|' '|')> */
+		func() bool {
+			position288, tokenIndex288, depth288 := position, tokenIndex, depth
+			{
+				position289 := position
+				depth++
+				if buffer[position] != '|' {
+					goto l288
 				}
-				if !rules[Rulecodeblock]() {
-					goto l54
+				position++
+				if buffer[position] != '|' {
+					goto l288
 				}
+				position++
 				depth--
-				add(Ruleelseblock, position55)
+				add(Ruletokor, position289)
 			}
 			return true
-		l54:
-			position, tokenIndex, depth = position54, tokenIndex54, depth54
+		l288:
+			position, tokenIndex, depth = position288, tokenIndex288, depth288
 			return false
 		},
-		/* 17 variable <- <(([a-z] / [A-Z])+ ([a-z] / [A-Z] / [0-9])*)> */
+		/* 78 toknot <- <'!'> */
 		func() bool {
-			position56, tokenIndex56, depth56 := position, tokenIndex, depth
+			position290, tokenIndex290, depth290 := position, tokenIndex, depth
 			{
-				position57 := position
+				position291 := position
 				depth++
-				{
-					position60, tokenIndex60, depth60 := position, tokenIndex, depth
-					if c := buffer[position]; c < 'a' || c > 'z' {
-						goto l61
-					}
-					position++
-					goto l60
-				l61:
-					position, tokenIndex, depth = position60, tokenIndex60, depth60
-					if c := buffer[position]; c < 'A' || c > 'Z' {
-						goto l56
-					}
-					position++
-				}
-			l60:
-			l58:
-				{
-					position59, tokenIndex59, depth59 := position, tokenIndex, depth
-					{
-						position62, tokenIndex62, depth62 := position, tokenIndex, depth
-						if c := buffer[position]; c < 'a' || c > 'z' {
-							goto l63
-						}
-						position++
-						goto l62
-					l63:
-						position, tokenIndex, depth = position62, tokenIndex62, depth62
-						if c := buffer[position]; c < 'A' || c > 'Z' {
-							goto l59
-						}
-						position++
-					}
-				l62:
-					goto l58
-				l59:
-					position, tokenIndex, depth = position59, tokenIndex59, depth59
-				}
-			l64:
-				{
-					position65, tokenIndex65, depth65 := position, tokenIndex, depth
-					{
-						position66, tokenIndex66, depth66 := position, tokenIndex, depth
-						if c := buffer[position]; c < 'a' || c > 'z' {
-							goto l67
-						}
-						position++
-						goto l66
-					l67:
-						position, tokenIndex, depth = position66, tokenIndex66, depth66
-						if c := buffer[position]; c < 'A' || c > 'Z' {
-							goto l68
-						}
-						position++
-						goto l66
-					l68:
-						position, tokenIndex, depth = position66, tokenIndex66, depth66
-						if c := buffer[position]; c < '0' || c > '9' {
-							goto l65
-						}
-						position++
-					}
-				l66:
-					goto l64
-				l65:
-					position, tokenIndex, depth = position65, tokenIndex65, depth65
+				if buffer[position] != '!' {
+					goto l290
 				}
+				position++
 				depth--
-				add(Rulevariable, position57)
+				add(Ruletoknot, position291)
 			}
 			return true
-		l56:
-			position, tokenIndex, depth = position56, tokenIndex56, depth56
+		l290:
+			position, tokenIndex, depth = position290, tokenIndex290, depth290
 			return false
 		},
-		/* 18 funcIdDecl <- <variable> */
+		/* 79 endl <- <(optspace ';')> */
 		func() bool {
-			position69, tokenIndex69, depth69 := position, tokenIndex, depth
+			position292, tokenIndex292, depth292 := position, tokenIndex, depth
 			{
-				position70 := position
+				position293 := position
 				depth++
-				if !rules[Rulevariable]() {
-					goto l69
+				if !rules[Ruleoptspace]() {
+					goto l292
+				}
+				if buffer[position] != ';' {
+					goto l292
 				}
+				position++
 				depth--
-				add(RulefuncIdDecl, position70)
+				add(Ruleendl, position293)
 			}
 			return true
-		l69:
-			position, tokenIndex, depth = position69, tokenIndex69, depth69
+		l292:
+			position, tokenIndex, depth = position292, tokenIndex292, depth292
 			return false
 		},
-		/* 19 funcidentifier <- <variable> */
+		/* 80 expr <- <(conjunction (optspace tokor optspace conjunction)*)> */
 		func() bool {
-			position71, tokenIndex71, depth71 := position, tokenIndex, depth
+			position294, tokenIndex294, depth294 := position, tokenIndex, depth
 			{
-				position72 := position
+				position295 := position
 				depth++
-				if !rules[Rulevariable]() {
-					goto l71
+				if !rules[Ruleconjunction]() {
+					goto l294
+				}
+			l296:
+				{
+					position297, tokenIndex297, depth297 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l297
+					}
+					if !rules[Ruletokor]() {
+						goto l297
+					}
+					if !rules[Ruleoptspace]() {
+						goto l297
+					}
+					if !rules[Ruleconjunction]() {
+						goto l297
+					}
+					goto l296
+				l297:
+					position, tokenIndex, depth = position297, tokenIndex297, depth297
 				}
 				depth--
-				add(Rulefuncidentifier, position72)
+				add(Ruleexpr, position295)
 			}
 			return true
-		l71:
-			position, tokenIndex, depth = position71, tokenIndex71, depth71
+		l294:
+			position, tokenIndex, depth = position294, tokenIndex294, depth294
 			return false
 		},
-		/* 20 paramaterdecl <- <('<' optspace parameters? '>')> */
+		/* 81 conjunction <- <(comparison (optspace tokand optspace comparison)*)> */
 		func() bool {
-			position73, tokenIndex73, depth73 := position, tokenIndex, depth
+			position298, tokenIndex298, depth298 := position, tokenIndex, depth
 			{
-				position74 := position
+				position299 := position
 				depth++
-				if buffer[position] != '<' {
-					goto l73
-				}
-				position++
-				if !rules[Ruleoptspace]() {
-					goto l73
+				if !rules[Rulecomparison]() {
+					goto l298
 				}
+			l300:
 				{
-					position75, tokenIndex75, depth75 := position, tokenIndex, depth
-					if !rules[Ruleparameters]() {
-						goto l75
+					position301, tokenIndex301, depth301 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l301
 					}
-					goto l76
-				l75:
-					position, tokenIndex, depth = position75, tokenIndex75, depth75
-				}
-			l76:
-				if buffer[position] != '>' {
-					goto l73
+					if !rules[Ruletokand]() {
+						goto l301
+					}
+					if !rules[Ruleoptspace]() {
+						goto l301
+					}
+					if !rules[Rulecomparison]() {
+						goto l301
+					}
+					goto l300
+				l301:
+					position, tokenIndex, depth = position301, tokenIndex301, depth301
 				}
-				position++
 				depth--
-				add(Ruleparamaterdecl, position74)
+				add(Ruleconjunction, position299)
 			}
 			return true
-		l73:
-			position, tokenIndex, depth = position73, tokenIndex73, depth73
+		l298:
+			position, tokenIndex, depth = position298, tokenIndex298, depth298
 			return false
 		},
-		/* 21 callparams <- <(value (comma value)* optspace)> */
+		/* 82 comparison <- <(sum (optspace comparisontoken optspace sum)?)> */
 		func() bool {
-			position77, tokenIndex77, depth77 := position, tokenIndex, depth
+			position302, tokenIndex302, depth302 := position, tokenIndex, depth
 			{
-				position78 := position
+				position303 := position
 				depth++
-				if !rules[Rulevalue]() {
-					goto l77
+				if !rules[Rulesum]() {
+					goto l302
 				}
-			l79:
 				{
-					position80, tokenIndex80, depth80 := position, tokenIndex, depth
-					if !rules[Rulecomma]() {
-						goto l80
+					position304, tokenIndex304, depth304 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l304
 					}
-					if !rules[Rulevalue]() {
-						goto l80
+					if !rules[Rulecomparisontoken]() {
+						goto l304
 					}
-					goto l79
-				l80:
-					position, tokenIndex, depth = position80, tokenIndex80, depth80
-				}
-				if !rules[Ruleoptspace]() {
-					goto l77
+					if !rules[Ruleoptspace]() {
+						goto l304
+					}
+					if !rules[Rulesum]() {
+						goto l304
+					}
+					goto l305
+				l304:
+					position, tokenIndex, depth = position304, tokenIndex304, depth304
 				}
+			l305:
 				depth--
-				add(Rulecallparams, position78)
+				add(Rulecomparison, position303)
 			}
 			return true
-		l77:
-			position, tokenIndex, depth = position77, tokenIndex77, depth77
+		l302:
+			position, tokenIndex, depth = position302, tokenIndex302, depth302
 			return false
 		},
-		/* 22 parameters <- <(variable (comma variable)* optspace)> */
+		/* 83 sum <- <(term (optspace (tokadd / toksub / tokbitor / tokxor) optspace term)*)> */
 		func() bool {
-			position81, tokenIndex81, depth81 := position, tokenIndex, depth
+			position306, tokenIndex306, depth306 := position, tokenIndex, depth
 			{
-				position82 := position
+				position307 := position
 				depth++
-				if !rules[Rulevariable]() {
-					goto l81
+				if !rules[Ruleterm]() {
+					goto l306
 				}
-			l83:
+			l308:
 				{
-					position84, tokenIndex84, depth84 := position, tokenIndex, depth
-					if !rules[Rulecomma]() {
-						goto l84
+					position309, tokenIndex309, depth309 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l309
 					}
-					if !rules[Rulevariable]() {
-						goto l84
+					{
+						position310, tokenIndex310, depth310 := position, tokenIndex, depth
+						if !rules[Ruletokadd]() {
+							goto l311
+						}
+						goto l310
+					l311:
+						position, tokenIndex, depth = position310, tokenIndex310, depth310
+						if !rules[Ruletoksub]() {
+							goto l312
+						}
+						goto l310
+					l312:
+						position, tokenIndex, depth = position310, tokenIndex310, depth310
+						if !rules[Ruletokbitor]() {
+							goto l313
+						}
+						goto l310
+					l313:
+						position, tokenIndex, depth = position310, tokenIndex310, depth310
+						if !rules[Ruletokxor]() {
+							goto l309
+						}
 					}
-					goto l83
-				l84:
-					position, tokenIndex, depth = position84, tokenIndex84, depth84
-				}
-				if !rules[Ruleoptspace]() {
-					goto l81
+				l310:
+					if !rules[Ruleoptspace]() {
+						goto l309
+					}
+					if !rules[Ruleterm]() {
+						goto l309
+					}
+					goto l308
+				l309:
+					position, tokenIndex, depth = position309, tokenIndex309, depth309
 				}
 				depth--
-				add(Ruleparameters, position82)
+				add(Rulesum, position307)
 			}
 			return true
-		l81:
-			position, tokenIndex, depth = position81, tokenIndex81, depth81
+		l306:
+			position, tokenIndex, depth = position306, tokenIndex306, depth306
 			return false
 		},
-		/* 23 comma <- <(optspace ',' optspace)> */
+		/* 84 term <- <(unary (optspace (tokmul / tokdiv / tokmod / tokshl / tokshr / tokbitand) optspace unary)*)> */
 		func() bool {
-			position85, tokenIndex85, depth85 := position, tokenIndex, depth
+			position314, tokenIndex314, depth314 := position, tokenIndex, depth
 			{
-				position86 := position
+				position315 := position
 				depth++
-				if !rules[Ruleoptspace]() {
-					goto l85
+				if !rules[Ruleunary]() {
+					goto l314
 				}
-				if buffer[position] != ',' {
-					goto l85
-				}
-				position++
-				if !rules[Ruleoptspace]() {
-					goto l85
+			l316:
+				{
+					position317, tokenIndex317, depth317 := position, tokenIndex, depth
+					if !rules[Ruleoptspace]() {
+						goto l317
+					}
+					{
+						position318, tokenIndex318, depth318 := position, tokenIndex, depth
+						if !rules[Ruletokmul]() {
+							goto l319
+						}
+						goto l318
+					l319:
+						position, tokenIndex, depth = position318, tokenIndex318, depth318
+						if !rules[Ruletokdiv]() {
+							goto l320
+						}
+						goto l318
+					l320:
+						position, tokenIndex, depth = position318, tokenIndex318, depth318
+						if !rules[Ruletokmod]() {
+							goto l321
+						}
+						goto l318
+					l321:
+						position, tokenIndex, depth = position318, tokenIndex318, depth318
+						if !rules[Ruletokshl]() {
+							goto l322
+						}
+						goto l318
+					l322:
+						position, tokenIndex, depth = position318, tokenIndex318, depth318
+						if !rules[Ruletokshr]() {
+							goto l323
+						}
+						goto l318
+					l323:
+						position, tokenIndex, depth = position318, tokenIndex318, depth318
+						if !rules[Ruletokbitand]() {
+							goto l317
+						}
+					}
+				l318:
+					if !rules[Ruleoptspace]() {
+						goto l317
+					}
+					if !rules[Ruleunary]() {
+						goto l317
+					}
+					goto l316
+				l317:
+					position, tokenIndex, depth = position317, tokenIndex317, depth317
 				}
 				depth--
-				add(Rulecomma, position86)
+				add(Ruleterm, position315)
 			}
 			return true
-		l85:
-			position, tokenIndex, depth = position85, tokenIndex85, depth85
+		l314:
+			position, tokenIndex, depth = position314, tokenIndex314, depth314
 			return false
 		},
-		/* 24 kwreserve <- <('r' 'e' 's')> */
+		/* 85 unary <- <(((toksub / toknot / tokbitnot) optspace unary) / primary)> */
 		func() bool {
-			position87, tokenIndex87, depth87 := position, tokenIndex, depth
+			position324, tokenIndex324, depth324 := position, tokenIndex, depth
 			{
-				position88 := position
+				position325 := position
 				depth++
-				if buffer[position] != 'r' {
-					goto l87
-				}
-				position++
-				if buffer[position] != 'e' {
-					goto l87
-				}
-				position++
-				if buffer[position] != 's' {
-					goto l87
+				{
+					position326, tokenIndex326, depth326 := position, tokenIndex, depth
+					{
+						position328, tokenIndex328, depth328 := position, tokenIndex, depth
+						if !rules[Ruletoksub]() {
+							goto l329
+						}
+						goto l328
+					l329:
+						position, tokenIndex, depth = position328, tokenIndex328, depth328
+						if !rules[Ruletoknot]() {
+							goto l330
+						}
+						goto l328
+					l330:
+						position, tokenIndex, depth = position328, tokenIndex328, depth328
+						if !rules[Ruletokbitnot]() {
+							goto l327
+						}
+					}
+				l328:
+					if !rules[Ruleoptspace]() {
+						goto l327
+					}
+					if !rules[Ruleunary]() {
+						goto l327
+					}
+					goto l326
+				l327:
+					position, tokenIndex, depth = position326, tokenIndex326, depth326
+					if !rules[Ruleprimary]() {
+						goto l324
+					}
 				}
-				position++
+			l326:
 				depth--
-				add(Rulekwreserve, position88)
+				add(Ruleunary, position325)
 			}
 			return true
-		l87:
-			position, tokenIndex, depth = position87, tokenIndex87, depth87
+		l324:
+			position, tokenIndex, depth = position324, tokenIndex324, depth324
 			return false
 		},
-		/* 25 kwreturn <- <('r' 'e' 't' 'u' 'r' 'n')> */
+		/* 86 primary <- <((popen expr pclose) / value)> */
 		func() bool {
-			position89, tokenIndex89, depth89 := position, tokenIndex, depth
+			position331, tokenIndex331, depth331 := position, tokenIndex, depth
 			{
-				position90 := position
+				position332 := position
 				depth++
-				if buffer[position] != 'r' {
-					goto l89
-				}
-				position++
-				if buffer[position] != 'e' {
-					goto l89
-				}
-				position++
-				if buffer[position] != 't' {
-					goto l89
-				}
-				position++
-				if buffer[position] != 'u' {
-					goto l89
-				}
-				position++
-				if buffer[position] != 'r' {
-					goto l89
-				}
-				position++
-				if buffer[position] != 'n' {
-					goto l89
+				{
+					position333, tokenIndex333, depth333 := position, tokenIndex, depth
+					if !rules[Rulepopen]() {
+						goto l334
+					}
+					if !rules[Ruleexpr]() {
+						goto l334
+					}
+					if !rules[Rulepclose]() {
+						goto l334
+					}
+					goto l333
+				l334:
+					position, tokenIndex, depth = position333, tokenIndex333, depth333
+					if !rules[Rulevalue]() {
+						goto l331
+					}
 				}
-				position++
+			l333:
 				depth--
-				add(Rulekwreturn, position90)
+				add(Ruleprimary, position332)
 			}
 			return true
-		l89:
-			position, tokenIndex, depth = position89, tokenIndex89, depth89
+		l331:
+			position, tokenIndex, depth = position331, tokenIndex331, depth331
 			return false
 		},
-		/* 26 kwroutine <- <('r' 'o' 'u' 't' 'i' 'n' 'e')> */
+		/* 87 popen <- <('(' optspace)> */
 		func() bool {
-			position91, tokenIndex91, depth91 := position, tokenIndex, depth
+			position335, tokenIndex335, depth335 := position, tokenIndex, depth
 			{
-				position92 := position
+				position336 := position
 				depth++
-				if buffer[position] != 'r' {
-					goto l91
-				}
-				position++
-				if buffer[position] != 'o' {
-					goto l91
-				}
-				position++
-				if buffer[position] != 'u' {
-					goto l91
-				}
-				position++
-				if buffer[position] != 't' {
-					goto l91
-				}
-				position++
-				if buffer[position] != 'i' {
-					goto l91
-				}
-				position++
-				if buffer[position] != 'n' {
-					goto l91
+				if buffer[position] != '(' {
+					goto l335
 				}
 				position++
-				if buffer[position] != 'e' {
-					goto l91
+				if !rules[Ruleoptspace]() {
+					goto l335
 				}
-				position++
 				depth--
-				add(Rulekwroutine, position92)
+				add(Rulepopen, position336)
 			}
 			return true
-		l91:
-			position, tokenIndex, depth = position91, tokenIndex91, depth91
+		l335:
+			position, tokenIndex, depth = position335, tokenIndex335, depth335
 			return false
 		},
-		/* 27 kwjump <- <('j' 'u' 'm' 'p')> */
+		/* 88 pclose <- <(')' optspace)> */
 		func() bool {
-			position93, tokenIndex93, depth93 := position, tokenIndex, depth
+			position337, tokenIndex337, depth337 := position, tokenIndex, depth
 			{
-				position94 := position
+				position338 := position
 				depth++
-				if buffer[position] != 'j' {
-					goto l93
-				}
-				position++
-				if buffer[position] != 'u' {
-					goto l93
-				}
-				position++
-				if buffer[position] != 'm' {
-					goto l93
+				if buffer[position] != ')' {
+					goto l337
 				}
 				position++
-				if buffer[position] != 'p' {
-					goto l93
+				if !rules[Ruleoptspace]() {
+					goto l337
 				}
-				position++
 				depth--
-				add(Rulekwjump, position94)
+				add(Rulepclose, position338)
 			}
 			return true
-		l93:
-			position, tokenIndex, depth = position93, tokenIndex93, depth93
+		l337:
+			position, tokenIndex, depth = position337, tokenIndex337, depth337
 			return false
 		},
-		/* 28 kwlabel <- <('l' 'a' 'b' 'e' 'l')> */
+		/* 89 bopen <- <('[' optspace)> */
 		func() bool {
-			position95, tokenIndex95, depth95 := position, tokenIndex, depth
+			position339, tokenIndex339, depth339 := position, tokenIndex, depth
 			{
-				position96 := position
+				position340 := position
 				depth++
-				if buffer[position] != 'l' {
-					goto l95
-				}
-				position++
-				if buffer[position] != 'a' {
-					goto l95
+				if buffer[position] != '[' {
+					goto l339
 				}
 				position++
-				if buffer[position] != 'b' {
-					goto l95
-				}
-				position++
-				if buffer[position] != 'e' {
-					goto l95
-				}
-				position++
-				if buffer[position] != 'l' {
-					goto l95
+				if !rules[Ruleoptspace]() {
+					goto l339
 				}
-				position++
 				depth--
-				add(Rulekwlabel, position96)
+				add(Rulebopen, position340)
 			}
 			return true
-		l95:
-			position, tokenIndex, depth = position95, tokenIndex95, depth95
+		l339:
+			position, tokenIndex, depth = position339, tokenIndex339, depth339
 			return false
 		},
-		/* 29 kwif <- <('i' 'f')> */
+		/* 90 bclose <- <(optspace ']')> */
 		func() bool {
-			position97, tokenIndex97, depth97 := position, tokenIndex, depth
+			position341, tokenIndex341, depth341 := position, tokenIndex, depth
 			{
-				position98 := position
+				position342 := position
 				depth++
-				if buffer[position] != 'i' {
-					goto l97
+				if !rules[Ruleoptspace]() {
+					goto l341
 				}
-				position++
-				if buffer[position] != 'f' {
-					goto l97
+				if buffer[position] != ']' {
+					goto l341
 				}
 				position++
 				depth--
-				add(Rulekwif, position98)
+				add(Rulebclose, position342)
 			}
 			return true
-		l97:
-			position, tokenIndex, depth = position97, tokenIndex97, depth97
+		l341:
+			position, tokenIndex, depth = position341, tokenIndex341, depth341
 			return false
 		},
-		/* 30 kwelse <- <('e' 'l' 's' 'e')> */
+		/* 91 toklt <- <'<'> */
 		func() bool {
-			position99, tokenIndex99, depth99 := position, tokenIndex, depth
+			position343, tokenIndex343, depth343 := position, tokenIndex, depth
 			{
-				position100 := position
+				position344 := position
 				depth++
-				if buffer[position] != 'e' {
-					goto l99
-				}
-				position++
-				if buffer[position] != 'l' {
-					goto l99
-				}
-				position++
-				if buffer[position] != 's' {
-					goto l99
-				}
-				position++
-				if buffer[position] != 'e' {
-					goto l99
+				if buffer[position] != '<' {
+					goto l343
 				}
 				position++
 				depth--
-				add(Rulekwelse, position100)
+				add(Ruletoklt, position344)
 			}
 			return true
-		l99:
-			position, tokenIndex, depth = position99, tokenIndex99, depth99
+		l343:
+			position, tokenIndex, depth = position343, tokenIndex343, depth343
 			return false
 		},
-		/* 31 tokadd <- <('+' optspace)> */
+		/* 92 tokgt <- <'>'> */
 		func() bool {
-			position101, tokenIndex101, depth101 := position, tokenIndex, depth
+			position345, tokenIndex345, depth345 := position, tokenIndex, depth
 			{
-				position102 := position
+				position346 := position
 				depth++
-				if buffer[position] != '+' {
-					goto l101
+				if buffer[position] != '>' {
+					goto l345
 				}
 				position++
-				if !rules[Ruleoptspace]() {
-					goto l101
-				}
 				depth--
-				add(Ruletokadd, position102)
+				add(Ruletokgt, position346)
 			}
 			return true
-		l101:
-			position, tokenIndex, depth = position101, tokenIndex101, depth101
+		l345:
+			position, tokenIndex, depth = position345, tokenIndex345, depth345
 			return false
 		},
-		/* 32 toksub <- <('-' optspace)> */
+		/* 93 tokeq <- <('=' '=')> */
 		func() bool {
-			position103, tokenIndex103, depth103 := position, tokenIndex, depth
+			position347, tokenIndex347, depth347 := position, tokenIndex, depth
 			{
-				position104 := position
+				position348 := position
 				depth++
-				if buffer[position] != '-' {
-					goto l103
+				if buffer[position] != '=' {
+					goto l347
 				}
 				position++
-				if !rules[Ruleoptspace]() {
-					goto l103
+				if buffer[position] != '=' {
+					goto l347
 				}
+				position++
 				depth--
-				add(Ruletoksub, position104)
+				add(Ruletokeq, position348)
 			}
 			return true
-		l103:
-			position, tokenIndex, depth = position103, tokenIndex103, depth103
+		l347:
+			position, tokenIndex, depth = position347, tokenIndex347, depth347
 			return false
 		},
-		/* 33 tokmul <- <('*' space)> */
+		/* 94 tokle <- <('<' '=')> */
 		func() bool {
-			position105, tokenIndex105, depth105 := position, tokenIndex, depth
+			position349, tokenIndex349, depth349 := position, tokenIndex, depth
 			{
-				position106 := position
+				position350 := position
 				depth++
-				if buffer[position] != '*' {
-					goto l105
+				if buffer[position] != '<' {
+					goto l349
 				}
 				position++
-				if !rules[Rulespace]() {
-					goto l105
+				if buffer[position] != '=' {
+					goto l349
 				}
+				position++
 				depth--
-				add(Ruletokmul, position106)
+				add(Ruletokle, position350)
 			}
 			return true
-		l105:
-			position, tokenIndex, depth = position105, tokenIndex105, depth105
+		l349:
+			position, tokenIndex, depth = position349, tokenIndex349, depth349
 			return false
 		},
-		/* 34 tokdiv <- <('/' optspace)> */
+		/* 95 tokge <- <('>' '=')> */
 		func() bool {
-			position107, tokenIndex107, depth107 := position, tokenIndex, depth
+			position351, tokenIndex351, depth351 := position, tokenIndex, depth
 			{
-				position108 := position
+				position352 := position
 				depth++
-				if buffer[position] != '/' {
-					goto l107
+				if buffer[position] != '>' {
+					goto l351
 				}
 				position++
-				if !rules[Ruleoptspace]() {
-					goto l107
+				if buffer[position] != '=' {
+					goto l351
 				}
+				position++
 				depth--
-				add(Ruletokdiv, position108)
+				add(Ruletokge, position352)
 			}
 			return true
-		l107:
-			position, tokenIndex, depth = position107, tokenIndex107, depth107
+		l351:
+			position, tokenIndex, depth = position351, tokenIndex351, depth351
 			return false
 		},
-		/* 35 endl <- <(optspace ';')> */
+		/* 96 tokne <- <('!' '=')> */
 		func() bool {
-			position109, tokenIndex109, depth109 := position, tokenIndex, depth
+			position353, tokenIndex353, depth353 := position, tokenIndex, depth
 			{
-				position110 := position
+				position354 := position
 				depth++
-				if !rules[Ruleoptspace]() {
-					goto l109
+				if buffer[position] != '!' {
+					goto l353
 				}
-				if buffer[position] != ';' {
-					goto l109
+				position++
+				if buffer[position] != '=' {
+					goto l353
 				}
 				position++
 				depth--
-				add(Ruleendl, position110)
+				add(Ruletokne, position354)
 			}
 			return true
-		l109:
-			position, tokenIndex, depth = position109, tokenIndex109, depth109
+		l353:
+			position, tokenIndex, depth = position353, tokenIndex353, depth353
 			return false
 		},
-		/* 36 rawmath <- <(value optspace (tokadd / toksub / tokmul / tokdiv) optspace value)> */
+		/* 97 comparisontoken <- <(tokle / tokge / tokeq / tokne / toklt / tokgt)> */
 		func() bool {
-			position111, tokenIndex111, depth111 := position, tokenIndex, depth
+			position355, tokenIndex355, depth355 := position, tokenIndex, depth
 			{
-				position112 := position
+				position356 := position
 				depth++
-				if !rules[Rulevalue]() {
-					goto l111
-				}
-				if !rules[Ruleoptspace]() {
-					goto l111
-				}
 				{
-					position113, tokenIndex113, depth113 := position, tokenIndex, depth
-					if !rules[Ruletokadd]() {
-						goto l114
+					position357, tokenIndex357, depth357 := position, tokenIndex, depth
+					if !rules[Ruletokle]() {
+						goto l358
 					}
-					goto l113
-				l114:
-					position, tokenIndex, depth = position113, tokenIndex113, depth113
-					if !rules[Ruletoksub]() {
-						goto l115
+					goto l357
+				l358:
+					position, tokenIndex, depth = position357, tokenIndex357, depth357
+					if !rules[Ruletokge]() {
+						goto l359
 					}
-					goto l113
-				l115:
-					position, tokenIndex, depth = position113, tokenIndex113, depth113
-					if !rules[Ruletokmul]() {
-						goto l116
+					goto l357
+				l359:
+					position, tokenIndex, depth = position357, tokenIndex357, depth357
+					if !rules[Ruletokeq]() {
+						goto l360
 					}
-					goto l113
-				l116:
-					position, tokenIndex, depth = position113, tokenIndex113, depth113
-					if !rules[Ruletokdiv]() {
-						goto l111
+					goto l357
+				l360:
+					position, tokenIndex, depth = position357, tokenIndex357, depth357
+					if !rules[Ruletokne]() {
+						goto l361
+					}
+					goto l357
+				l361:
+					position, tokenIndex, depth = position357, tokenIndex357, depth357
+					if !rules[Ruletoklt]() {
+						goto l362
+					}
+					goto l357
+				l362:
+					position, tokenIndex, depth = position357, tokenIndex357, depth357
+					if !rules[Ruletokgt]() {
+						goto l355
 					}
 				}
-			l113:
-				if !rules[Ruleoptspace]() {
-					goto l111
-				}
-				if !rules[Rulevalue]() {
-					goto l111
-				}
+			l357:
 				depth--
-				add(Rulerawmath, position112)
+				add(Rulecomparisontoken, position356)
 			}
 			return true
-		l111:
-			position, tokenIndex, depth = position111, tokenIndex111, depth111
+		l355:
+			position, tokenIndex, depth = position355, tokenIndex355, depth355
 			return false
 		},
-		/* 37 math <- <(optspace (rawmath / (popen rawmath pclose)) optspace)> */
+		/* 98 condition <- <expr> */
 		func() bool {
-			position117, tokenIndex117, depth117 := position, tokenIndex, depth
+			position363, tokenIndex363, depth363 := position, tokenIndex, depth
 			{
-				position118 := position
+				position364 := position
 				depth++
-				if !rules[Ruleoptspace]() {
-					goto l117
-				}
-				{
-					position119, tokenIndex119, depth119 := position, tokenIndex, depth
-					if !rules[Rulerawmath]() {
-						goto l120
-					}
-					goto l119
-				l120:
-					position, tokenIndex, depth = position119, tokenIndex119, depth119
-					if !rules[Rulepopen]() {
-						goto l117
-					}
-					if !rules[Rulerawmath]() {
-						goto l117
-					}
-					if !rules[Rulepclose]() {
-						goto l117
-					}
-				}
-			l119:
-				if !rules[Ruleoptspace]() {
-					goto l117
+				if !rules[Ruleexpr]() {
+					goto l363
 				}
 				depth--
-				add(Rulemath, position118)
+				add(Rulecondition, position364)
 			}
 			return true
-		l117:
-			position, tokenIndex, depth = position117, tokenIndex117, depth117
+		l363:
+			position, tokenIndex, depth = position363, tokenIndex363, depth363
 			return false
 		},
-		/* 38 popen <- <('(' optspace)> */
+		/* 99 comparison_paren <- <(popen optspace condition optspace pclose)> */
 		func() bool {
-			position121, tokenIndex121, depth121 := position, tokenIndex, depth
+			position365, tokenIndex365, depth365 := position, tokenIndex, depth
 			{
-				position122 := position
+				position366 := position
 				depth++
-				if buffer[position] != '(' {
-					goto l121
+				if !rules[Rulepopen]() {
+					goto l365
+				}
+				if !rules[Ruleoptspace]() {
+					goto l365
+				}
+				if !rules[Rulecondition]() {
+					goto l365
 				}
-				position++
 				if !rules[Ruleoptspace]() {
-					goto l121
+					goto l365
+				}
+				if !rules[Rulepclose]() {
+					goto l365
 				}
 				depth--
-				add(Rulepopen, position122)
+				add(Rulecomparison_paren, position366)
 			}
 			return true
-		l121:
-			position, tokenIndex, depth = position121, tokenIndex121, depth121
+		l365:
+			position, tokenIndex, depth = position365, tokenIndex365, depth365
 			return false
 		},
-		/* 39 pclose <- <(')' optspace)> */
+		/* 100 positivenum <- <(hexnum / binarynum / decimalnum)> */
 		func() bool {
-			position123, tokenIndex123, depth123 := position, tokenIndex, depth
+			position367, tokenIndex367, depth367 := position, tokenIndex, depth
 			{
-				position124 := position
+				position368 := position
 				depth++
-				if buffer[position] != ')' {
-					goto l123
-				}
-				position++
-				if !rules[Ruleoptspace]() {
-					goto l123
+				{
+					position369, tokenIndex369, depth369 := position, tokenIndex, depth
+					if !rules[Rulehexnum]() {
+						goto l370
+					}
+					goto l369
+				l370:
+					position, tokenIndex, depth = position369, tokenIndex369, depth369
+					if !rules[Rulebinarynum]() {
+						goto l371
+					}
+					goto l369
+				l371:
+					position, tokenIndex, depth = position369, tokenIndex369, depth369
+					if !rules[Ruledecimalnum]() {
+						goto l367
+					}
 				}
+			l369:
 				depth--
-				add(Rulepclose, position124)
+				add(Rulepositivenum, position368)
 			}
 			return true
-		l123:
-			position, tokenIndex, depth = position123, tokenIndex123, depth123
+		l367:
+			position, tokenIndex, depth = position367, tokenIndex367, depth367
 			return false
 		},
-		/* 40 toklt <- <'<'> */
+		/* 101 decimalnum <- <(([1-9] ('_'? digit)*) / ('0' !digit))> */
 		func() bool {
-			position125, tokenIndex125, depth125 := position, tokenIndex, depth
+			position372, tokenIndex372, depth372 := position, tokenIndex, depth
 			{
-				position126 := position
+				position373 := position
 				depth++
-				if buffer[position] != '<' {
-					goto l125
+				{
+					position374, tokenIndex374, depth374 := position, tokenIndex, depth
+					if c := buffer[position]; c < '1' || c > '9' {
+						goto l375
+					}
+					position++
+				l376:
+					{
+						position377, tokenIndex377, depth377 := position, tokenIndex, depth
+						{
+							position378, tokenIndex378, depth378 := position, tokenIndex, depth
+							if buffer[position] != '_' {
+								goto l378
+							}
+							position++
+							goto l379
+						l378:
+							position, tokenIndex, depth = position378, tokenIndex378, depth378
+						}
+					l379:
+						if !rules[Ruledigit]() {
+							goto l377
+						}
+						goto l376
+					l377:
+						position, tokenIndex, depth = position377, tokenIndex377, depth377
+					}
+					goto l374
+				l375:
+					position, tokenIndex, depth = position374, tokenIndex374, depth374
+					if buffer[position] != '0' {
+						goto l372
+					}
+					position++
+					{
+						position380, tokenIndex380, depth380 := position, tokenIndex, depth
+						if !rules[Ruledigit]() {
+							goto l380
+						}
+						goto l372
+					l380:
+						position, tokenIndex, depth = position380, tokenIndex380, depth380
+					}
 				}
-				position++
+			l374:
 				depth--
-				add(Ruletoklt, position126)
+				add(Ruledecimalnum, position373)
 			}
 			return true
-		l125:
-			position, tokenIndex, depth = position125, tokenIndex125, depth125
+		l372:
+			position, tokenIndex, depth = position372, tokenIndex372, depth372
 			return false
 		},
-		/* 41 tokgt <- <'>'> */
+		/* 102 hexnum <- <('0' ('x' / 'X') hexdigit ('_'? hexdigit)*)> */
 		func() bool {
-			position127, tokenIndex127, depth127 := position, tokenIndex, depth
+			position381, tokenIndex381, depth381 := position, tokenIndex, depth
 			{
-				position128 := position
+				position382 := position
 				depth++
-				if buffer[position] != '>' {
-					goto l127
+				if buffer[position] != '0' {
+					goto l381
 				}
 				position++
+				{
+					position383, tokenIndex383, depth383 := position, tokenIndex, depth
+					if buffer[position] != 'x' {
+						goto l384
+					}
+					position++
+					goto l383
+				l384:
+					position, tokenIndex, depth = position383, tokenIndex383, depth383
+					if buffer[position] != 'X' {
+						goto l381
+					}
+					position++
+				}
+			l383:
+				if !rules[Rulehexdigit]() {
+					goto l381
+				}
+			l385:
+				{
+					position386, tokenIndex386, depth386 := position, tokenIndex, depth
+					{
+						position387, tokenIndex387, depth387 := position, tokenIndex, depth
+						if buffer[position] != '_' {
+							goto l387
+						}
+						position++
+						goto l388
+					l387:
+						position, tokenIndex, depth = position387, tokenIndex387, depth387
+					}
+				l388:
+					if !rules[Rulehexdigit]() {
+						goto l386
+					}
+					goto l385
+				l386:
+					position, tokenIndex, depth = position386, tokenIndex386, depth386
+				}
 				depth--
-				add(Ruletokgt, position128)
+				add(Rulehexnum, position382)
 			}
 			return true
-		l127:
-			position, tokenIndex, depth = position127, tokenIndex127, depth127
+		l381:
+			position, tokenIndex, depth = position381, tokenIndex381, depth381
 			return false
 		},
-		/* 42 tokeq <- <('=' '=')> */
+		/* 103 binarynum <- <('0' ('b' / 'B') ('0' / '1') ('_'? ('0' / '1'))*)> */
 		func() bool {
-			position129, tokenIndex129, depth129 := position, tokenIndex, depth
+			position389, tokenIndex389, depth389 := position, tokenIndex, depth
 			{
-				position130 := position
+				position390 := position
 				depth++
-				if buffer[position] != '=' {
-					goto l129
+				if buffer[position] != '0' {
+					goto l389
 				}
 				position++
-				if buffer[position] != '=' {
-					goto l129
+				{
+					position391, tokenIndex391, depth391 := position, tokenIndex, depth
+					if buffer[position] != 'b' {
+						goto l392
+					}
+					position++
+					goto l391
+				l392:
+					position, tokenIndex, depth = position391, tokenIndex391, depth391
+					if buffer[position] != 'B' {
+						goto l389
+					}
+					position++
+				}
+			l391:
+				{
+					position393, tokenIndex393, depth393 := position, tokenIndex, depth
+					if buffer[position] != '0' {
+						goto l394
+					}
+					position++
+					goto l393
+				l394:
+					position, tokenIndex, depth = position393, tokenIndex393, depth393
+					if buffer[position] != '1' {
+						goto l389
+					}
+					position++
+				}
+			l393:
+			l395:
+				{
+					position396, tokenIndex396, depth396 := position, tokenIndex, depth
+					{
+						position397, tokenIndex397, depth397 := position, tokenIndex, depth
+						if buffer[position] != '_' {
+							goto l397
+						}
+						position++
+						goto l398
+					l397:
+						position, tokenIndex, depth = position397, tokenIndex397, depth397
+					}
+				l398:
+					{
+						position399, tokenIndex399, depth399 := position, tokenIndex, depth
+						if buffer[position] != '0' {
+							goto l400
+						}
+						position++
+						goto l399
+					l400:
+						position, tokenIndex, depth = position399, tokenIndex399, depth399
+						if buffer[position] != '1' {
+							goto l396
+						}
+						position++
+					}
+				l399:
+					goto l395
+				l396:
+					position, tokenIndex, depth = position396, tokenIndex396, depth396
 				}
-				position++
 				depth--
-				add(Ruletokeq, position130)
+				add(Rulebinarynum, position390)
 			}
 			return true
-		l129:
-			position, tokenIndex, depth = position129, tokenIndex129, depth129
+		l389:
+			position, tokenIndex, depth = position389, tokenIndex389, depth389
 			return false
 		},
-		/* 43 tokle <- <('<' '=')> */
+		/* 104 negativenum <- <('-' positivenum)> */
 		func() bool {
-			position131, tokenIndex131, depth131 := position, tokenIndex, depth
+			position401, tokenIndex401, depth401 := position, tokenIndex, depth
 			{
-				position132 := position
+				position402 := position
 				depth++
-				if buffer[position] != '<' {
-					goto l131
+				if buffer[position] != '-' {
+					goto l401
 				}
 				position++
-				if buffer[position] != '=' {
-					goto l131
+				if !rules[Rulepositivenum]() {
+					goto l401
 				}
-				position++
 				depth--
-				add(Ruletokle, position132)
+				add(Rulenegativenum, position402)
 			}
 			return true
-		l131:
-			position, tokenIndex, depth = position131, tokenIndex131, depth131
+		l401:
+			position, tokenIndex, depth = position401, tokenIndex401, depth401
 			return false
 		},
-		/* 44 tokge <- <('>' '=')> */
+		/* 105 number <- <(positivenum / negativenum)> */
 		func() bool {
-			position133, tokenIndex133, depth133 := position, tokenIndex, depth
+			position403, tokenIndex403, depth403 := position, tokenIndex, depth
 			{
-				position134 := position
+				position404 := position
 				depth++
-				if buffer[position] != '>' {
-					goto l133
-				}
-				position++
-				if buffer[position] != '=' {
-					goto l133
+				{
+					position405, tokenIndex405, depth405 := position, tokenIndex, depth
+					if !rules[Rulepositivenum]() {
+						goto l406
+					}
+					goto l405
+				l406:
+					position, tokenIndex, depth = position405, tokenIndex405, depth405
+					if !rules[Rulenegativenum]() {
+						goto l403
+					}
 				}
-				position++
+			l405:
 				depth--
-				add(Ruletokge, position134)
+				add(Rulenumber, position404)
 			}
 			return true
-		l133:
-			position, tokenIndex, depth = position133, tokenIndex133, depth133
+		l403:
+			position, tokenIndex, depth = position403, tokenIndex403, depth403
 			return false
 		},
-		/* 45 tokne <- <('!' '=')> */
+		/* 106 digit <- <[0-9]> */
 		func() bool {
-			position135, tokenIndex135, depth135 := position, tokenIndex, depth
+			position407, tokenIndex407, depth407 := position, tokenIndex, depth
 			{
-				position136 := position
+				position408 := position
 				depth++
-				if buffer[position] != '!' {
-					goto l135
-				}
-				position++
-				if buffer[position] != '=' {
-					goto l135
+				if c := buffer[position]; c < '0' || c > '9' {
+					goto l407
 				}
 				position++
 				depth--
-				add(Ruletokne, position136)
+				add(Ruledigit, position408)
 			}
 			return true
-		l135:
-			position, tokenIndex, depth = position135, tokenIndex135, depth135
+		l407:
+			position, tokenIndex, depth = position407, tokenIndex407, depth407
 			return false
 		},
-		/* 46 comparisontoken <- <(toklt / tokgt / tokeq / tokle / tokge / tokne)> */
+		/* 107 hexdigit <- <([0-9] / [a-f] / [A-F])> */
 		func() bool {
-			position137, tokenIndex137, depth137 := position, tokenIndex, depth
+			position409, tokenIndex409, depth409 := position, tokenIndex, depth
 			{
-				position138 := position
+				position410 := position
 				depth++
 				{
-					position139, tokenIndex139, depth139 := position, tokenIndex, depth
-					if !rules[Ruletoklt]() {
-						goto l140
-					}
-					goto l139
-				l140:
-					position, tokenIndex, depth = position139, tokenIndex139, depth139
-					if !rules[Ruletokgt]() {
-						goto l141
+					position411, tokenIndex411, depth411 := position, tokenIndex, depth
+					if c := buffer[position]; c < '0' || c > '9' {
+						goto l412
 					}
-					goto l139
-				l141:
-					position, tokenIndex, depth = position139, tokenIndex139, depth139
-					if !rules[Ruletokeq]() {
-						goto l142
-					}
-					goto l139
-				l142:
-					position, tokenIndex, depth = position139, tokenIndex139, depth139
-					if !rules[Ruletokle]() {
-						goto l143
-					}
-					goto l139
-				l143:
-					position, tokenIndex, depth = position139, tokenIndex139, depth139
-					if !rules[Ruletokge]() {
-						goto l144
+					position++
+					goto l411
+				l412:
+					position, tokenIndex, depth = position411, tokenIndex411, depth411
+					if c := buffer[position]; c < 'a' || c > 'f' {
+						goto l413
 					}
-					goto l139
-				l144:
-					position, tokenIndex, depth = position139, tokenIndex139, depth139
-					if !rules[Ruletokne]() {
-						goto l137
+					position++
+					goto l411
+				l413:
+					position, tokenIndex, depth = position411, tokenIndex411, depth411
+					if c := buffer[position]; c < 'A' || c > 'F' {
+						goto l409
 					}
+					position++
 				}
-			l139:
+			l411:
 				depth--
-				add(Rulecomparisontoken, position138)
+				add(Rulehexdigit, position410)
 			}
 			return true
-		l137:
-			position, tokenIndex, depth = position137, tokenIndex137, depth137
+		l409:
+			position, tokenIndex, depth = position409, tokenIndex409, depth409
 			return false
 		},
-		/* 47 comparison <- <(value minspace comparisontoken minspace value)> */
+		/* 108 float <- <(digits (('.' digits exponent?) / exponent))> */
 		func() bool {
-			position145, tokenIndex145, depth145 := position, tokenIndex, depth
+			position414, tokenIndex414, depth414 := position, tokenIndex, depth
 			{
-				position146 := position
+				position415 := position
 				depth++
-				if !rules[Rulevalue]() {
-					goto l145
-				}
-				if !rules[Ruleminspace]() {
-					goto l145
+				if !rules[Ruledigits]() {
+					goto l414
 				}
-				if !rules[Rulecomparisontoken]() {
-					goto l145
-				}
-				if !rules[Ruleminspace]() {
-					goto l145
-				}
-				if !rules[Rulevalue]() {
-					goto l145
+				{
+					position416, tokenIndex416, depth416 := position, tokenIndex, depth
+					if buffer[position] != '.' {
+						goto l417
+					}
+					position++
+					if !rules[Ruledigits]() {
+						goto l417
+					}
+					{
+						position418, tokenIndex418, depth418 := position, tokenIndex, depth
+						if !rules[Ruleexponent]() {
+							goto l418
+						}
+						goto l419
+					l418:
+						position, tokenIndex, depth = position418, tokenIndex418, depth418
+					}
+				l419:
+					goto l416
+				l417:
+					position, tokenIndex, depth = position416, tokenIndex416, depth416
+					if !rules[Ruleexponent]() {
+						goto l414
+					}
 				}
+			l416:
 				depth--
-				add(Rulecomparison, position146)
+				add(Rulefloat, position415)
 			}
 			return true
-		l145:
-			position, tokenIndex, depth = position145, tokenIndex145, depth145
+		l414:
+			position, tokenIndex, depth = position414, tokenIndex414, depth414
 			return false
 		},
-		/* 48 comparison_paren <- <(popen optspace (comparison / value) optspace pclose)> */
+		/* 109 digits <- <(digit ('_'? digit)*)> */
 		func() bool {
-			position147, tokenIndex147, depth147 := position, tokenIndex, depth
+			position420, tokenIndex420, depth420 := position, tokenIndex, depth
 			{
-				position148 := position
+				position421 := position
 				depth++
-				if !rules[Rulepopen]() {
-					goto l147
-				}
-				if !rules[Ruleoptspace]() {
-					goto l147
+				if !rules[Ruledigit]() {
+					goto l420
 				}
+			l422:
 				{
-					position149, tokenIndex149, depth149 := position, tokenIndex, depth
-					if !rules[Rulecomparison]() {
-						goto l150
+					position423, tokenIndex423, depth423 := position, tokenIndex, depth
+					{
+						position424, tokenIndex424, depth424 := position, tokenIndex, depth
+						if buffer[position] != '_' {
+							goto l424
+						}
+						position++
+						goto l425
+					l424:
+						position, tokenIndex, depth = position424, tokenIndex424, depth424
 					}
-					goto l149
-				l150:
-					position, tokenIndex, depth = position149, tokenIndex149, depth149
-					if !rules[Rulevalue]() {
-						goto l147
+				l425:
+					if !rules[Ruledigit]() {
+						goto l423
 					}
-				}
-			l149:
-				if !rules[Ruleoptspace]() {
-					goto l147
-				}
-				if !rules[Rulepclose]() {
-					goto l147
+					goto l422
+				l423:
+					position, tokenIndex, depth = position423, tokenIndex423, depth423
 				}
 				depth--
-				add(Rulecomparison_paren, position148)
+				add(Ruledigits, position421)
 			}
 			return true
-		l147:
-			position, tokenIndex, depth = position147, tokenIndex147, depth147
+		l420:
+			position, tokenIndex, depth = position420, tokenIndex420, depth420
 			return false
 		},
-		/* 49 positivenum <- <([1-9] digit*)> */
+		/* 110 exponent <- <(('e' / 'E') ('+' / '-')? digit+)> */
 		func() bool {
-			position151, tokenIndex151, depth151 := position, tokenIndex, depth
+			position426, tokenIndex426, depth426 := position, tokenIndex, depth
 			{
-				position152 := position
+				position427 := position
 				depth++
-				if c := buffer[position]; c < '1' || c > '9' {
-					goto l151
+				{
+					position428, tokenIndex428, depth428 := position, tokenIndex, depth
+					if buffer[position] != 'e' {
+						goto l429
+					}
+					position++
+					goto l428
+				l429:
+					position, tokenIndex, depth = position428, tokenIndex428, depth428
+					if buffer[position] != 'E' {
+						goto l426
+					}
+					position++
 				}
-				position++
-			l153:
+			l428:
+				{
+					position430, tokenIndex430, depth430 := position, tokenIndex, depth
+					{
+						position432, tokenIndex432, depth432 := position, tokenIndex, depth
+						if buffer[position] != '+' {
+							goto l433
+						}
+						position++
+						goto l432
+					l433:
+						position, tokenIndex, depth = position432, tokenIndex432, depth432
+						if buffer[position] != '-' {
+							goto l430
+						}
+						position++
+					}
+				l432:
+					goto l431
+				l430:
+					position, tokenIndex, depth = position430, tokenIndex430, depth430
+				}
+			l431:
+				if !rules[Ruledigit]() {
+					goto l426
+				}
+			l434:
 				{
-					position154, tokenIndex154, depth154 := position, tokenIndex, depth
+					position435, tokenIndex435, depth435 := position, tokenIndex, depth
 					if !rules[Ruledigit]() {
-						goto l154
+						goto l435
 					}
-					goto l153
-				l154:
-					position, tokenIndex, depth = position154, tokenIndex154, depth154
+					goto l434
+				l435:
+					position, tokenIndex, depth = position435, tokenIndex435, depth435
 				}
 				depth--
-				add(Rulepositivenum, position152)
+				add(Ruleexponent, position427)
 			}
 			return true
-		l151:
-			position, tokenIndex, depth = position151, tokenIndex151, depth151
+		l426:
+			position, tokenIndex, depth = position426, tokenIndex426, depth426
 			return false
 		},
-		/* 50 negativenum <- <('-' positivenum)> */
+		/* 111 string <- <('"' (escape / (!('"' / '\\' / '\r' / '\n') .))* '"')> */
 		func() bool {
-			position155, tokenIndex155, depth155 := position, tokenIndex, depth
+			position436, tokenIndex436, depth436 := position, tokenIndex, depth
 			{
-				position156 := position
+				position437 := position
 				depth++
-				if buffer[position] != '-' {
-					goto l155
+				if buffer[position] != '"' {
+					goto l436
 				}
 				position++
-				if !rules[Rulepositivenum]() {
-					goto l155
-				}
-				depth--
-				add(Rulenegativenum, position156)
-			}
-			return true
-		l155:
-			position, tokenIndex, depth = position155, tokenIndex155, depth155
-			return false
-		},
-		/* 51 number <- <(positivenum / negativenum)> */
-		func() bool {
-			position157, tokenIndex157, depth157 := position, tokenIndex, depth
-			{
-				position158 := position
-				depth++
+			l438:
 				{
-					position159, tokenIndex159, depth159 := position, tokenIndex, depth
-					if !rules[Rulepositivenum]() {
-						goto l160
-					}
-					goto l159
-				l160:
-					position, tokenIndex, depth = position159, tokenIndex159, depth159
-					if !rules[Rulenegativenum]() {
-						goto l157
+					position439, tokenIndex439, depth439 := position, tokenIndex, depth
+					{
+						position440, tokenIndex440, depth440 := position, tokenIndex, depth
+						if !rules[Ruleescape]() {
+							goto l441
+						}
+						goto l440
+					l441:
+						position, tokenIndex, depth = position440, tokenIndex440, depth440
+						{
+							position442, tokenIndex442, depth442 := position, tokenIndex, depth
+							{
+								position443, tokenIndex443, depth443 := position, tokenIndex, depth
+								if buffer[position] != '"' {
+									goto l444
+								}
+								position++
+								goto l443
+							l444:
+								position, tokenIndex, depth = position443, tokenIndex443, depth443
+								if buffer[position] != '\\' {
+									goto l445
+								}
+								position++
+								goto l443
+							l445:
+								position, tokenIndex, depth = position443, tokenIndex443, depth443
+								if buffer[position] != '\r' {
+									goto l446
+								}
+								position++
+								goto l443
+							l446:
+								position, tokenIndex, depth = position443, tokenIndex443, depth443
+								if buffer[position] != '\n' {
+									goto l442
+								}
+								position++
+							}
+						l443:
+							goto l439
+						l442:
+							position, tokenIndex, depth = position442, tokenIndex442, depth442
+						}
+						if !matchDot() {
+							goto l439
+						}
 					}
+				l440:
+					goto l438
+				l439:
+					position, tokenIndex, depth = position439, tokenIndex439, depth439
 				}
-			l159:
+				if buffer[position] != '"' {
+					goto l436
+				}
+				position++
 				depth--
-				add(Rulenumber, position158)
+				add(Rulestring, position437)
 			}
 			return true
-		l157:
-			position, tokenIndex, depth = position157, tokenIndex157, depth157
+		l436:
+			position, tokenIndex, depth = position436, tokenIndex436, depth436
 			return false
 		},
-		/* 52 digit <- <[0-9]> */
+		/* 112 escape <- <('\\' (('n' / 'r' / 't' / '0' / '\\' / '"') / ('x' hexdigit hexdigit)))> */
 		func() bool {
-			position161, tokenIndex161, depth161 := position, tokenIndex, depth
+			position447, tokenIndex447, depth447 := position, tokenIndex, depth
 			{
-				position162 := position
+				position448 := position
 				depth++
-				if c := buffer[position]; c < '0' || c > '9' {
-					goto l161
+				if buffer[position] != '\\' {
+					goto l447
 				}
 				position++
+				{
+					position449, tokenIndex449, depth449 := position, tokenIndex, depth
+					{
+						position451, tokenIndex451, depth451 := position, tokenIndex, depth
+						if buffer[position] != 'n' {
+							goto l452
+						}
+						position++
+						goto l451
+					l452:
+						position, tokenIndex, depth = position451, tokenIndex451, depth451
+						if buffer[position] != 'r' {
+							goto l453
+						}
+						position++
+						goto l451
+					l453:
+						position, tokenIndex, depth = position451, tokenIndex451, depth451
+						if buffer[position] != 't' {
+							goto l454
+						}
+						position++
+						goto l451
+					l454:
+						position, tokenIndex, depth = position451, tokenIndex451, depth451
+						if buffer[position] != '0' {
+							goto l455
+						}
+						position++
+						goto l451
+					l455:
+						position, tokenIndex, depth = position451, tokenIndex451, depth451
+						if buffer[position] != '\\' {
+							goto l456
+						}
+						position++
+						goto l451
+					l456:
+						position, tokenIndex, depth = position451, tokenIndex451, depth451
+						if buffer[position] != '"' {
+							goto l450
+						}
+						position++
+					}
+				l451:
+					goto l449
+				l450:
+					position, tokenIndex, depth = position449, tokenIndex449, depth449
+					if buffer[position] != 'x' {
+						goto l447
+					}
+					position++
+					if !rules[Rulehexdigit]() {
+						goto l447
+					}
+					if !rules[Rulehexdigit]() {
+						goto l447
+					}
+				}
+			l449:
 				depth--
-				add(Ruledigit, position162)
+				add(Ruleescape, position448)
 			}
 			return true
-		l161:
-			position, tokenIndex, depth = position161, tokenIndex161, depth161
+		l447:
+			position, tokenIndex, depth = position447, tokenIndex447, depth447
 			return false
 		},
-		/* 53 commentblock <- <('/' '*' (!('*' '/') .)* ('*' '/'))> */
+		/* 113 commentblock <- <('/' '*' (!('*' '/') .)* ('*' '/'))> */
 		func() bool {
-			position163, tokenIndex163, depth163 := position, tokenIndex, depth
+			position457, tokenIndex457, depth457 := position, tokenIndex, depth
 			{
-				position164 := position
+				position458 := position
 				depth++
 				if buffer[position] != '/' {
-					goto l163
+					goto l457
 				}
 				position++
 				if buffer[position] != '*' {
-					goto l163
+					goto l457
 				}
 				position++
-			l165:
+			l459:
 				{
-					position166, tokenIndex166, depth166 := position, tokenIndex, depth
+					position460, tokenIndex460, depth460 := position, tokenIndex, depth
 					{
-						position167, tokenIndex167, depth167 := position, tokenIndex, depth
+						position461, tokenIndex461, depth461 := position, tokenIndex, depth
 						if buffer[position] != '*' {
-							goto l167
+							goto l461
 						}
 						position++
 						if buffer[position] != '/' {
-							goto l167
+							goto l461
 						}
 						position++
-						goto l166
-					l167:
-						position, tokenIndex, depth = position167, tokenIndex167, depth167
+						goto l460
+					l461:
+						position, tokenIndex, depth = position461, tokenIndex461, depth461
 					}
 					if !matchDot() {
-						goto l166
+						goto l460
 					}
-					goto l165
-				l166:
-					position, tokenIndex, depth = position166, tokenIndex166, depth166
+					goto l459
+				l460:
+					position, tokenIndex, depth = position460, tokenIndex460, depth460
 				}
 				if buffer[position] != '*' {
-					goto l163
+					goto l457
 				}
 				position++
 				if buffer[position] != '/' {
-					goto l163
+					goto l457
 				}
 				position++
 				depth--
-				add(Rulecommentblock, position164)
+				add(Rulecommentblock, position458)
 			}
 			return true
-		l163:
-			position, tokenIndex, depth = position163, tokenIndex163, depth163
+		l457:
+			position, tokenIndex, depth = position457, tokenIndex457, depth457
 			return false
 		},
-		/* 54 commentdoubleslash <- <('/' '/' (!('\n' / '\r') .)* space)> */
+		/* 114 commentdoubleslash <- <('/' '/' (!('\n' / '\r') .)* space)> */
 		func() bool {
-			position168, tokenIndex168, depth168 := position, tokenIndex, depth
+			position462, tokenIndex462, depth462 := position, tokenIndex, depth
 			{
-				position169 := position
+				position463 := position
 				depth++
 				if buffer[position] != '/' {
-					goto l168
+					goto l462
 				}
 				position++
 				if buffer[position] != '/' {
-					goto l168
+					goto l462
 				}
 				position++
-			l170:
+			l464:
 				{
-					position171, tokenIndex171, depth171 := position, tokenIndex, depth
+					position465, tokenIndex465, depth465 := position, tokenIndex, depth
 					{
-						position172, tokenIndex172, depth172 := position, tokenIndex, depth
+						position466, tokenIndex466, depth466 := position, tokenIndex, depth
 						{
-							position173, tokenIndex173, depth173 := position, tokenIndex, depth
+							position467, tokenIndex467, depth467 := position, tokenIndex, depth
 							if buffer[position] != '\n' {
-								goto l174
+								goto l468
 							}
 							position++
-							goto l173
-						l174:
-							position, tokenIndex, depth = position173, tokenIndex173, depth173
+							goto l467
+						l468:
+							position, tokenIndex, depth = position467, tokenIndex467, depth467
 							if buffer[position] != '\r' {
-								goto l172
+								goto l466
 							}
 							position++
 						}
-					l173:
-						goto l171
-					l172:
-						position, tokenIndex, depth = position172, tokenIndex172, depth172
+					l467:
+						goto l465
+					l466:
+						position, tokenIndex, depth = position466, tokenIndex466, depth466
 					}
 					if !matchDot() {
-						goto l171
+						goto l465
 					}
-					goto l170
-				l171:
-					position, tokenIndex, depth = position171, tokenIndex171, depth171
+					goto l464
+				l465:
+					position, tokenIndex, depth = position465, tokenIndex465, depth465
 				}
 				if !rules[Rulespace]() {
-					goto l168
+					goto l462
 				}
 				depth--
-				add(Rulecommentdoubleslash, position169)
+				add(Rulecommentdoubleslash, position463)
 			}
 			return true
-		l168:
-			position, tokenIndex, depth = position168, tokenIndex168, depth168
+		l462:
+			position, tokenIndex, depth = position462, tokenIndex462, depth462
 			return false
 		},
-		/* 55 comment <- <(commentblock / commentdoubleslash)> */
+		/* 115 comment <- <(commentblock / commentdoubleslash)> */
 		func() bool {
-			position175, tokenIndex175, depth175 := position, tokenIndex, depth
+			position469, tokenIndex469, depth469 := position, tokenIndex, depth
 			{
-				position176 := position
+				position470 := position
 				depth++
 				{
-					position177, tokenIndex177, depth177 := position, tokenIndex, depth
+					position471, tokenIndex471, depth471 := position, tokenIndex, depth
 					if !rules[Rulecommentblock]() {
-						goto l178
+						goto l472
 					}
-					goto l177
-				l178:
-					position, tokenIndex, depth = position177, tokenIndex177, depth177
+					goto l471
+				l472:
+					position, tokenIndex, depth = position471, tokenIndex471, depth471
 					if !rules[Rulecommentdoubleslash]() {
-						goto l175
+						goto l469
 					}
 				}
-			l177:
+			l471:
 				depth--
-				add(Rulecomment, position176)
+				add(Rulecomment, position470)
 			}
 			return true
-		l175:
-			position, tokenIndex, depth = position175, tokenIndex175, depth175
+		l469:
+			position, tokenIndex, depth = position469, tokenIndex469, depth469
 			return false
 		},
-		/* 56 literalspace <- <(' ' / '\t' / '\n' / '\r')> */
+		/* 116 literalspace <- <(' ' / '\t' / '\n' / '\r')> */
 		func() bool {
-			position179, tokenIndex179, depth179 := position, tokenIndex, depth
+			position473, tokenIndex473, depth473 := position, tokenIndex, depth
 			{
-				position180 := position
+				position474 := position
 				depth++
 				{
-					position181, tokenIndex181, depth181 := position, tokenIndex, depth
+					position475, tokenIndex475, depth475 := position, tokenIndex, depth
 					if buffer[position] != ' ' {
-						goto l182
+						goto l476
 					}
 					position++
-					goto l181
-				l182:
-					position, tokenIndex, depth = position181, tokenIndex181, depth181
+					goto l475
+				l476:
+					position, tokenIndex, depth = position475, tokenIndex475, depth475
 					if buffer[position] != '\t' {
-						goto l183
+						goto l477
 					}
 					position++
-					goto l181
-				l183:
-					position, tokenIndex, depth = position181, tokenIndex181, depth181
+					goto l475
+				l477:
+					position, tokenIndex, depth = position475, tokenIndex475, depth475
 					if buffer[position] != '\n' {
-						goto l184
+						goto l478
 					}
 					position++
-					goto l181
-				l184:
-					position, tokenIndex, depth = position181, tokenIndex181, depth181
+					goto l475
+				l478:
+					position, tokenIndex, depth = position475, tokenIndex475, depth475
 					if buffer[position] != '\r' {
-						goto l179
+						goto l473
 					}
 					position++
 				}
-			l181:
+			l475:
 				depth--
-				add(Ruleliteralspace, position180)
+				add(Ruleliteralspace, position474)
 			}
 			return true
-		l179:
-			position, tokenIndex, depth = position179, tokenIndex179, depth179
+		l473:
+			position, tokenIndex, depth = position473, tokenIndex473, depth473
 			return false
 		},
-		/* 57 space <- <(comment / literalspace)> */
+		/* 117 space <- <(comment / literalspace)> */
 		func() bool {
-			position185, tokenIndex185, depth185 := position, tokenIndex, depth
+			position479, tokenIndex479, depth479 := position, tokenIndex, depth
 			{
-				position186 := position
+				position480 := position
 				depth++
 				{
-					position187, tokenIndex187, depth187 := position, tokenIndex, depth
+					position481, tokenIndex481, depth481 := position, tokenIndex, depth
 					if !rules[Rulecomment]() {
-						goto l188
+						goto l482
 					}
-					goto l187
-				l188:
-					position, tokenIndex, depth = position187, tokenIndex187, depth187
+					goto l481
+				l482:
+					position, tokenIndex, depth = position481, tokenIndex481, depth481
 					if !rules[Ruleliteralspace]() {
-						goto l185
+						goto l479
 					}
 				}
-			l187:
+			l481:
 				depth--
-				add(Rulespace, position186)
+				add(Rulespace, position480)
 			}
 			return true
-		l185:
-			position, tokenIndex, depth = position185, tokenIndex185, depth185
+		l479:
+			position, tokenIndex, depth = position479, tokenIndex479, depth479
 			return false
 		},
-		/* 58 minspace <- <space+> */
+		/* 118 minspace <- <space+> */
 		func() bool {
-			position189, tokenIndex189, depth189 := position, tokenIndex, depth
+			position483, tokenIndex483, depth483 := position, tokenIndex, depth
 			{
-				position190 := position
+				position484 := position
 				depth++
 				if !rules[Rulespace]() {
-					goto l189
+					goto l483
 				}
-			l191:
+			l485:
 				{
-					position192, tokenIndex192, depth192 := position, tokenIndex, depth
+					position486, tokenIndex486, depth486 := position, tokenIndex, depth
 					if !rules[Rulespace]() {
-						goto l192
+						goto l486
 					}
-					goto l191
-				l192:
-					position, tokenIndex, depth = position192, tokenIndex192, depth192
+					goto l485
+				l486:
+					position, tokenIndex, depth = position486, tokenIndex486, depth486
 				}
 				depth--
-				add(Ruleminspace, position190)
+				add(Ruleminspace, position484)
 			}
 			return true
-		l189:
-			position, tokenIndex, depth = position189, tokenIndex189, depth189
+		l483:
+			position, tokenIndex, depth = position483, tokenIndex483, depth483
 			return false
 		},
-		/* 59 optspace <- <space*> */
+		/* 119 optspace <- <space*> */
 		func() bool {
 			{
-				position194 := position
+				position488 := position
 				depth++
-			l195:
+			l489:
 				{
-					position196, tokenIndex196, depth196 := position, tokenIndex, depth
+					position490, tokenIndex490, depth490 := position, tokenIndex, depth
 					if !rules[Rulespace]() {
-						goto l196
+						goto l490
 					}
-					goto l195
-				l196:
-					position, tokenIndex, depth = position196, tokenIndex196, depth196
+					goto l489
+				l490:
+					position, tokenIndex, depth = position490, tokenIndex490, depth490
 				}
 				depth--
-				add(Ruleoptspace, position194)
+				add(Ruleoptspace, position488)
 			}
 			return true
 		},
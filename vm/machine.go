@@ -0,0 +1,756 @@
+package vm
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// operand kinds, see formats
+const (
+	operandRegister  = 'r' // one byte, the index of a register
+	operandImmediate = 'i' // imm8, sign extended
+	operandLong      = 'l' // imm32, big endian and sign extended
+	operandQuad      = 'q' // imm64, big endian
+)
+
+// formats lists the operands that follow each opcode.
+var formats = map[Operation]string{
+	END:    "",
+	SET:    "ri",
+	SETL:   "rl",
+	ADD:    "ri",
+	SUB:    "ri",
+	MUL:    "ri",
+	DIV:    "ri",
+	MOD:    "ri",
+	AND:    "ri",
+	OR:     "ri",
+	XOR:    "ri",
+	SHL:    "ri",
+	SHR:    "ri",
+	MOV:    "rr",
+	ADDREG: "rr",
+	SUBREG: "rr",
+	MULREG: "rr",
+	DIVREG: "rr",
+	MODREG: "rr",
+	ANDREG: "rr",
+	ORREG:  "rr",
+	XORREG: "rr",
+	SHLREG: "rr",
+	SHRREG: "rr",
+	JE:     "rrr",
+	JNE:    "rrr",
+	JL:     "rrr",
+	JG:     "rrr",
+	RELJE:  "rrr",
+	RELJNE: "rrr",
+	RELJL:  "rrr",
+	RELJG:  "rrr",
+	STRPS:  "r",
+	STPS:   "i",
+	STPR:   "r",
+	STPP:   "r",
+	NEG:    "r",
+	NOT:    "r",
+	DATA:   "l",
+	LOAD:   "rl",
+	STORE:  "rl",
+	ALLOC:  "rl",
+	FREE:   "r",
+	LOADM:  "rr",
+	STOREM: "rr",
+	BOUNDS: "rl",
+	STR:    "l", // followed by the bytes of the string
+	STRLEN: "rr",
+	STRCAT: "rr",
+	STRCMP: "rr",
+	STRAT:  "rr",
+	SETF:   "rq",
+	FADD:   "rr",
+	FSUB:   "rr",
+	FMUL:   "rr",
+	FDIV:   "rr",
+	FNEG:   "r",
+	FJE:    "rrr",
+	FJNE:   "rrr",
+	FJL:    "rrr",
+	FJG:    "rrr",
+	ITOF:   "r",
+	FTOI:   "r",
+	SQRT:   "rr",
+	JR:     "r",
+	ENTRY:  "l",
+	NEW:    "rl",
+	PRINT:  "rr",
+	PRINTS: "rr",
+	READ:   "rr",
+	READLN: "rr",
+	EXIT:   "rr",
+	EOF:    "rr",
+	HOST:   "rri",
+	NONE:   "",
+	BREAK:  "",
+}
+
+// Machine runs min bytecode. Registers hold signed 64 bit
+// integers; the stack grows as needed.
+//
+// The data segment holds the program's global variables, one
+// 64 bit word each. "DATA n" sizes it to n words, all zero;
+// "LOAD %reg address" and "STORE %reg address" read and write
+// the word at address.
+//
+// Memory is linear and also made of words. It grows and shrinks
+// like a stack: "ALLOC %reg n" adds n zero words to its end and
+// sets reg to the address of the first, and "FREE %reg" drops
+// every word from the address in reg on. "LOADM %a %b" sets a to
+// the word at the address in b and "STOREM %a %b" stores a there.
+// "BOUNDS %reg n" traps with ERRINDEX unless 0 <= reg < n.
+//
+// Words that must outlive the code that made them are kept on
+// the heap instead, which only grows: "NEW %reg n" adds n zero
+// words to it and sets reg to the address of the first. Heap
+// addresses start at HeapBase and are read and written with
+// LOADM and STOREM like the rest of memory.
+//
+// Floats are float64s kept in the registers as their IEEE 754
+// bits. "SETF %reg bits" loads one; the F instructions work on
+// them like their integer counterparts, ITOF and FTOI convert
+// between the two and "SQRT %a %b" sets a to the square root of
+// b. FTOI truncates and traps with ERRFLOATRANGE if the result
+// does not fit in an integer.
+//
+// "JE %a %b %c" jumps to the address in c if a equals b, and JNE,
+// JL and JG if it does not, is less or is greater. RELJE, RELJNE,
+// RELJL and RELJG jump by the offset in c from the instruction
+// after them instead.
+//
+// "JR %reg" jumps to the address in reg, which must hold an
+// ENTRY instruction; it traps with ERRBADADDRESS otherwise. Code
+// that is jumped to this way is called with its number of
+// arguments on top of the stack, and "ENTRY n" pops it and traps
+// with ERRARITY unless it is n. reg may also hold the address of
+// a closure, a record on the heap whose first word is the address
+// of the ENTRY; JR then jumps there and puts the closure's address
+// under the number of arguments for the code to find its
+// variables.
+//
+// Strings are immutable and referred to by handles, the index of
+// the string in the machine's string table. "STR n" followed by n
+// bytes adds a string to the table; programs start with one for
+// each of their string constants, so that the handle of the k-th
+// constant is k. STRCAT adds the strings it makes to the table
+// too. Invalid handles trap with ERRBADSTRING.
+//
+// Programs talk to the world through Stdin and Stdout:
+//
+//	PRINT   %a  writes the integer in a
+//	PRINTS  %a  writes the string a
+//	READ    %a  sets a to the next integer of the input
+//	READLN  %a  sets a to a new string, the next line of the
+//	            input without its end, empty at its end
+//	EOF     %a  sets a to 1 if all of the input has been read
+//	            and to 0 otherwise
+//	EXIT    %a  ends the program like END, with a in register A
+//
+// They take a second register, which they ignore, like the other
+// instructions that builtins are made of. READ skips white space
+// and traps with ERRIO if what follows is not an integer; any of
+// them traps with ERRIO if reading or writing fails.
+//
+// The program embedding the machine provides the functions that
+// min code declares extern; see RegisterHost.
+type Machine struct {
+	Registers [NUM_REGS]int64
+	Stdin     io.Reader
+	Stdout    io.Writer
+	// HostError is the error of the host function that trapped
+	// the machine with ERRHOST.
+	HostError error
+	// The limits of the program, for programs that are not
+	// trusted; see RunContext too. A limit that is 0 is no limit.
+	//
+	// MaxInstructions, the program's fuel, stops it with
+	// ERRINSTRUCTIONS once it has executed that many instructions.
+	// MaxStack stops it with ERRSTACKLIMIT once it has more words
+	// on the stack. MaxMemory stops it with ERRMEMORYLIMIT when it
+	// asks for more bytes of data, memory, heap and strings
+	// together; the bytes of a word are 8 and those of a string its
+	// length.
+	MaxInstructions int64
+	MaxStack        int
+	MaxMemory       int64
+	// Instructions is the number of instructions executed so far.
+	Instructions int64
+
+	code    []byte
+	pc      int
+	stack   []int64
+	data    []int64
+	memory  []int64
+	heap    []int64
+	strings []string
+	input   *bufio.Reader // Stdin, once it is read
+	hosts   map[string]host
+	used    int64 // bytes of memory, counted for MaxMemory
+}
+
+// HostFunc is a function that min code calls as an extern
+// routine. It gets the arguments of the call and returns its
+// result, or an error that stops the program.
+type HostFunc func(args []int64) (int64, error)
+
+type host struct {
+	arity int
+	fn    HostFunc
+}
+
+// HeapBase is the address of the first word of the heap.
+const HeapBase int64 = 1 << 40
+
+func NewMachine(code []byte) *Machine {
+	return &Machine{code: code, Stdin: os.Stdin, Stdout: os.Stdout}
+}
+
+// RegisterHost makes fn the extern routine name, which takes
+// arity arguments:
+//
+//	m.RegisterHost("now", 0, func(args []int64) (int64, error) {
+//		return time.Now().Unix(), nil
+//	})
+//
+// "HOST %a %b n" pops n arguments, the last one first, and calls
+// the function registered under the string b with them, setting a
+// to its result. It traps with ERRARITY if n is not the function's
+// arity, and with ERRHOST if no function is registered under b or
+// if it returns an error, which is kept in HostError.
+func (m *Machine) RegisterHost(name string, arity int, fn HostFunc) {
+	if m.hosts == nil {
+		m.hosts = make(map[string]host)
+	}
+	m.hosts[name] = host{arity, fn}
+}
+
+// Run executes instructions until the program ends or traps. It
+// returns ERRDONE once END is executed, ERROUTOFBOUNDS if the
+// program counter leaves the code or an instruction does not fit
+// in it, and one of the other ERR codes if an instruction cannot
+// be executed.
+func (m *Machine) Run() Operation {
+	return m.RunContext(context.Background())
+}
+
+// checkEvery is how many instructions RunContext executes between
+// looks at its context.
+const checkEvery = 1024
+
+// RunContext is Run, but stops the program with ERRCANCELED once
+// ctx is canceled and with ERRDEADLINE once its deadline passes.
+func (m *Machine) RunContext(ctx context.Context) Operation {
+	done := ctx.Done()
+	for {
+		if m.MaxInstructions > 0 && m.Instructions >= m.MaxInstructions {
+			return ERRINSTRUCTIONS
+		}
+		if done != nil && m.Instructions%checkEvery == 0 {
+			select {
+			case <-done:
+				if ctx.Err() == context.DeadlineExceeded {
+					return ERRDEADLINE
+				}
+				return ERRCANCELED
+			default:
+			}
+		}
+		m.Instructions++
+		if code := m.step(); code != ERRNONE {
+			return code
+		}
+		if m.MaxStack > 0 && len(m.stack) > m.MaxStack {
+			return ERRSTACKLIMIT
+		}
+	}
+}
+
+// allocate counts bytes more of memory as used, or traps with
+// ERRMEMORYLIMIT if that would use more than MaxMemory.
+func (m *Machine) allocate(bytes int64) Operation {
+	if m.MaxMemory > 0 && m.used+bytes > m.MaxMemory {
+		return ERRMEMORYLIMIT
+	}
+	m.used += bytes
+	return ERRNONE
+}
+
+// Call calls the routine whose ENTRY is at entry with args, the
+// way JR does, and runs the machine until the routine returns to
+// ret, which must hold END. It returns the routine's first
+// result, or the status that the program exits with.
+//
+// Programs whose routines are called this way are first run to
+// their END, to load their data and strings.
+func (m *Machine) Call(ctx context.Context, entry, ret int, args ...int64) (int64, Operation) {
+	if entry < 0 || entry >= len(m.code) || Operation(m.code[entry]) != ENTRY {
+		return 0, ERRBADADDRESS
+	}
+	m.stack = append(m.stack, int64(ret))
+	for i := len(args) - 1; i >= 0; i-- {
+		m.stack = append(m.stack, args[i])
+	}
+	m.stack = append(m.stack, int64(len(args)))
+	m.pc = entry
+	if code := m.RunContext(ctx); code != ERRDONE {
+		return 0, code
+	}
+	if m.pc != ret {
+		// EXIT
+		return m.Registers[REGA], ERRDONE
+	}
+	if len(m.stack) == 0 {
+		return 0, ERRSTACKUNDERFLOW
+	}
+	result := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return result, ERRDONE
+}
+
+// step executes the instruction at the program counter.
+func (m *Machine) step() Operation {
+	if m.pc < 0 || m.pc >= len(m.code) {
+		return ERROUTOFBOUNDS
+	}
+	op := Operation(m.code[m.pc])
+	format, ok := formats[op]
+	if !ok {
+		return ERROPCODENOTFOUND
+	}
+
+	// decode the operands: registers by index, immediates by value
+	var args [3]int64
+	next := m.pc + 1
+	for i, kind := range format {
+		switch kind {
+		case operandRegister, operandImmediate:
+			if next+1 > len(m.code) {
+				return ERROUTOFBOUNDS
+			}
+			args[i] = int64(int8(m.code[next]))
+			if kind == operandRegister {
+				args[i] = int64(m.code[next])
+				if args[i] >= int64(NUM_REGS) {
+					return ERRBADREGISTER
+				}
+			}
+			next++
+		case operandLong:
+			if next+4 > len(m.code) {
+				return ERROUTOFBOUNDS
+			}
+			args[i] = int64(int32(binary.BigEndian.Uint32(m.code[next:])))
+			next += 4
+		case operandQuad:
+			if next+8 > len(m.code) {
+				return ERROUTOFBOUNDS
+			}
+			args[i] = int64(binary.BigEndian.Uint64(m.code[next:]))
+			next += 8
+		}
+	}
+
+	// a is the register most instructions operate on
+	var a *int64
+	if len(format) > 0 && format[0] == operandRegister {
+		a = &m.Registers[args[0]]
+	}
+	switch op {
+	case END:
+		return ERRDONE
+	case BREAK:
+		return ERRBREAK
+	case NONE:
+	case SET, SETL, SETF:
+		*a = args[1]
+	case MOV:
+		*a = m.Registers[args[1]]
+	case ADD, SUB, MUL, DIV, MOD, AND, OR, XOR, SHL, SHR:
+		result, code := arithmetic(op, *a, args[1])
+		if code != ERRNONE {
+			return code
+		}
+		*a = result
+	case ADDREG, SUBREG, MULREG, DIVREG, MODREG, ANDREG, ORREG, XORREG, SHLREG, SHRREG:
+		result, code := arithmetic(op, *a, m.Registers[args[1]])
+		if code != ERRNONE {
+			return code
+		}
+		*a = result
+	case NEG:
+		*a = -*a
+	case NOT:
+		*a = ^*a
+	case JE, JNE, JL, JG:
+		if compare(op, *a, m.Registers[args[1]]) {
+			next = int(m.Registers[args[2]])
+		}
+	case RELJE, RELJNE, RELJL, RELJG:
+		if compare(op-RELJE+JE, *a, m.Registers[args[1]]) {
+			next += int(m.Registers[args[2]])
+		}
+	case JR:
+		entry := *a
+		if closure := *a - HeapBase; closure >= 0 && closure < int64(len(m.heap)) {
+			if len(m.stack) == 0 {
+				return ERRSTACKUNDERFLOW
+			}
+			count := m.stack[len(m.stack)-1]
+			m.stack = append(m.stack[:len(m.stack)-1], *a, count)
+			entry = m.heap[closure]
+		}
+		if entry < 0 || entry >= int64(len(m.code)) || Operation(m.code[entry]) != ENTRY {
+			return ERRBADADDRESS
+		}
+		next = int(entry)
+	case ENTRY:
+		if len(m.stack) == 0 {
+			return ERRSTACKUNDERFLOW
+		}
+		count := m.stack[len(m.stack)-1]
+		m.stack = m.stack[:len(m.stack)-1]
+		if count != args[0] {
+			return ERRARITY
+		}
+	case STRPS:
+		*a = int64(next)
+	case STPS:
+		m.stack = append(m.stack, args[0])
+	case STPR:
+		m.stack = append(m.stack, *a)
+	case DATA:
+		if args[0] < 0 {
+			return ERRBADADDRESS
+		}
+		if code := m.allocate(8 * (args[0] - int64(len(m.data)))); code != ERRNONE {
+			return code
+		}
+		m.data = make([]int64, args[0])
+	case LOAD, STORE:
+		if args[1] < 0 || args[1] >= int64(len(m.data)) {
+			return ERRBADADDRESS
+		}
+		if op == LOAD {
+			*a = m.data[args[1]]
+		} else {
+			m.data[args[1]] = *a
+		}
+	case ALLOC:
+		if args[1] < 0 {
+			return ERRBADADDRESS
+		}
+		if code := m.allocate(8 * args[1]); code != ERRNONE {
+			return code
+		}
+		*a = int64(len(m.memory))
+		m.memory = append(m.memory, make([]int64, args[1])...)
+	case FREE:
+		if *a < 0 || *a > int64(len(m.memory)) {
+			return ERRBADADDRESS
+		}
+		m.used -= 8 * (int64(len(m.memory)) - *a)
+		m.memory = m.memory[:*a]
+	case NEW:
+		if args[1] < 0 {
+			return ERRBADADDRESS
+		}
+		if code := m.allocate(8 * args[1]); code != ERRNONE {
+			return code
+		}
+		*a = HeapBase + int64(len(m.heap))
+		m.heap = append(m.heap, make([]int64, args[1])...)
+	case LOADM, STOREM:
+		words, address := m.memory, m.Registers[args[1]]
+		if address >= HeapBase {
+			words, address = m.heap, address-HeapBase
+		}
+		if address < 0 || address >= int64(len(words)) {
+			return ERRBADADDRESS
+		}
+		if op == LOADM {
+			*a = words[address]
+		} else {
+			words[address] = *a
+		}
+	case BOUNDS:
+		if *a < 0 || *a >= args[1] {
+			return ERRINDEX
+		}
+	case FADD, FSUB, FMUL, FDIV:
+		*a = FloatBits(farithmetic(op, Float(*a), Float(m.Registers[args[1]])))
+	case FNEG:
+		*a = FloatBits(-Float(*a))
+	case FJE, FJNE, FJL, FJG:
+		if fcompare(op, Float(*a), Float(m.Registers[args[1]])) {
+			next = int(m.Registers[args[2]])
+		}
+	case ITOF:
+		*a = FloatBits(float64(*a))
+	case FTOI:
+		f := math.Trunc(Float(*a))
+		// the largest float64 below 2^63 is 2^63 - 1024
+		if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return ERRFLOATRANGE
+		}
+		*a = int64(f)
+	case SQRT:
+		*a = FloatBits(math.Sqrt(Float(m.Registers[args[1]])))
+	case STR:
+		if args[0] < 0 || int64(next)+args[0] > int64(len(m.code)) {
+			return ERROUTOFBOUNDS
+		}
+		if code := m.allocate(args[0]); code != ERRNONE {
+			return code
+		}
+		m.strings = append(m.strings, string(m.code[next:next+int(args[0])]))
+		next += int(args[0])
+	case STRLEN, STRCAT, STRCMP, STRAT:
+		if code := m.strop(op, a, m.Registers[args[1]]); code != ERRNONE {
+			return code
+		}
+	case PRINT, PRINTS, READ, READLN, EOF:
+		if code := m.io(op, a); code != ERRNONE {
+			return code
+		}
+	case EXIT:
+		m.Registers[REGA] = *a
+		return ERRDONE
+	case HOST:
+		if code := m.host(a, m.Registers[args[1]], args[2]); code != ERRNONE {
+			return code
+		}
+	case STPP:
+		if len(m.stack) == 0 {
+			return ERRSTACKUNDERFLOW
+		}
+		*a, m.stack = m.stack[len(m.stack)-1], m.stack[:len(m.stack)-1]
+	}
+	m.pc = next
+	return ERRNONE
+}
+
+// arithmetic computes "a op b" for both the immediate and the
+// register form of op. Shifts use the low six bits of b, and
+// >> keeps the sign.
+func arithmetic(op Operation, a, b int64) (int64, Operation) {
+	switch op {
+	case ADD, ADDREG:
+		return a + b, ERRNONE
+	case SUB, SUBREG:
+		return a - b, ERRNONE
+	case MUL, MULREG:
+		return a * b, ERRNONE
+	case DIV, DIVREG, MOD, MODREG:
+		if b == 0 {
+			return 0, ERRDIVZERO
+		}
+		if op == DIV || op == DIVREG {
+			return a / b, ERRNONE
+		}
+		return a % b, ERRNONE
+	case AND, ANDREG:
+		return a & b, ERRNONE
+	case OR, ORREG:
+		return a | b, ERRNONE
+	case XOR, XORREG:
+		return a ^ b, ERRNONE
+	case SHL, SHLREG:
+		return a << uint(b&63), ERRNONE
+	case SHR, SHRREG:
+		return a >> uint(b&63), ERRNONE
+	}
+	return 0, ERROPCODENOTFOUND
+}
+
+// Float returns the float held in a register.
+func Float(reg int64) float64 {
+	return math.Float64frombits(uint64(reg))
+}
+
+// FloatBits returns the register value holding f.
+func FloatBits(f float64) int64 {
+	return int64(math.Float64bits(f))
+}
+
+func farithmetic(op Operation, a, b float64) float64 {
+	switch op {
+	case FADD:
+		return a + b
+	case FSUB:
+		return a - b
+	case FMUL:
+		return a * b
+	}
+	return a / b
+}
+
+// fcompare is false for every jump but FJNE if a or b is NaN.
+func fcompare(op Operation, a, b float64) bool {
+	switch op {
+	case FJE:
+		return a == b
+	case FJNE:
+		return a != b
+	case FJL:
+		return a < b
+	}
+	return a > b
+}
+
+// strop executes the string instruction "op %a b":
+//
+//	STRLEN  a = the length of string b
+//	STRCAT  a = a new string, a followed by b
+//	STRCMP  a = -1, 0 or 1 as a sorts before, with or after b
+//	STRAT   a = byte b of string a
+func (m *Machine) strop(op Operation, a *int64, b int64) Operation {
+	if op == STRLEN {
+		s, ok := m.Text(b)
+		if !ok {
+			return ERRBADSTRING
+		}
+		*a = int64(len(s))
+		return ERRNONE
+	}
+
+	s, ok := m.Text(*a)
+	if !ok {
+		return ERRBADSTRING
+	}
+	if op == STRAT {
+		if b < 0 || b >= int64(len(s)) {
+			return ERRINDEX
+		}
+		*a = int64(s[b])
+		return ERRNONE
+	}
+	t, ok := m.Text(b)
+	if !ok {
+		return ERRBADSTRING
+	}
+	if op == STRCAT {
+		if code := m.allocate(int64(len(s) + len(t))); code != ERRNONE {
+			return code
+		}
+		*a = int64(len(m.strings))
+		m.strings = append(m.strings, s+t)
+		return ERRNONE
+	}
+	*a = int64(strings.Compare(s, t))
+	return ERRNONE
+}
+
+// io executes the input and output instruction "op %a".
+func (m *Machine) io(op Operation, a *int64) Operation {
+	var err error
+	switch op {
+	case PRINT:
+		_, err = io.WriteString(m.Stdout, strconv.FormatInt(*a, 10))
+	case PRINTS:
+		s, ok := m.Text(*a)
+		if !ok {
+			return ERRBADSTRING
+		}
+		_, err = io.WriteString(m.Stdout, s)
+	case READ:
+		if m.input == nil {
+			m.input = bufio.NewReader(m.Stdin)
+		}
+		var n int64
+		// Fscan stops at the first byte that cannot be part of
+		// the integer, and leaves it to be read
+		if _, err = fmt.Fscan(m.input, &n); err == nil {
+			*a = n
+		}
+	case READLN:
+		if m.input == nil {
+			m.input = bufio.NewReader(m.Stdin)
+		}
+		line, rerr := m.input.ReadString('\n')
+		if rerr != nil && rerr != io.EOF {
+			return ERRIO
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if code := m.allocate(int64(len(line))); code != ERRNONE {
+			return code
+		}
+		*a = int64(len(m.strings))
+		m.strings = append(m.strings, line)
+	case EOF:
+		if m.input == nil {
+			m.input = bufio.NewReader(m.Stdin)
+		}
+		*a = 0
+		if _, err = m.input.Peek(1); err == io.EOF {
+			*a, err = 1, nil
+		}
+	}
+	if err != nil {
+		return ERRIO
+	}
+	return ERRNONE
+}
+
+// host calls the host function named by the string with handle
+// name with the top n words of the stack.
+func (m *Machine) host(a *int64, name int64, n int64) Operation {
+	s, ok := m.Text(name)
+	if !ok {
+		return ERRBADSTRING
+	}
+	h, ok := m.hosts[s]
+	if !ok {
+		m.HostError = fmt.Errorf("host function %q is not registered", s)
+		return ERRHOST
+	}
+	if n != int64(h.arity) {
+		return ERRARITY
+	}
+	if n > int64(len(m.stack)) {
+		return ERRSTACKUNDERFLOW
+	}
+	args := append([]int64(nil), m.stack[len(m.stack)-int(n):]...)
+	m.stack = m.stack[:len(m.stack)-int(n)]
+	result, err := h.fn(args)
+	if err != nil {
+		m.HostError = err
+		return ERRHOST
+	}
+	*a = result
+	return ERRNONE
+}
+
+// Text returns the string with the given handle.
+func (m *Machine) Text(handle int64) (string, bool) {
+	if handle < 0 || handle >= int64(len(m.strings)) {
+		return "", false
+	}
+	return m.strings[handle], true
+}
+
+func compare(op Operation, a, b int64) bool {
+	switch op {
+	case JE:
+		return a == b
+	case JNE:
+		return a != b
+	case JL:
+		return a < b
+	}
+	return a > b
+}
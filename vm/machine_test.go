@@ -0,0 +1,237 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+// loop is "label top; jump top;" by hand: B holds the address of
+// the JE, which jumps to itself.
+var loop = []byte{
+	SET.Byte(), byte(REGB), 3,
+	JE.Byte(), byte(REGA), byte(REGA), byte(REGB),
+}
+
+func TestLoopStopsAtInstructionLimit(t *testing.T) {
+	for _, limit := range []int64{1, 2, 1000, 1001} {
+		m := NewMachine(loop)
+		m.MaxInstructions = limit
+		if code := m.Run(); code != ERRINSTRUCTIONS {
+			t.Fatalf("limit %d: got code %d, want %d", limit, code, ERRINSTRUCTIONS)
+		}
+		if m.Instructions != limit {
+			t.Errorf("limit %d: executed %d instructions", limit, m.Instructions)
+		}
+		if m.pc != 3 {
+			t.Errorf("limit %d: stopped at %d, want 3", limit, m.pc)
+		}
+	}
+}
+
+func TestStackLimit(t *testing.T) {
+	// STPS 1 and jump back to it
+	code := []byte{
+		SET.Byte(), byte(REGB), 3,
+		STPS.Byte(), 1,
+		JE.Byte(), byte(REGA), byte(REGA), byte(REGB),
+	}
+	m := NewMachine(code)
+	m.MaxStack = 64
+	if code := m.Run(); code != ERRSTACKLIMIT {
+		t.Fatalf("got code %d, want %d", code, ERRSTACKLIMIT)
+	}
+	if len(m.stack) != m.MaxStack+1 {
+		t.Errorf("stopped with %d words on the stack", len(m.stack))
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	// ALLOC 16 words and jump back to it
+	code := []byte{
+		SET.Byte(), byte(REGB), 3,
+		ALLOC.Byte(), byte(REGC), 0, 0, 0, 16,
+		JE.Byte(), byte(REGA), byte(REGA), byte(REGB),
+	}
+	m := NewMachine(code)
+	m.MaxMemory = 1000
+	if code := m.Run(); code != ERRMEMORYLIMIT {
+		t.Fatalf("got code %d, want %d", code, ERRMEMORYLIMIT)
+	}
+	if m.used > m.MaxMemory || len(m.memory) != 7*16 {
+		t.Errorf("stopped with %d bytes used, %d words of memory", m.used, len(m.memory))
+	}
+}
+
+func TestOutOfBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		code []byte
+	}{
+		{"no END", []byte{SET.Byte(), byte(REGA), 5}},
+		{"truncated register", []byte{SET.Byte()}},
+		{"truncated immediate", []byte{SET.Byte(), byte(REGA)}},
+		{"truncated long", []byte{DATA.Byte(), 0, 0}},
+		{"truncated string", []byte{STR.Byte(), 0, 0, 0, 9, 'a'}},
+	}
+	for _, test := range tests {
+		m := NewMachine(test.code)
+		code := m.Run()
+		if code != ERROUTOFBOUNDS {
+			t.Errorf("%s: got code %d, want %d", test.name, code, ERROUTOFBOUNDS)
+		}
+		if m.Trap(code) == nil {
+			t.Errorf("%s: no trap", test.name)
+		}
+	}
+}
+
+// hostCall calls the host function "add" with n arguments, 2 and
+// then 3 and so on, and ends with its result in A.
+func hostCall(n int) []byte {
+	code := []byte{STR.Byte(), 0, 0, 0, 3, 'a', 'd', 'd'}
+	for i := 0; i < n; i++ {
+		code = append(code, STPS.Byte(), byte(2+i))
+	}
+	return append(code,
+		SET.Byte(), byte(REGB), 0,
+		HOST.Byte(), byte(REGA), byte(REGB), byte(n),
+		END.Byte(),
+	)
+}
+
+func TestHost(t *testing.T) {
+	failed := errors.New("failed")
+	add := func(args []int64) (int64, error) {
+		if args[0] < 0 {
+			return 0, failed
+		}
+		return args[0] + 10*args[1], nil
+	}
+
+	m := NewMachine(hostCall(2))
+	m.RegisterHost("add", 2, add)
+	if err := m.Trap(m.Run()); err != nil {
+		t.Fatal(err)
+	}
+	if m.Registers[REGA] != 32 || len(m.stack) != 0 {
+		t.Errorf("add(2, 3) gives %d and leaves %d words on the stack", m.Registers[REGA], len(m.stack))
+	}
+
+	tests := []struct {
+		name  string
+		code  []byte
+		arity int
+		fn    HostFunc
+		trap  Operation
+		err   error
+	}{
+		{"arity", hostCall(1), 2, add, ERRARITY, nil},
+		{"error", hostCall(2), 2, func([]int64) (int64, error) { return 0, failed }, ERRHOST, failed},
+		{"unregistered", hostCall(2), 0, nil, ERRHOST, nil},
+	}
+	for _, test := range tests {
+		m := NewMachine(test.code)
+		if test.fn != nil {
+			m.RegisterHost("add", test.arity, test.fn)
+		}
+		err := m.Trap(m.Run())
+		var trap *Trap
+		if !errors.As(err, &trap) || trap.Code != test.trap {
+			t.Errorf("%s: got %v, want trap %d", test.name, err, test.trap)
+			continue
+		}
+		if test.err != nil && (!errors.Is(err, test.err) || trap.Err != test.err) {
+			t.Errorf("%s: %v does not wrap %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	tests := []struct {
+		op   Operation
+		a, b int64
+		want int64
+	}{
+		{MODREG, 17, 5, 2},
+		{MODREG, -17, 5, -2},
+		{ANDREG, 12, 10, 8},
+		{ORREG, 12, 10, 14},
+		{XORREG, 12, 10, 6},
+		{SHLREG, 3, 4, 48},
+		{SHRREG, 48, 4, 3},
+		{SHRREG, -16, 2, -4},
+		{SHLREG, 1, 64, 1}, // shifts count modulo 64
+		{SHLREG, 1, 63, -1 << 63},
+	}
+	for _, test := range tests {
+		m := NewMachine([]byte{test.op.Byte(), byte(REGA), byte(REGB), END.Byte()})
+		m.Registers[REGA], m.Registers[REGB] = test.a, test.b
+		if err := m.Trap(m.Run()); err != nil {
+			t.Errorf("%d %d %d: %v", test.op, test.a, test.b, err)
+			continue
+		}
+		if got := m.Registers[REGA]; got != test.want {
+			t.Errorf("%d %d %d gives %d, want %d", test.op, test.a, test.b, got, test.want)
+		}
+	}
+
+	immediates := []struct {
+		code []byte
+		want int64
+	}{
+		{[]byte{SET.Byte(), byte(REGA), 6, AND.Byte(), byte(REGA), 3}, 2},
+		{[]byte{SET.Byte(), byte(REGA), 6, OR.Byte(), byte(REGA), 3}, 7},
+		{[]byte{SET.Byte(), byte(REGA), 6, XOR.Byte(), byte(REGA), 3}, 5},
+		{[]byte{SET.Byte(), byte(REGA), 6, SHL.Byte(), byte(REGA), 3}, 48},
+		{[]byte{SET.Byte(), byte(REGA), 6, SHR.Byte(), byte(REGA), 1}, 3},
+		{[]byte{SET.Byte(), byte(REGA), 7, MOD.Byte(), byte(REGA), 4}, 3},
+		{[]byte{SET.Byte(), byte(REGA), 6, NOT.Byte(), byte(REGA)}, -7},
+	}
+	for _, test := range immediates {
+		m := NewMachine(append(test.code, END.Byte()))
+		if err := m.Trap(m.Run()); err != nil {
+			t.Errorf("%v: %v", test.code, err)
+		} else if got := m.Registers[REGA]; got != test.want {
+			t.Errorf("%v gives %d, want %d", test.code, got, test.want)
+		}
+	}
+
+	for _, op := range []Operation{MOD, MODREG} {
+		m := NewMachine([]byte{op.Byte(), byte(REGA), 0, END.Byte()})
+		if code := m.Run(); code != ERRDIVZERO {
+			t.Errorf("%d by 0 gives code %d, want %d", op, code, ERRDIVZERO)
+		}
+	}
+}
+
+func TestRelativeJumps(t *testing.T) {
+	tests := []struct {
+		op   Operation
+		a, b int64
+		jump bool
+	}{
+		{RELJE, 1, 1, true},
+		{RELJE, 1, 2, false},
+		{RELJNE, 1, 2, true},
+		{RELJL, 1, 2, true},
+		{RELJL, 2, 1, false},
+		{RELJG, 2, 1, true},
+	}
+	for _, test := range tests {
+		// the jump skips the SET of A to 9
+		code := []byte{
+			test.op.Byte(), byte(REGB), byte(REGC), byte(REGD),
+			SET.Byte(), byte(REGA), 9,
+			END.Byte(),
+		}
+		m := NewMachine(code)
+		m.Registers[REGB], m.Registers[REGC], m.Registers[REGD] = test.a, test.b, 3
+		if err := m.Trap(m.Run()); err != nil {
+			t.Errorf("%d %d %d: %v", test.op, test.a, test.b, err)
+			continue
+		}
+		if jumped := m.Registers[REGA] != 9; jumped != test.jump {
+			t.Errorf("%d %d %d: jumped is %v", test.op, test.a, test.b, jumped)
+		}
+	}
+}
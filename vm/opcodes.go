@@ -35,6 +35,24 @@ const (
 	ERRDONE           Operation = 1
 	ERROPCODENOTFOUND Operation = 2
 	ERRBREAK          Operation = 3
+	ERRDIVZERO        Operation = 4
+	ERRSTACKUNDERFLOW Operation = 5
+	ERRBADREGISTER    Operation = 6
+	ERRBADADDRESS     Operation = 7
+	ERRINDEX          Operation = 8
+	ERRBADSTRING      Operation = 9
+	ERRFLOATRANGE     Operation = 10
+	ERRARITY          Operation = 11
+	ERRIO             Operation = 12
+	ERRHOST           Operation = 13
+	ERRINSTRUCTIONS   Operation = 14
+	ERRCANCELED       Operation = 15
+	ERRDEADLINE       Operation = 16
+	ERRSTACKLIMIT     Operation = 17
+	ERRMEMORYLIMIT    Operation = 18
+	ERROUTOFBOUNDS    Operation = 19
+	// BREAK_OUTOFBOUNDS is kept for the programs that compare
+	// with it; the machine reports ERROUTOFBOUNDS instead.
 	BREAK_OUTOFBOUNDS Operation = 1
 	END               Operation = 1
 	SET               Operation = 2
@@ -62,6 +80,53 @@ const (
 	STPR              Operation = 24
 	SQRT              Operation = 25
 	NEG               Operation = 26
+	MODREG            Operation = 27
+	AND               Operation = 28
+	ANDREG            Operation = 29
+	OR                Operation = 30
+	ORREG             Operation = 31
+	XOR               Operation = 32
+	XORREG            Operation = 33
+	SHL               Operation = 34
+	SHLREG            Operation = 35
+	SHR               Operation = 36
+	SHRREG            Operation = 37
+	NOT               Operation = 38
+	DATA              Operation = 39
+	LOAD              Operation = 40
+	STORE             Operation = 41
+	ALLOC             Operation = 42
+	FREE              Operation = 43
+	LOADM             Operation = 44
+	STOREM            Operation = 45
+	BOUNDS            Operation = 46
+	STR               Operation = 47
+	STRLEN            Operation = 48
+	STRCAT            Operation = 49
+	STRCMP            Operation = 50
+	STRAT             Operation = 51
+	SETF              Operation = 52
+	FADD              Operation = 53
+	FSUB              Operation = 54
+	FMUL              Operation = 55
+	FDIV              Operation = 56
+	FNEG              Operation = 57
+	FJE               Operation = 58
+	FJNE              Operation = 59
+	FJL               Operation = 60
+	FJG               Operation = 61
+	ITOF              Operation = 62
+	FTOI              Operation = 63
+	JR                Operation = 64
+	ENTRY             Operation = 65
+	NEW               Operation = 66
+	PRINT             Operation = 67
+	PRINTS            Operation = 68
+	READ              Operation = 69
+	READLN            Operation = 70
+	EXIT              Operation = 71
+	HOST              Operation = 72
+	EOF               Operation = 73
 	SETL              Operation = 253
 	NONE              Operation = 254
 	BREAK             Operation = 255
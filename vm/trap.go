@@ -0,0 +1,85 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+)
+
+// Trap is the error of a program that stopped at PC because of
+// Code instead of ending.
+type Trap struct {
+	Code Operation
+	PC   int
+	Err  error // what made the host function or the context fail
+}
+
+var trapMessages = map[Operation]string{
+	ERROPCODENOTFOUND: "unknown instruction",
+	ERRBREAK:          "breakpoint",
+	ERRDIVZERO:        "division by zero",
+	ERRSTACKUNDERFLOW: "stack underflow",
+	ERRBADREGISTER:    "bad register",
+	ERRBADADDRESS:     "bad address",
+	ERRINDEX:          "index out of range",
+	ERRBADSTRING:      "bad string handle",
+	ERRFLOATRANGE:     "float out of the range of integers",
+	ERRARITY:          "wrong number of arguments",
+	ERRIO:             "input or output failed",
+	ERRHOST:           "host function failed",
+	ERRINSTRUCTIONS:   "instruction limit reached",
+	ERRCANCELED:       "canceled",
+	ERRDEADLINE:       "deadline exceeded",
+	ERRSTACKLIMIT:     "stack limit reached",
+	ERRMEMORYLIMIT:    "memory limit reached",
+	ERROUTOFBOUNDS:    "out of the code",
+}
+
+// The traps of the limits of a machine, to tell them apart with
+// errors.Is.
+var (
+	ErrInstructionLimit = &Trap{Code: ERRINSTRUCTIONS}
+	ErrStackLimit       = &Trap{Code: ERRSTACKLIMIT}
+	ErrMemoryLimit      = &Trap{Code: ERRMEMORYLIMIT}
+	ErrCanceled         = &Trap{Code: ERRCANCELED}
+	ErrDeadline         = &Trap{Code: ERRDEADLINE}
+)
+
+func (t *Trap) Error() string {
+	message, ok := trapMessages[t.Code]
+	if !ok {
+		message = fmt.Sprintf("trap %d", t.Code)
+	}
+	if t.Err != nil && t.Code == ERRHOST {
+		return fmt.Sprintf("%s at %d: %s", message, t.PC, t.Err)
+	}
+	return fmt.Sprintf("%s at %d", message, t.PC)
+}
+
+func (t *Trap) Unwrap() error {
+	return t.Err
+}
+
+// Is reports whether target is a trap with the same code, wherever
+// it happened.
+func (t *Trap) Is(target error) bool {
+	other, ok := target.(*Trap)
+	return ok && other.Code == t.Code
+}
+
+// Trap returns the error for code, which Run returned, or nil if
+// the program ended.
+func (m *Machine) Trap(code Operation) error {
+	if code == ERRNONE || code == ERRDONE {
+		return nil
+	}
+	t := &Trap{Code: code, PC: m.pc}
+	switch code {
+	case ERRHOST:
+		t.Err = m.HostError
+	case ERRCANCELED:
+		t.Err = context.Canceled
+	case ERRDEADLINE:
+		t.Err = context.DeadlineExceeded
+	}
+	return t
+}